@@ -0,0 +1,69 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/qubic/computors-consumer/elastic"
+)
+
+type cacheEntry struct {
+	list    *elastic.ComputorsList
+	expires time.Time
+}
+
+// cache keeps found lists for a limited time. A list, that is effective in a tick, might change, if a newer list is
+// indexed, so the entries expire.
+type cache struct {
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+}
+
+func newCache(size int, ttl time.Duration) *cache {
+	return &cache{
+		entries: make(map[string]cacheEntry, size),
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+	}
+}
+
+func (c *cache) get(key string) (*elastic.ComputorsList, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.list, true
+}
+
+func (c *cache) put(key string, list *elastic.ComputorsList) {
+	if c.size <= 0 {
+		return // disabled
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now()
+	if len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= c.size {
+		for k := range c.entries { // evict any entry
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = cacheEntry{list: list, expires: now.Add(c.ttl)}
+}
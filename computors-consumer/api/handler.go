@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/qubic/computors-consumer/elastic"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
+)
+
+type ComputorsListFinder interface {
+	FindComputorsListForTick(ctx context.Context, tick uint32) (*elastic.ComputorsList, error)
+	FindLatestComputorsListWithIdentitiesForEpoch(ctx context.Context, epoch uint32) (*elastic.ComputorsList, error)
+}
+
+type Handler struct {
+	finder ComputorsListFinder
+	cache  *cache
+}
+
+type ComputorsListResponse struct {
+	Epoch      uint32   `json:"epoch"`
+	TickNumber uint32   `json:"tickNumber"` // tick, from which the list is effective
+	Identities []string `json:"identities"`
+	Signature  string   `json:"signature"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler creates the handler. Found lists are cached for the ttl. A cache size of zero disables the cache.
+func NewHandler(finder ComputorsListFinder, cacheSize int, cacheTtl time.Duration) *Handler {
+	return &Handler{
+		finder: finder,
+		cache:  newCache(cacheSize, cacheTtl),
+	}
+}
+
+// GetComputorsListForTick returns the list, that is effective in the tick.
+func (h *Handler) GetComputorsListForTick(w http.ResponseWriter, r *http.Request) {
+	tick, err := strconv.ParseUint(r.PathValue("tick"), 10, 32)
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, ErrorResponse{Error: "invalid tick"})
+		return
+	}
+	h.find(w, r.Context(), fmt.Sprintf("tick:%d", tick), func(ctx context.Context) (*elastic.ComputorsList, error) {
+		return h.finder.FindComputorsListForTick(ctx, uint32(tick))
+	})
+}
+
+// GetComputorsListForEpoch returns the latest list of the epoch.
+func (h *Handler) GetComputorsListForEpoch(w http.ResponseWriter, r *http.Request) {
+	epoch, err := strconv.ParseUint(r.PathValue("epoch"), 10, 32)
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, ErrorResponse{Error: "invalid epoch"})
+		return
+	}
+	h.find(w, r.Context(), fmt.Sprintf("epoch:%d", epoch), func(ctx context.Context) (*elastic.ComputorsList, error) {
+		return h.finder.FindLatestComputorsListWithIdentitiesForEpoch(ctx, uint32(epoch))
+	})
+}
+
+func (h *Handler) find(w http.ResponseWriter, ctx context.Context, key string, find func(ctx context.Context) (*elastic.ComputorsList, error)) {
+	list, ok := h.cache.get(key)
+	if !ok {
+		var err error
+		list, err = find(ctx)
+		if err != nil {
+			zap.S().Errorw("Finding computors list failed.", "key", key, logging.Error, err)
+			writeJson(w, http.StatusInternalServerError, ErrorResponse{Error: "finding computors list failed"})
+			return
+		}
+		if list == nil {
+			writeJson(w, http.StatusNotFound, ErrorResponse{Error: "computors list not found"})
+			return
+		}
+		h.cache.put(key, list)
+	}
+	writeJson(w, http.StatusOK, ComputorsListResponse{
+		Epoch:      list.Epoch,
+		TickNumber: list.TickNumber,
+		Identities: list.Identities,
+		Signature:  list.Signature,
+	})
+}
+
+func writeJson(w http.ResponseWriter, status int, response any) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		zap.S().Errorw("Encoding response failed.", logging.Error, err)
+	}
+}
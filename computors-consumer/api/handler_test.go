@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/qubic/computors-consumer/elastic"
+	"github.com/qubic/sink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elasticStandIn answers searches for the list effective in tick 1200 and the latest list of epoch 100. The known
+// ticks are 1000 to 1999 (epoch 100) and 2000 to 2100 (epoch 101, provisional). It identifies as OpenSearch, too.
+func elasticStandIn(t *testing.T, searches *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := strings.ReplaceAll(string(body), " ", "")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version":{"distribution":"opensearch","number":"2.11.0"}}`))
+			return
+		}
+		if r.URL.Path == "/qubic-tick-intervals-alias/_search" {
+			assert.Contains(t, query, `"_source":["epoch"]`)
+			switch {
+			case strings.Contains(query, `{"range":{"from":{"lte":1200}}},{"range":{"to":{"gte":1200}}}`):
+				_, _ = w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_source":{"epoch":100}}]}}`))
+			case strings.Contains(query, `{"range":{"from":{"lte":2050}}},{"range":{"to":{"gte":2050}}}`):
+				_, _ = w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_source":{"epoch":101}}]}}`))
+			default:
+				_, _ = w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`))
+			}
+			return
+		}
+
+		assert.Equal(t, "/qubic-computors-alias/_search", r.URL.Path)
+		assert.Contains(t, query, `"_source":["epoch","tickNumber","identities","signature"]`)
+		*searches++
+		switch {
+		case strings.Contains(query, `{"term":{"epoch":100}},{"range":{"tickNumber":{"lte":1200}}}`) && strings.Contains(query, `"order":"desc"`):
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_source":{"epoch":100,"tickNumber":1000,"identities":["A","B"],"signature":"sig-1"}}]}}`))
+		case strings.Contains(query, `"term":{"epoch":100}`) && strings.Contains(query, `"order":"desc"`):
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_source":{"epoch":100,"tickNumber":1500,"identities":["A","C"],"signature":"sig-2"}}]}}`))
+		case strings.Contains(query, `"term":{"epoch":666}`):
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"test error"}`))
+		default:
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestHandler(t *testing.T, searches *int, cacheSize int) http.Handler {
+	return newTestHandlerWithSink(t, searches, cacheSize, sink.Elasticsearch)
+}
+
+func newTestHandlerWithSink(t *testing.T, searches *int, cacheSize int, sinkName string) http.Handler {
+	server := elasticStandIn(t, searches)
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}, MaxRetries: 0})
+	require.NoError(t, err)
+	sinkClient, err := sink.NewClient(context.Background(), esClient, sink.HttpBulkConfig{Sink: sinkName, Addresses: []string{server.URL}})
+	require.NoError(t, err)
+	handler := NewHandler(elastic.NewClient(sinkClient, "qubic-computors-alias", "qubic-computor-seats-alias", "qubic-tick-intervals-alias"), cacheSize, time.Minute)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/computors/ticks/{tick}", handler.GetComputorsListForTick)
+	mux.HandleFunc("GET /v1/computors/epochs/{epoch}", handler.GetComputorsListForEpoch)
+	return mux
+}
+
+func get(t *testing.T, handler http.Handler, path string) (int, string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestHandler_GetComputorsListForTick(t *testing.T) {
+	var searches int
+	handler := newTestHandler(t, &searches, 10)
+
+	code, body := get(t, handler, "/v1/computors/ticks/1200")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"epoch":100,"tickNumber":1000,"identities":["A","B"],"signature":"sig-1"}`, body)
+
+	code, body = get(t, handler, "/v1/computors/ticks/1200")
+	assert.Equal(t, http.StatusOK, code)
+	var response ComputorsListResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+	assert.Equal(t, "sig-1", response.Signature)
+	assert.Equal(t, 1, searches) // cached
+
+	code, _ = get(t, handler, "/v1/computors/ticks/2050")
+	assert.Equal(t, http.StatusNotFound, code, "no list of epoch 101")
+	code, _ = get(t, handler, "/v1/computors/ticks/2050")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, 3, searches) // not found is not cached
+
+	code, _ = get(t, handler, "/v1/computors/ticks/999")
+	assert.Equal(t, http.StatusNotFound, code, "before the known ticks")
+	code, _ = get(t, handler, "/v1/computors/ticks/2101")
+	assert.Equal(t, http.StatusNotFound, code, "after the known ticks")
+	assert.Equal(t, 3, searches, "the lists are not searched for unknown ticks")
+
+	code, _ = get(t, handler, "/v1/computors/ticks/foo")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 3, searches)
+}
+
+func TestHandler_GetComputorsListForEpoch(t *testing.T) {
+	var searches int
+	handler := newTestHandler(t, &searches, 0)
+
+	code, body := get(t, handler, "/v1/computors/epochs/100")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"epoch":100,"tickNumber":1500,"identities":["A","C"],"signature":"sig-2"}`, body)
+	_, _ = get(t, handler, "/v1/computors/epochs/100")
+	assert.Equal(t, 2, searches) // cache disabled
+
+	code, _ = get(t, handler, "/v1/computors/epochs/101")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, body = get(t, handler, "/v1/computors/epochs/666")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.JSONEq(t, `{"error":"finding computors list failed"}`, body)
+
+	code, _ = get(t, handler, "/v1/computors/epochs/4294967296")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestHandler_OpenSearchSink(t *testing.T) {
+	var searches int
+	handler := newTestHandlerWithSink(t, &searches, 0, sink.OpenSearch)
+
+	code, body := get(t, handler, "/v1/computors/ticks/1200")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"epoch":100,"tickNumber":1000,"identities":["A","B"],"signature":"sig-1"}`, body)
+
+	code, body = get(t, handler, "/v1/computors/epochs/100")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"epoch":100,"tickNumber":1500,"identities":["A","C"],"signature":"sig-2"}`, body)
+	assert.Equal(t, 2, searches)
+}
+
+func TestCache_Expiry(t *testing.T) {
+	now := time.Now()
+	c := newCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.put("a", &elastic.ComputorsList{Epoch: 1})
+	list, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), list.Epoch)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.get("a")
+	assert.False(t, ok)
+
+	c.put("a", &elastic.ComputorsList{Epoch: 1})
+	c.put("b", &elastic.ComputorsList{Epoch: 2})
+	c.put("c", &elastic.ComputorsList{Epoch: 3})
+	assert.Len(t, c.entries, 2)
+	_, ok = c.get("c")
+	assert.True(t, ok)
+}
@@ -7,13 +7,14 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/qubic/computors-consumer/domain"
 	"github.com/qubic/computors-consumer/elastic"
 	"github.com/qubic/computors-consumer/metrics"
 	"github.com/qubic/go-qubic/common"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type KafkaClient interface {
@@ -24,7 +25,9 @@ type KafkaClient interface {
 
 type ElasticClient interface {
 	BulkIndex(ctx context.Context, data []*elastic.EsDocument) error
+	BulkIndexSeats(ctx context.Context, data []*elastic.EsDocument) error
 	FindLatestComputorsListForEpoch(ctx context.Context, epoch uint32) (*elastic.ComputorsList, error)
+	FindComputorsListsForEpoch(ctx context.Context, epoch uint32) ([]*elastic.ComputorsList, error)
 }
 
 type EpochProcessor struct {
@@ -47,16 +50,32 @@ func (p *EpochProcessor) Consume() error {
 	for range ticker {
 		count, err := p.consumeBatch(context.Background())
 		if err != nil {
-			log.Printf("Error consuming batch: %v", err)
 			return fmt.Errorf("consuming batch: %w", err)
 		} else {
 			p.metrics.IncProcessedMessages(count)
-			log.Printf("Consumed [%d] message(s).", count)
+			zap.S().Infow("Consumed messages.", "count", count)
 		}
 	}
 	return nil
 }
 
+// Replay consumes until done returns true. Returns the number of consumed computor lists.
+func (p *EpochProcessor) Replay(ctx context.Context, done func() bool) (int, error) {
+	total := 0
+	for !done() {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		count, err := p.consumeBatch(ctx)
+		if err != nil {
+			return total, fmt.Errorf("consuming batch: %w", err)
+		}
+		p.metrics.IncProcessedMessages(count)
+		total += count
+	}
+	return total, nil
+}
+
 func (p *EpochProcessor) consumeBatch(ctx context.Context) (int, error) {
 	defer p.kafkaClient.AllowRebalance() // because of kgo.BlockRebalanceOnPoll()
 	messages, err := p.kafkaClient.PollMessages(ctx)
@@ -64,7 +83,6 @@ func (p *EpochProcessor) consumeBatch(ctx context.Context) (int, error) {
 		return -1, fmt.Errorf("polling kafka messages: %w", err)
 	}
 
-	// duplicate check only works if there is enough time between bulk insert and querying
 	filteredMessages, err := p.filterDuplicateComputorLists(ctx, messages)
 	if err != nil {
 		return -1, fmt.Errorf("filter duplicate computor lists: %w", err)
@@ -76,7 +94,13 @@ func (p *EpochProcessor) consumeBatch(ctx context.Context) (int, error) {
 			return -1, fmt.Errorf("sending epoch computor batch to elastic: %w", err)
 		}
 	} else {
-		log.Printf("No messages to send to elastic.")
+		zap.S().Info("No messages to send to elastic.")
+	}
+
+	// use all messages, so that seats are repaired on replay
+	err = p.indexSeats(ctx, messages)
+	if err != nil {
+		return -1, fmt.Errorf("indexing computor seats: %w", err)
 	}
 
 	err = p.kafkaClient.Commit(ctx) // because of kgo.DisableAutoCommit()
@@ -86,20 +110,30 @@ func (p *EpochProcessor) consumeBatch(ctx context.Context) (int, error) {
 	return len(messages), nil
 }
 
+// filterDuplicateComputorLists removes lists, that are equal to the latest list of the epoch. The latest list is the
+// previous list of the epoch in the batch or, for the first list of an epoch in the batch, the latest stored list.
+// Stored lists are searchable after bulk insert (see elastic client).
 func (p *EpochProcessor) filterDuplicateComputorLists(ctx context.Context, messages []*domain.EpochComputors) ([]*domain.EpochComputors, error) {
 	filteredList := make([]*domain.EpochComputors, 0, len(messages))
+	latestSignatures := make(map[uint32]string) // per epoch
 	for _, computorsList := range messages {
-		latestList, latestErr := p.elasticClient.FindLatestComputorsListForEpoch(ctx, computorsList.Epoch)
-		if latestErr != nil {
-			return nil, fmt.Errorf("checking latest computors list for epoch %d: %w", computorsList.Epoch, latestErr)
+		latestSignature, ok := latestSignatures[computorsList.Epoch]
+		if !ok {
+			latestList, latestErr := p.elasticClient.FindLatestComputorsListForEpoch(ctx, computorsList.Epoch)
+			if latestErr != nil {
+				return nil, fmt.Errorf("checking latest computors list for epoch %d: %w", computorsList.Epoch, latestErr)
+			}
+			if latestList != nil {
+				latestSignature = latestList.Signature
+			}
 		}
-		if latestList == nil || latestList.Signature != computorsList.Signature {
-			log.Printf("Ingest computors list for epoch [%d] and tick [%d].",
-				computorsList.Epoch, computorsList.TickNumber)
+		if latestSignature != computorsList.Signature {
+			zap.S().Infow("Ingest computors list.", logging.Epoch, computorsList.Epoch, logging.Tick, computorsList.TickNumber)
 			filteredList = append(filteredList, computorsList)
+			latestSignatures[computorsList.Epoch] = computorsList.Signature
 		} else {
-			log.Printf("Ignore duplicate computors list. Epoch [%d], tick [%d], signature [%s].",
-				computorsList.Epoch, computorsList.TickNumber, computorsList.Signature)
+			zap.S().Infow("Ignore duplicate computors list.", logging.Epoch, computorsList.Epoch, logging.Tick, computorsList.TickNumber,
+				"signature", computorsList.Signature)
 		}
 	}
 	return filteredList, nil
@@ -143,22 +177,14 @@ func convertToDocument(computors *domain.EpochComputors) (*elastic.EsDocument, e
 	return document, err
 }
 
+// calculateUniqueId creates the id from epoch and signature. The signature identifies the list within the epoch, so
+// that the same list results in the same document, even if it is published again with another tick number.
 func calculateUniqueId(event *domain.EpochComputors) (string, error) {
 	var buff bytes.Buffer
 	err := binary.Write(&buff, binary.LittleEndian, event.Epoch)
 	if err != nil {
 		return "", fmt.Errorf("writing epoch to buffer: %w", err)
 	}
-	err = binary.Write(&buff, binary.LittleEndian, event.TickNumber)
-	if err != nil {
-		return "", fmt.Errorf("writing tick to buffer: %w", err)
-	}
-	for _, identity := range event.Identities {
-		_, err = buff.Write([]byte(identity))
-		if err != nil {
-			return "", fmt.Errorf("writing identity [%s] to buffer: %w", identity, err)
-		}
-	}
 
 	_, err = buff.Write([]byte(event.Signature))
 	if err != nil {
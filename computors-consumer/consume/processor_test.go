@@ -36,13 +36,19 @@ func (f *FakeKafkaClient) AllowRebalance() {
 }
 
 type FakeElasticClient struct {
-	lastDocuments  []*elastic.EsDocument
-	duplicate      *elastic.ComputorsList
-	err            error
-	bulkIndexCount int
+	lastDocuments     []*elastic.EsDocument
+	lastSeatDocuments []*elastic.EsDocument
+	duplicate         *elastic.ComputorsList
+	duplicateEpoch    uint32                              // optional. epoch of the duplicate.
+	storedLists       map[uint32][]*elastic.ComputorsList // optional
+	err               error
+	bulkIndexCount    int
 }
 
-func (f *FakeElasticClient) FindLatestComputorsListForEpoch(context.Context, uint32) (*elastic.ComputorsList, error) {
+func (f *FakeElasticClient) FindLatestComputorsListForEpoch(_ context.Context, epoch uint32) (*elastic.ComputorsList, error) {
+	if f.duplicateEpoch > 0 && f.duplicateEpoch != epoch {
+		return nil, f.err
+	}
 	return f.duplicate, f.err
 }
 
@@ -52,6 +58,15 @@ func (f *FakeElasticClient) BulkIndex(_ context.Context, documents []*elastic.Es
 	return f.err
 }
 
+func (f *FakeElasticClient) FindComputorsListsForEpoch(_ context.Context, epoch uint32) ([]*elastic.ComputorsList, error) {
+	return f.storedLists[epoch], f.err
+}
+
+func (f *FakeElasticClient) BulkIndexSeats(_ context.Context, documents []*elastic.EsDocument) error {
+	f.lastSeatDocuments = documents
+	return f.err
+}
+
 func TestProcessor_ConsumeBatch(t *testing.T) {
 	computorsList := []*domain.EpochComputors{
 		{Epoch: 1, TickNumber: 100, Identities: []string{"A", "B", "C"}, Signature: "signature-1"},
@@ -83,6 +98,27 @@ func TestProcessor_ConsumeBatch(t *testing.T) {
 
 }
 
+func TestProcessor_Replay(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		computorsList: []*domain.EpochComputors{
+			{Epoch: 1, TickNumber: 100, Identities: []string{"A", "B", "C"}, Signature: "signature-1"},
+		},
+	}
+	elasticClient := &FakeElasticClient{}
+	processor := NewEpochProcessor(kafkaClient, elasticClient, m)
+
+	count, err := processor.Replay(context.Background(), func() bool { return kafkaClient.commitCount == 2 })
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, elasticClient.bulkIndexCount)
+	assert.Len(t, elasticClient.lastSeatDocuments, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = processor.Replay(ctx, func() bool { return false })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestProcessor_ConsumeBatch_GivenDuplicate_IgnoreDuplicate(t *testing.T) {
 	computorsList := []*domain.EpochComputors{
 		{Epoch: 1, TickNumber: 100, Identities: []string{"A", "B", "C"}, Signature: "signature-1"},
@@ -112,6 +148,54 @@ func TestProcessor_ConsumeBatch_GivenDuplicate_IgnoreDuplicate(t *testing.T) {
 	assert.Equal(t, doc1, elasticClient.lastDocuments[0])
 }
 
+func TestProcessor_ConsumeBatch_GivenMultipleListsPerEpoch_IgnoreDuplicatesInBatch(t *testing.T) {
+	computorsList := []*domain.EpochComputors{
+		{Epoch: 1, TickNumber: 100, Identities: []string{"A", "B", "C"}, Signature: "signature-1"}, // stored
+		{Epoch: 1, TickNumber: 100, Identities: []string{"A", "B", "C"}, Signature: "signature-1"},
+		{Epoch: 1, TickNumber: 150, Identities: []string{"A", "B", "D"}, Signature: "signature-2"},
+		{Epoch: 1, TickNumber: 150, Identities: []string{"A", "B", "D"}, Signature: "signature-2"}, // duplicate in batch
+		{Epoch: 1, TickNumber: 180, Identities: []string{"A", "E", "D"}, Signature: "signature-3"},
+		{Epoch: 2, TickNumber: 200, Identities: []string{"A", "E", "D"}, Signature: "signature-4"},
+		{Epoch: 2, TickNumber: 200, Identities: []string{"A", "E", "D"}, Signature: "signature-4"}, // duplicate in batch
+	}
+
+	kafkaClient := &FakeKafkaClient{
+		computorsList: computorsList,
+	}
+	elasticClient := &FakeElasticClient{
+		duplicate:      &elastic.ComputorsList{Epoch: 1, TickNumber: 100, Signature: "signature-1"},
+		duplicateEpoch: 1,
+	}
+	processor := NewEpochProcessor(kafkaClient, elasticClient, m)
+
+	count, err := processor.consumeBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 7, count)
+	require.Equal(t, 1, elasticClient.bulkIndexCount)
+	require.Len(t, elasticClient.lastDocuments, 3)
+	for i, list := range []*domain.EpochComputors{computorsList[2], computorsList[4], computorsList[5]} {
+		doc, err := convertToDocument(list)
+		require.NoError(t, err)
+		assert.Equal(t, doc, elasticClient.lastDocuments[i])
+	}
+}
+
+func TestProcessor_CalculateId_GivenOtherTick_ThenSameId(t *testing.T) {
+	list := &domain.EpochComputors{Epoch: 1, TickNumber: 100, Identities: []string{"A"}, Signature: "signature-1"}
+	id, err := calculateUniqueId(list)
+	require.NoError(t, err)
+
+	list.TickNumber = 200
+	sameId, err := calculateUniqueId(list)
+	require.NoError(t, err)
+	assert.Equal(t, id, sameId)
+
+	list.Epoch = 2
+	otherId, err := calculateUniqueId(list)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, otherId)
+}
+
 func TestProcessor_CalculateId(t *testing.T) {
 	content, err := os.ReadFile("testdata/example-computors-list.json")
 	var computors *domain.EpochComputors
@@ -120,7 +204,7 @@ func TestProcessor_CalculateId(t *testing.T) {
 
 	id, err := calculateUniqueId(computors)
 	require.NoError(t, err)
-	require.Equal(t, "0311802cd338e16653c563c94934de9673bc42561c9b5ee6d5784293cc68de50", id)
+	require.Equal(t, "25a35ddd49086298bde075ec87d7346acceffeef1b65dcbdd8bcaa9f60d372db", id)
 }
 
 func TestProcessor_ConvertToDocument(t *testing.T) {
@@ -134,6 +218,6 @@ func TestProcessor_ConvertToDocument(t *testing.T) {
 
 	document, err := convertToDocument(computors)
 	require.NoError(t, err)
-	require.Equal(t, "0311802cd338e16653c563c94934de9673bc42561c9b5ee6d5784293cc68de50", document.Id)
+	require.Equal(t, "25a35ddd49086298bde075ec87d7346acceffeef1b65dcbdd8bcaa9f60d372db", document.Id)
 	require.Equal(t, marshalled, document.Payload)
 }
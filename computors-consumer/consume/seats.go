@@ -0,0 +1,144 @@
+package consume
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/qubic/computors-consumer/domain"
+	"github.com/qubic/computors-consumer/elastic"
+	"github.com/qubic/go-qubic/common"
+	"go.uber.org/zap"
+)
+
+// indexSeats derives the computor seats of the epochs of the messages (and the previous epochs, because their last
+// seats end with the new epoch) from all known lists. The documents are complete and have stable ids, so that
+// indexing them again on replay results in the same documents.
+func (p *EpochProcessor) indexSeats(ctx context.Context, messages []*domain.EpochComputors) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	epochs := make(map[uint32]bool)
+	for _, message := range messages {
+		epochs[message.Epoch] = true
+		if message.Epoch > 0 {
+			epochs[message.Epoch-1] = true
+		}
+	}
+
+	listsPerEpoch := make(map[uint32][]*domain.EpochComputors)
+	epochLists := func(epoch uint32) ([]*domain.EpochComputors, error) {
+		lists, ok := listsPerEpoch[epoch]
+		if !ok {
+			var err error
+			lists, err = p.findComputorLists(ctx, epoch, messages)
+			if err != nil {
+				return nil, fmt.Errorf("finding computor lists of epoch [%d]: %w", epoch, err)
+			}
+			listsPerEpoch[epoch] = lists
+		}
+		return lists, nil
+	}
+
+	var documents []*elastic.EsDocument
+	for _, epoch := range slices.Sorted(maps.Keys(epochs)) {
+		lists, err := epochLists(epoch)
+		if err != nil {
+			return err
+		}
+		nextLists, err := epochLists(epoch + 1)
+		if err != nil {
+			return err
+		}
+		var nextEpochTick uint32
+		if len(nextLists) > 0 {
+			nextEpochTick = nextLists[0].TickNumber
+		}
+
+		for _, seat := range domain.DeriveSeats(lists, nextEpochTick) {
+			document, err := convertSeatToDocument(seat)
+			if err != nil {
+				return fmt.Errorf("converting computor seat to elastic document: %w", err)
+			}
+			documents = append(documents, document)
+		}
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+
+	zap.S().Infow("Index computor seats.", "epochs", len(epochs), "count", len(documents))
+	err := p.elasticClient.BulkIndexSeats(ctx, documents)
+	if err != nil {
+		return fmt.Errorf("bulk indexing computor seats: %w", err)
+	}
+	return nil
+}
+
+// findComputorLists returns the stored lists of the epoch and the lists of the epoch in the messages ordered by tick
+// number. Messages replace stored lists of the same tick.
+func (p *EpochProcessor) findComputorLists(ctx context.Context, epoch uint32, messages []*domain.EpochComputors) ([]*domain.EpochComputors, error) {
+	storedLists, err := p.elasticClient.FindComputorsListsForEpoch(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	listPerTick := make(map[uint32]*domain.EpochComputors, len(storedLists))
+	for _, stored := range storedLists {
+		listPerTick[stored.TickNumber] = &domain.EpochComputors{
+			Epoch:      stored.Epoch,
+			TickNumber: stored.TickNumber,
+			Identities: stored.Identities,
+			Signature:  stored.Signature,
+		}
+	}
+	for _, message := range messages {
+		if message.Epoch == epoch {
+			listPerTick[message.TickNumber] = message
+		}
+	}
+
+	lists := make([]*domain.EpochComputors, 0, len(listPerTick))
+	for _, tick := range slices.Sorted(maps.Keys(listPerTick)) {
+		lists = append(lists, listPerTick[tick])
+	}
+	return lists, nil
+}
+
+func convertSeatToDocument(seat *domain.ComputorSeat) (*elastic.EsDocument, error) {
+	val, err := json.Marshal(seat)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling computor seat %+v: %w", seat, err)
+	}
+
+	id, err := calculateSeatId(seat)
+	if err != nil {
+		return nil, fmt.Errorf("creating seat id: %w", err)
+	}
+	return &elastic.EsDocument{
+		Id:      id,
+		Payload: val,
+	}, nil
+}
+
+// calculateSeatId creates the id of the seat. The end of the seat is not part of the id, so that the document is
+// updated, when the seat ends.
+func calculateSeatId(seat *domain.ComputorSeat) (string, error) {
+	var buff bytes.Buffer
+	_ = binary.Write(&buff, binary.LittleEndian, seat.Epoch)
+	_ = binary.Write(&buff, binary.LittleEndian, uint32(seat.Index))
+	_ = binary.Write(&buff, binary.LittleEndian, seat.FromTick)
+	buff.WriteString(seat.Identity)
+
+	hash, err := common.K12Hash(buff.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to hash seat: %w", err)
+	}
+	return hex.EncodeToString(hash[:]), nil
+}
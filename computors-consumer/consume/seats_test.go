@@ -0,0 +1,93 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/qubic/computors-consumer/domain"
+	"github.com/qubic/computors-consumer/elastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seatsOf(t *testing.T, documents []*elastic.EsDocument) []*domain.ComputorSeat {
+	seats := make([]*domain.ComputorSeat, 0, len(documents))
+	for _, document := range documents {
+		var seat domain.ComputorSeat
+		require.NoError(t, json.Unmarshal(document.Payload, &seat))
+		id, err := calculateSeatId(&seat)
+		require.NoError(t, err)
+		require.Equal(t, id, document.Id)
+		seats = append(seats, &seat)
+	}
+	return seats
+}
+
+func TestProcessor_ConsumeBatch_IndexSeats(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{computorsList: []*domain.EpochComputors{
+		{Epoch: 101, TickNumber: 2000, Identities: []string{"A", "D"}, Signature: "signature-3"},
+	}}
+	elasticClient := &FakeElasticClient{storedLists: map[uint32][]*elastic.ComputorsList{
+		100: {
+			{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B"}, Signature: "signature-1"},
+			{Epoch: 100, TickNumber: 1500, Identities: []string{"A", "C"}, Signature: "signature-2"},
+		},
+	}}
+	processor := NewEpochProcessor(kafkaClient, elasticClient, m)
+
+	_, err := processor.consumeBatch(context.Background())
+	require.NoError(t, err)
+
+	// seats of the previous epoch end with the new epoch
+	assert.Equal(t, []*domain.ComputorSeat{
+		{Epoch: 100, Index: 0, Identity: "A", FromTick: 1000, ToTick: 1999},
+		{Epoch: 100, Index: 1, Identity: "B", FromTick: 1000, ToTick: 1499},
+		{Epoch: 100, Index: 1, Identity: "C", FromTick: 1500, ToTick: 1999},
+		{Epoch: 101, Index: 0, Identity: "A", FromTick: 2000},
+		{Epoch: 101, Index: 1, Identity: "D", FromTick: 2000},
+	}, seatsOf(t, elasticClient.lastSeatDocuments))
+}
+
+func TestProcessor_ConsumeBatch_GivenReplay_ThenSameSeats(t *testing.T) {
+	message := &domain.EpochComputors{Epoch: 100, TickNumber: 1500, Identities: []string{"A", "C"}, Signature: "signature-2"}
+	kafkaClient := &FakeKafkaClient{computorsList: []*domain.EpochComputors{message}}
+	elasticClient := &FakeElasticClient{storedLists: map[uint32][]*elastic.ComputorsList{
+		100: {{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B"}, Signature: "signature-1"}},
+	}}
+	processor := NewEpochProcessor(kafkaClient, elasticClient, m)
+
+	_, err := processor.consumeBatch(context.Background())
+	require.NoError(t, err)
+	firstDocuments := elasticClient.lastSeatDocuments
+	require.Len(t, firstDocuments, 3)
+
+	// replay after the list was stored. The list is a duplicate, but the seats are indexed again.
+	elasticClient.storedLists[100] = append(elasticClient.storedLists[100], &elastic.ComputorsList{
+		Epoch: 100, TickNumber: 1500, Identities: []string{"A", "C"}, Signature: "signature-2",
+	})
+	elasticClient.duplicate = &elastic.ComputorsList{Epoch: 100, TickNumber: 1500, Signature: "signature-2"}
+	elasticClient.lastSeatDocuments = nil
+
+	_, err = processor.consumeBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, firstDocuments, elasticClient.lastSeatDocuments)
+}
+
+func TestProcessor_calculateSeatId(t *testing.T) {
+	seat := &domain.ComputorSeat{Epoch: 100, Index: 1, Identity: "A", FromTick: 1000}
+	id, err := calculateSeatId(seat)
+	require.NoError(t, err)
+	assert.Len(t, id, 64)
+
+	// end of seat does not change the id
+	seat.ToTick = 1999
+	sameId, err := calculateSeatId(seat)
+	require.NoError(t, err)
+	assert.Equal(t, id, sameId)
+
+	seat.FromTick = 1500
+	otherId, err := calculateSeatId(seat)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, otherId)
+}
@@ -6,3 +6,42 @@ type EpochComputors struct {
 	Identities []string `json:"identities"`
 	Signature  string   `json:"signature"` // hex -> base64
 }
+
+// ComputorSeat is the seat of an identity in the computor lists of an epoch and the ticks, in which it was valid.
+type ComputorSeat struct {
+	Epoch    uint32 `json:"epoch"`
+	Index    int    `json:"index"`
+	Identity string `json:"identity"`
+	FromTick uint32 `json:"fromTick"`
+	ToTick   uint32 `json:"toTick,omitempty"` // last tick. Empty, if the seat is still valid.
+}
+
+// DeriveSeats derives the seats from the consecutive computor lists of one epoch, that need to be ordered by tick
+// number. A seat ends in the tick before the list, that has another identity at the index. Seats of the last list end
+// in the tick before the next epoch or are open, if the first tick of the next epoch is unknown (zero).
+func DeriveSeats(lists []*EpochComputors, nextEpochTick uint32) []*ComputorSeat {
+	var seats []*ComputorSeat
+	open := make(map[int]*ComputorSeat)
+	for _, list := range lists {
+		for index, seat := range open {
+			if index >= len(list.Identities) || list.Identities[index] != seat.Identity {
+				seat.ToTick = list.TickNumber - 1
+				delete(open, index)
+			}
+		}
+		for index, identity := range list.Identities {
+			if _, ok := open[index]; ok || identity == "" {
+				continue // unchanged or empty seat
+			}
+			seat := &ComputorSeat{Epoch: list.Epoch, Index: index, Identity: identity, FromTick: list.TickNumber}
+			open[index] = seat
+			seats = append(seats, seat)
+		}
+	}
+	if nextEpochTick > 0 {
+		for _, seat := range open {
+			seat.ToTick = nextEpochTick - 1
+		}
+	}
+	return seats
+}
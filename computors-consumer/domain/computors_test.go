@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveSeats(t *testing.T) {
+	lists := []*EpochComputors{
+		{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B", "C"}},
+		{Epoch: 100, TickNumber: 1500, Identities: []string{"A", "D", "C"}},
+		{Epoch: 100, TickNumber: 1800, Identities: []string{"A", "B", ""}},
+	}
+
+	assert.Equal(t, []*ComputorSeat{
+		{Epoch: 100, Index: 0, Identity: "A", FromTick: 1000},
+		{Epoch: 100, Index: 1, Identity: "B", FromTick: 1000, ToTick: 1499},
+		{Epoch: 100, Index: 2, Identity: "C", FromTick: 1000, ToTick: 1799},
+		{Epoch: 100, Index: 1, Identity: "D", FromTick: 1500, ToTick: 1799},
+		{Epoch: 100, Index: 1, Identity: "B", FromTick: 1800},
+	}, DeriveSeats(lists, 0))
+}
+
+func TestDeriveSeats_GivenNextEpoch_ThenCloseSeats(t *testing.T) {
+	lists := []*EpochComputors{
+		{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B"}},
+		{Epoch: 100, TickNumber: 1500, Identities: []string{"A"}},
+	}
+
+	assert.Equal(t, []*ComputorSeat{
+		{Epoch: 100, Index: 0, Identity: "A", FromTick: 1000, ToTick: 1999},
+		{Epoch: 100, Index: 1, Identity: "B", FromTick: 1000, ToTick: 1499},
+	}, DeriveSeats(lists, 2000))
+}
+
+func TestDeriveSeats_GivenNoLists_ThenEmpty(t *testing.T) {
+	assert.Empty(t, DeriveSeats(nil, 2000))
+}
@@ -1,17 +1,12 @@
 package elastic
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"runtime"
 	"strings"
-	"time"
 
-	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/qubic/sink"
 )
 
 type EsDocument struct {
@@ -20,14 +15,18 @@ type EsDocument struct {
 }
 
 type Client struct {
-	esClient  *elasticsearch.Client
-	indexName string
+	sink                   sink.Client
+	indexName              string
+	seatsIndexName         string
+	tickIntervalsIndexName string
 }
 
-func NewClient(esClient *elasticsearch.Client, indexName string) *Client {
+func NewClient(sinkClient sink.Client, indexName, seatsIndexName, tickIntervalsIndexName string) *Client {
 	return &Client{
-		esClient:  esClient,
-		indexName: indexName,
+		sink:                   sinkClient,
+		indexName:              indexName,
+		seatsIndexName:         seatsIndexName,
+		tickIntervalsIndexName: tickIntervalsIndexName,
 	}
 }
 
@@ -45,10 +44,21 @@ type computorsHit struct {
 	Source ComputorsList `json:"_source"`
 }
 
+type tickIntervalsResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source struct {
+				Epoch uint32 `json:"epoch"`
+			} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
 type ComputorsList struct {
-	Epoch      uint32 `json:"epoch"`
-	TickNumber uint32 `json:"tickNumber"`
-	Signature  string `json:"signature"`
+	Epoch      uint32   `json:"epoch"`
+	TickNumber uint32   `json:"tickNumber"`
+	Identities []string `json:"identities,omitempty"` // only, if requested
+	Signature  string   `json:"signature"`
 }
 
 func (c *Client) FindLatestComputorsListForEpoch(ctx context.Context, epoch uint32) (*ComputorsList, error) {
@@ -58,23 +68,9 @@ func (c *Client) FindLatestComputorsListForEpoch(ctx context.Context, epoch uint
 		return nil, fmt.Errorf("creating query: %w", err)
 	}
 
-	res, err := c.esClient.Search(
-		c.esClient.Search.WithContext(ctx),
-		c.esClient.Search.WithSource("epoch", "tickNumber", "signature"),
-		c.esClient.Search.WithIndex(c.indexName),
-		c.esClient.Search.WithBody(strings.NewReader(query)),
-	)
+	result, err := c.search(ctx, query, "epoch", "tickNumber", "signature")
 	if err != nil {
-		return nil, fmt.Errorf("performing search: %w", err)
-	}
-	defer res.Body.Close()
-
-	if res.IsError() {
-		return nil, fmt.Errorf("got error response from elastic: %s", res.String())
-	}
-	var result searchResponse
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
+		return nil, err
 	}
 
 	if result.Hits.Total.Value > 0 {
@@ -90,54 +86,115 @@ func createFindLatestComputorsListInEpoch(epoch uint32) (string, error) {
 	return query, nil
 }
 
-func (c *Client) BulkIndex(ctx context.Context, data []*EsDocument) error {
-	start := time.Now().UnixMilli()
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Index:      c.indexName,              // The default index name
-		Client:     c.esClient,               // The Elasticsearch client
-		NumWorkers: min(runtime.NumCPU(), 8), // 8 parallel connections are enough
-	})
+// FindLatestComputorsListWithIdentitiesForEpoch returns the latest list of the epoch including the identities or nil,
+// if there is none.
+func (c *Client) FindLatestComputorsListWithIdentitiesForEpoch(ctx context.Context, epoch uint32) (*ComputorsList, error) {
+	query, err := createFindLatestComputorsListInEpoch(epoch)
 	if err != nil {
-		return fmt.Errorf("creating bulk indexer: %w", err)
+		return nil, fmt.Errorf("creating query: %w", err)
 	}
+	return c.searchFirst(ctx, query)
+}
 
-	for _, d := range data {
-		item := esutil.BulkIndexerItem{
-			Action:       "index",
-			DocumentID:   d.Id,
-			RequireAlias: true,
-			Body:         bytes.NewReader(d.Payload),
-			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
-				msg := "Error indexing document"
-				if err != nil {
-					log.Printf("%s [%s]: %s: [%s]", msg, d.Id, string(d.Payload), err)
-				} else {
-					log.Printf("%s [%s]: %s: [%s: %s]", msg, d.Id, string(d.Payload), res.Error.Type, res.Error.Reason)
-				}
-			},
-		}
-		err = bi.Add(ctx, item)
-	}
-
-	err = bi.Close(ctx)
+// FindComputorsListForTick returns the list, that is effective in the tick, including the identities or nil, if there
+// is none. This is the list of the epoch of the tick with the highest tick number, that is not larger than the tick.
+// The epoch is read from the tick intervals index. Ticks outside the known intervals (for example future ticks) have
+// no list.
+func (c *Client) FindComputorsListForTick(ctx context.Context, tick uint32) (*ComputorsList, error) {
+	epoch, found, err := c.findEpochForTick(ctx, tick)
 	if err != nil {
-		return fmt.Errorf("closing bulk indexer: %w", err)
+		return nil, fmt.Errorf("finding epoch of tick [%d]: %w", tick, err)
 	}
+	if !found {
+		return nil, nil
+	}
+	query := `{ "size": 1, "query": { "bool": { "filter": [ { "term": { "epoch": %d } }, { "range": { "tickNumber": { "lte": %d } } } ] } }, "sort": [ { "tickNumber": {  "order": "desc" } } ] }`
+	return c.searchFirst(ctx, fmt.Sprintf(query, epoch, tick))
+}
 
-	biStats := bi.Stats()
-	end := time.Now().UnixMilli()
-	if biStats.NumFailed > 0 {
-		return fmt.Errorf("%d errors indexing [%d] documents",
-			biStats.NumFailed,
-			biStats.NumFlushed,
-		)
-	} else {
-		log.Printf("Indexed %d documents (%d bytes, %d requests) in %dms.",
-			biStats.NumFlushed,
-			biStats.FlushedBytes,
-			biStats.NumRequests,
-			end-start,
-		)
-	}
-	return nil
+// findEpochForTick returns the epoch of the tick interval, that contains the tick. Returns false, if there is none.
+func (c *Client) findEpochForTick(ctx context.Context, tick uint32) (uint32, bool, error) {
+	query := `{ "size": 1, "_source": [ "epoch" ], "query": { "bool": { "filter": [ { "range": { "from": { "lte": %d } } }, { "range": { "to": { "gte": %d } } } ] } } }`
+	var result tickIntervalsResponse
+	err := c.sink.Search(ctx, c.tickIntervalsIndexName, fmt.Sprintf(query, tick, tick), &result)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(result.Hits.Hits) == 0 {
+		return 0, false, nil
+	}
+	return result.Hits.Hits[0].Source.Epoch, true, nil
+}
+
+func (c *Client) searchFirst(ctx context.Context, query string) (*ComputorsList, error) {
+	result, err := c.search(ctx, query, "epoch", "tickNumber", "identities", "signature")
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Hits.Hits) == 0 {
+		return nil, nil
+	}
+	return &result.Hits.Hits[0].Source, nil
+}
+
+// FindComputorsListsForEpoch returns all computor lists of the epoch including the identities ordered by tick number.
+func (c *Client) FindComputorsListsForEpoch(ctx context.Context, epoch uint32) ([]*ComputorsList, error) {
+	query := createFindComputorsListsInEpoch(epoch)
+	result, err := c.search(ctx, query, "epoch", "tickNumber", "identities", "signature")
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]*ComputorsList, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		lists = append(lists, &hit.Source)
+	}
+	return lists, nil
+}
+
+func createFindComputorsListsInEpoch(epoch uint32) string {
+	// there are only a few lists per epoch
+	query := `{ "size": 100, "query": { "term": { "epoch": %d } }, "sort": [ { "tickNumber": {  "order": "asc" } } ] }`
+	return fmt.Sprintf(query, epoch)
+}
+
+// search limits the returned fields to the source fields.
+func (c *Client) search(ctx context.Context, query string, source ...string) (*searchResponse, error) {
+	var body map[string]any
+	decoder := json.NewDecoder(strings.NewReader(query))
+	decoder.UseNumber() // keep the numbers as they are
+	err := decoder.Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing query: %w", err)
+	}
+	body["_source"] = source
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling query: %w", err)
+	}
+
+	var result searchResponse
+	err = c.sink.Search(ctx, c.indexName, string(data), &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BulkIndex indexes computor lists. Waits until the lists are searchable, so that the next batch finds them.
+func (c *Client) BulkIndex(ctx context.Context, data []*EsDocument) error {
+	return c.sink.BulkIndexAndWait(ctx, documents(data), c.indexName)
+}
+
+// BulkIndexSeats indexes computor seats.
+func (c *Client) BulkIndexSeats(ctx context.Context, data []*EsDocument) error {
+	return c.sink.BulkIndex(ctx, documents(data), c.seatsIndexName)
+}
+
+func documents(data []*EsDocument) []sink.Document {
+	result := make([]sink.Document, 0, len(data))
+	for _, d := range data {
+		result = append(result, sink.Document{Id: d.Id, Payload: d.Payload})
+	}
+	return result
 }
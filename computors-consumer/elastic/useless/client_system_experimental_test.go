@@ -18,6 +18,7 @@ import (
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/joho/godotenv"
 	"github.com/qubic/computors-consumer/elastic"
+	"github.com/qubic/sink"
 	"github.com/stretchr/testify/require"
 )
 
@@ -39,6 +40,13 @@ func TestElasticClient_getLatestComputorsList_givenNoData_thenReturnNil(t *testi
 	require.Nil(t, computors)
 }
 
+func TestElasticClient_findComputorsListsForEpoch(t *testing.T) {
+	lists, err := elasticClient.FindComputorsListsForEpoch(context.Background(), 167)
+	require.NoError(t, err)
+	require.NotEmpty(t, lists)
+	require.NotEmpty(t, lists[0].Identities)
+}
+
 func TestMain(m *testing.M) {
 	setup()
 	// Parse args and run
@@ -56,11 +64,13 @@ func setup() {
 	}
 	var cfg struct {
 		Elastic struct {
-			Addresses   []string `conf:"default:https://localhost:9200"`
-			Username    string   `conf:"default:qubic-ingestion"`
-			Password    string   `conf:"optional,mask"`
-			IndexName   string   `conf:"default:qubic-computors-alias"`
-			Certificate string   `conf:"default:http_ca.crt"`
+			Addresses              []string `conf:"default:https://localhost:9200"`
+			Username               string   `conf:"default:qubic-ingestion"`
+			Password               string   `conf:"optional,mask"`
+			IndexName              string   `conf:"default:qubic-computors-alias"`
+			SeatsIndexName         string   `conf:"default:qubic-computor-seats-alias"`
+			TickIntervalsIndexName string   `conf:"default:qubic-tick-intervals-alias"`
+			Certificate            string   `conf:"default:http_ca.crt"`
 		}
 	}
 	err = conf.Parse(os.Args[1:], envPrefix, &cfg)
@@ -88,5 +98,5 @@ func setup() {
 	if err != nil {
 		log.Fatalf("error creating elastic client: %v", err)
 	}
-	elasticClient = elastic.NewClient(esClient, cfg.Elastic.IndexName)
+	elasticClient = elastic.NewClient(sink.NewElasticClient(esClient), cfg.Elastic.IndexName, cfg.Elastic.SeatsIndexName, cfg.Elastic.TickIntervalsIndexName)
 }
@@ -5,17 +5,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/qubic/computors-consumer/domain"
+	"github.com/qubic/logging"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
 )
 
+// RecordClient polls the records. It is the kgo client of the consumer group or a replay client.
+type RecordClient interface {
+	PollRecords(ctx context.Context, maxPollRecords int) kgo.Fetches
+	CommitUncommittedOffsets(ctx context.Context) error
+	AllowRebalance()
+}
+
 type Client struct {
-	kcl *kgo.Client
+	kcl RecordClient
 }
 
-func NewClient(kafkaClient *kgo.Client) *Client {
+func NewClient(kafkaClient RecordClient) *Client {
 	return &Client{
 		kcl: kafkaClient,
 	}
@@ -25,7 +33,7 @@ func (c *Client) PollMessages(ctx context.Context) ([]*domain.EpochComputors, er
 	fetches := c.kcl.PollRecords(ctx, 100)
 	if errs := fetches.Errors(); len(errs) > 0 {
 		for _, err := range errs {
-			log.Printf("Error: %v", err)
+			zap.S().Errorw("Fetching records failed.", "topic", err.Topic, logging.Partition, err.Partition, logging.Error, err.Err)
 		}
 		return nil, errors.New("fetching records")
 	}
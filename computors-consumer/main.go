@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
@@ -11,39 +12,50 @@ import (
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
-	"github.com/elastic/elastic-transport-go/v8/elastictransport"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/computors-consumer/api"
 	"github.com/qubic/computors-consumer/consume"
 	"github.com/qubic/computors-consumer/elastic"
 	"github.com/qubic/computors-consumer/kafka"
 	"github.com/qubic/computors-consumer/metrics"
 	"github.com/qubic/computors-consumer/status"
+	"github.com/qubic/logging"
+	"github.com/qubic/replay"
+	"github.com/qubic/sink"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_COMPUTORS_CONSUMER"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("computors-consumer", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-	log.SetOutput(os.Stdout)
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Elastic struct {
-			Addresses   []string `conf:"default:https://localhost:9200"`
-			Username    string   `conf:"default:qubic-ingestion"`
-			Password    string   `conf:"optional,mask"`
-			IndexName   string   `conf:"default:qubic-computors-alias"`
-			Certificate string   `conf:"default:http_ca.crt"`
-			MaxRetries  int      `conf:"default:15"`
+			Addresses              []string `conf:"default:https://localhost:9200"`
+			Username               string   `conf:"default:qubic-ingestion"`
+			Password               string   `conf:"optional,mask"`
+			IndexName              string   `conf:"default:qubic-computors-alias"`
+			SeatsIndexName         string   `conf:"default:qubic-computor-seats-alias"`
+			TickIntervalsIndexName string   `conf:"default:qubic-tick-intervals-alias"` // read by the api
+			Certificate            string   `conf:"default:http_ca.crt"`
+			MaxRetries             int      `conf:"default:15"`
+			Sink                   string   `conf:"default:elasticsearch"` // elasticsearch, opensearch or ndjson
+			BulkUrl                string   `conf:"optional"`              // bulk endpoint of the ndjson sink. Defaults to <address>/_bulk.
 		}
 		Broker struct {
 			BootstrapServers []string `conf:"default:localhost:9092"`
@@ -54,6 +66,18 @@ func run() error {
 			MetricsPort      int    `conf:"default:9999"`
 			MetricsNamespace string `conf:"default:qubic_kafka"`
 		}
+		Api struct {
+			Port      int           `conf:"default:8000"`
+			CacheSize int           `conf:"default:1000"` // found lists. Zero disables the cache.
+			CacheTtl  time.Duration `conf:"default:1m"`
+		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
+		Replay struct {
+			replay.Options
+		}
+		Args conf.Args
 	}
 
 	help, err := conf.Parse(envPrefix, &cfg)
@@ -69,28 +93,15 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("generating config for output: %w", err)
 	}
-	log.Printf("main: Config :\n%v\n", out)
-
-	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
-		kprom.Registerer(prometheus.DefaultRegisterer),
-		kprom.Gatherer(prometheus.DefaultGatherer))
-	kcl, err := kgo.NewClient(
-		kgo.WithHooks(m),
-		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
-		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
-		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
-		kgo.BlockRebalanceOnPoll(),
-		kgo.DisableAutoCommit(),
-	)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
 	if err != nil {
-		log.Fatal(err)
+		return errors.Wrap(err, "setting log level")
 	}
-	defer kcl.Close()
 
 	cert, err := os.ReadFile(cfg.Elastic.Certificate)
 	if err != nil {
-		log.Printf("[WARN] main: could not read elastic certificate: %v", err)
+		zap.S().Warnw("Could not read elastic certificate.", logging.Error, err)
 	}
 
 	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
@@ -101,16 +112,62 @@ func run() error {
 		RetryOnStatus: []int{502, 503, 504, 429},
 		MaxRetries:    cfg.Elastic.MaxRetries,
 		RetryBackoff:  calculateBackoff(),
-		Logger: elastic.NewLogger(&elastictransport.TextLogger{
-			Output:             os.Stdout,
-			EnableRequestBody:  false,
-			EnableResponseBody: true, // Make response body available in the logger so we can log response
-		}),
+		Logger:        elastic.NewLogger(),
 	})
+	if err != nil {
+		return errors.Wrap(err, "creating elastic client")
+	}
+	sinkClient, err := sink.NewClient(context.Background(), esClient, sink.HttpBulkConfig{
+		Sink:         cfg.Elastic.Sink,
+		Addresses:    cfg.Elastic.Addresses,
+		BulkUrl:      cfg.Elastic.BulkUrl,
+		Username:     cfg.Elastic.Username,
+		Password:     cfg.Elastic.Password,
+		CACert:       cert,
+		MaxRetries:   cfg.Elastic.MaxRetries,
+		RetryBackoff: calculateBackoff(),
+	})
+	if err != nil {
+		return err
+	}
+	elasticClient := elastic.NewClient(sinkClient, cfg.Elastic.IndexName, cfg.Elastic.SeatsIndexName, cfg.Elastic.TickIntervalsIndexName)
+	consumeMetrics := metrics.NewMetrics(cfg.Sync.MetricsNamespace)
+
+	if len(cfg.Args) > 0 {
+		// the records are keyed by epoch, so there is no tick range
+		replayConfig, err := replay.NewConfig(cfg.Broker.ConsumeTopic, cfg.Replay.Options, replay.TickRangeOptions{})
+		if err != nil {
+			return errors.Wrap(err, "parsing replay config")
+		}
+		return runReplayCommand(context.Background(), cfg.Args, replayCommand{
+			config:         replayConfig,
+			brokers:        cfg.Broker.BootstrapServers,
+			dryRun:         cfg.Replay.DryRun,
+			elasticClient:  elasticClient,
+			indexName:      cfg.Elastic.IndexName,
+			seatsIndexName: cfg.Elastic.SeatsIndexName,
+			metrics:        consumeMetrics,
+		})
+	}
+
+	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
+		kprom.Registerer(prometheus.DefaultRegisterer),
+		kprom.Gatherer(prometheus.DefaultGatherer))
+	kcl, err := kgo.NewClient(
+		kgo.WithHooks(m),
+		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
+		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
+		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
+		kgo.WithLogger(logging.NewKafkaLogger()),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.DisableAutoCommit(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating kafka client")
+	}
+	defer kcl.Close()
 
-	elasticClient := elastic.NewClient(esClient, cfg.Elastic.IndexName)
 	kafkaClient := kafka.NewClient(kcl)
-	consumeMetrics := metrics.NewMetrics(cfg.Sync.MetricsNamespace)
 	processor := consume.NewEpochProcessor(kafkaClient, elasticClient, consumeMetrics)
 
 	procError := make(chan error, 1)
@@ -123,28 +180,38 @@ func run() error {
 
 	serverError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting health and metrics endpoint on port [%d].", cfg.Sync.MetricsPort)
+		zap.S().Infow("Starting health, metrics and log level endpoint.", "port", cfg.Sync.MetricsPort)
 		http.HandleFunc("/health", status.Health)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		serverError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	apiError := make(chan error, 1)
+	go func() {
+		mux := http.NewServeMux()
+		handler := api.NewHandler(elasticClient, cfg.Api.CacheSize, cfg.Api.CacheTtl)
+		mux.HandleFunc("GET /v1/computors/ticks/{tick}", handler.GetComputorsListForTick)
+		mux.HandleFunc("GET /v1/computors/epochs/{epoch}", handler.GetComputorsListForEpoch)
+		zap.S().Infow("Starting computors list api.", "port", cfg.Api.Port)
+		apiError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Api.Port), mux)
+	}()
+
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-procError:
-			return fmt.Errorf("[ERROR] processing: %v", err)
+			return errors.Wrap(err, "processing")
 		case err := <-serverError:
-			return fmt.Errorf("[ERROR] starting server: %v", err)
+			return errors.Wrap(err, "starting server")
+		case err := <-apiError:
+			return errors.Wrap(err, "starting api server")
 		}
 	}
-
-	return nil
-
 }
 
 // calculateBackoff needs retry number because of multi threading
@@ -156,7 +223,7 @@ func calculateBackoff() func(i int) time.Duration {
 		} else {
 			d = time.Second*30 + randomMillis()
 		}
-		log.Printf("[WARN] elasticsearch client retry [%d] in %v.", i, d)
+		zap.S().Warnw("Retrying elasticsearch request.", "attempt", i, logging.Duration, d)
 		return d
 	}
 }
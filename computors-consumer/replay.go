@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ardanlabs/conf/v3"
+	"github.com/pkg/errors"
+	"github.com/qubic/computors-consumer/consume"
+	"github.com/qubic/computors-consumer/elastic"
+	"github.com/qubic/computors-consumer/kafka"
+	"github.com/qubic/computors-consumer/metrics"
+	"github.com/qubic/replay"
+	"go.uber.org/zap"
+)
+
+type replayCommand struct {
+	config         replay.Config
+	brokers        []string
+	dryRun         bool
+	elasticClient  consume.ElasticClient
+	indexName      string
+	seatsIndexName string
+	metrics        *metrics.Metrics
+}
+
+// runReplayCommand re-indexes a range of the topic into the configured indexes. The live consumer group is not used,
+// so the service can keep running during the replay.
+func runReplayCommand(ctx context.Context, args conf.Args, cmd replayCommand) error {
+	if args.Num(0) != "replay" {
+		return fmt.Errorf("unknown command [%s]\n%s", args.Num(0), replay.Usage)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := replay.NewClient(ctx, cmd.brokers, cmd.config)
+	if err != nil {
+		return errors.Wrap(err, "creating replay client")
+	}
+	defer client.Close()
+
+	elasticClient := cmd.elasticClient
+	var counter *countingClient
+	if cmd.dryRun {
+		counter = &countingClient{
+			ElasticClient:  elasticClient,
+			counter:        replay.NewCounter(),
+			indexName:      cmd.indexName,
+			seatsIndexName: cmd.seatsIndexName,
+		}
+		elasticClient = counter
+	}
+
+	processor := consume.NewEpochProcessor(kafka.NewClient(client), elasticClient, cmd.metrics)
+	count, err := processor.Replay(ctx, client.Done)
+	if err != nil {
+		return errors.Wrapf(err, "replaying after [%d] computor lists", count)
+	}
+	zap.S().Infow("Replay finished.", "lists", count)
+	if counter != nil {
+		counter.counter.Print(os.Stdout, "documents")
+	}
+	return nil
+}
+
+// countingClient replaces the indexing of the elastic client for dry runs. It only counts the documents per index.
+// Stored lists are still read from the index.
+type countingClient struct {
+	consume.ElasticClient
+	counter        *replay.Counter
+	indexName      string
+	seatsIndexName string
+}
+
+func (c *countingClient) BulkIndex(_ context.Context, data []*elastic.EsDocument) error {
+	c.counter.Add(c.indexName, len(data))
+	return nil
+}
+
+func (c *countingClient) BulkIndexSeats(_ context.Context, data []*elastic.EsDocument) error {
+	c.counter.Add(c.seatsIndexName, len(data))
+	return nil
+}
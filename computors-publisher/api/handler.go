@@ -2,19 +2,30 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
+type Checker interface {
+	ForceCheck() bool
+}
+
 type Handler struct {
+	checker Checker
 }
 
 type HealthResponse struct {
 	Status string `json:"status"`
 }
 
-func NewHandler() *Handler {
-	return &Handler{}
+type CheckResponse struct {
+	Status string `json:"status"` // SCHEDULED or PENDING, if a forced check is pending already
+}
+
+func NewHandler(checker Checker) *Handler {
+	return &Handler{checker: checker}
 }
 
 func (h *Handler) GetHealth(w http.ResponseWriter, _ *http.Request) {
@@ -23,8 +34,22 @@ func (h *Handler) GetHealth(w http.ResponseWriter, _ *http.Request) {
 		Status: "UP",
 	})
 	if err != nil {
-		log.Printf("Error encoding response: %v", err)
+		zap.S().Errorw("Encoding response failed.", logging.Error, err)
 		http.Error(w, "Error encoding response", 500)
 		return
 	}
 }
+
+// PostCheck forces a check for new computor lists.
+func (h *Handler) PostCheck(w http.ResponseWriter, _ *http.Request) {
+	response := CheckResponse{Status: "SCHEDULED"}
+	if !h.checker.ForceCheck() {
+		response.Status = "PENDING"
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		zap.S().Errorw("Encoding response failed.", logging.Error, err)
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FakeChecker struct {
+	pending bool
+}
+
+func (f *FakeChecker) ForceCheck() bool {
+	if f.pending {
+		return false
+	}
+	f.pending = true
+	return true
+}
+
+func TestHandler_PostCheck(t *testing.T) {
+	handler := NewHandler(&FakeChecker{})
+
+	recorder := httptest.NewRecorder()
+	handler.PostCheck(recorder, httptest.NewRequest(http.MethodPost, "/check", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.JSONEq(t, `{"status":"SCHEDULED"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.PostCheck(recorder, httptest.NewRequest(http.MethodPost, "/check", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.JSONEq(t, `{"status":"PENDING"}`, recorder.Body.String())
+}
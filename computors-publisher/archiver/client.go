@@ -3,6 +3,7 @@ package archiver
 import (
 	"context"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -11,7 +12,9 @@ import (
 	"github.com/qubic/computors-publisher/domain"
 	archiverproto "github.com/qubic/go-archiver-v2/protobuf"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 type Client struct {
@@ -99,3 +102,91 @@ func convertComputorList(computors *archiverproto.Computors) (*domain.EpochCompu
 		Signature:  base64.StdEncoding.EncodeToString(sigBytes),
 	}, nil
 }
+
+func (c *Client) GetTickVotes(ctx context.Context, tickNumber uint32) (*domain.TickVotes, error) {
+	response, err := c.api.GetTickQuorumDataV2(ctx, &archiverproto.GetQuorumTickDataRequest{TickNumber: tickNumber})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("getting archiver quorum data: %w: %w", domain.ErrTickNotFound, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting archiver quorum data: %w", err)
+	}
+	if response.GetQuorumTickData().GetQuorumTickStructure() == nil {
+		return nil, fmt.Errorf("nil quorum data for tick [%d]", tickNumber)
+	}
+
+	tickVotes, err := convertTickVotes(response.GetQuorumTickData())
+	if err != nil {
+		return nil, fmt.Errorf("converting quorum data of tick [%d]: %w", tickNumber, err)
+	}
+	return tickVotes, nil
+}
+
+func convertTickVotes(quorumData *archiverproto.QuorumTickData) (*domain.TickVotes, error) {
+	structure := quorumData.GetQuorumTickStructure()
+	tickVotes := domain.TickVotes{
+		Epoch:      structure.Epoch,
+		TickNumber: structure.TickNumber,
+		Timestamp:  structure.Timestamp,
+		Votes:      make(map[uint32]*domain.ComputorVote, len(quorumData.GetQuorumDiffPerComputor())),
+	}
+
+	var err error
+	if tickVotes.PreviousResourceTestingDigest, err = decodeUint32(structure.PrevResourceTestingDigestHex); err != nil {
+		return nil, fmt.Errorf("decoding previous resource testing digest: %w", err)
+	}
+	if tickVotes.PreviousTransactionBodyDigest, err = decodeUint32(structure.PrevTransactionBodyHex); err != nil {
+		return nil, fmt.Errorf("decoding previous transaction body digest: %w", err)
+	}
+	for _, digest := range []struct {
+		hex    string
+		target *[32]byte
+	}{
+		{structure.PrevSpectrumDigestHex, &tickVotes.PreviousSpectrumDigest},
+		{structure.PrevUniverseDigestHex, &tickVotes.PreviousUniverseDigest},
+		{structure.PrevComputerDigestHex, &tickVotes.PreviousComputerDigest},
+		{structure.TxDigestHex, &tickVotes.TxDigest},
+	} {
+		if err = decodeFixed(digest.hex, digest.target[:]); err != nil {
+			return nil, fmt.Errorf("decoding digest [%s]: %w", digest.hex, err)
+		}
+	}
+
+	for index, diff := range quorumData.GetQuorumDiffPerComputor() {
+		var vote domain.ComputorVote
+		if err = decodeFixed(diff.ExpectedNextTickTxDigestHex, vote.ExpectedNextTickTxDigest[:]); err != nil {
+			return nil, fmt.Errorf("decoding expected next tick tx digest of computor [%d]: %w", index, err)
+		}
+		if err = decodeFixed(diff.SignatureHex, vote.Signature[:]); err != nil {
+			return nil, fmt.Errorf("decoding signature of computor [%d]: %w", index, err)
+		}
+		tickVotes.Votes[index] = &vote
+	}
+	return &tickVotes, nil
+}
+
+// decodeUint32 decodes the hex encoded little endian number.
+func decodeUint32(value string) (uint32, error) {
+	var b [4]byte
+	err := decodeFixed(value, b[:])
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+// decodeFixed decodes the hex value into the target. Empty values are zero.
+func decodeFixed(value string, target []byte) error {
+	if value == "" {
+		return nil
+	}
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(target) {
+		return fmt.Errorf("invalid length [%d]", len(decoded))
+	}
+	copy(target, decoded)
+	return nil
+}
@@ -1,6 +1,7 @@
 package archiver
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -8,6 +9,9 @@ import (
 	archiverproto "github.com/qubic/go-archiver-v2/protobuf"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -74,3 +78,64 @@ func TestArchiveClient_convertStatusResponse(t *testing.T) {
 	assert.Subset(t, status.EpochList, []uint32{160, 161, 162, 163, 164, 165, 166, 167, 168})
 
 }
+
+func TestArchiveClient_convertTickVotes(t *testing.T) {
+	quorumData := &archiverproto.QuorumTickData{
+		QuorumTickStructure: &archiverproto.QuorumTickStructure{
+			Epoch:                        168,
+			TickNumber:                   28400000,
+			Timestamp:                    1729080000123,
+			PrevResourceTestingDigestHex: "01000000",
+			PrevSpectrumDigestHex:        "0202020202020202020202020202020202020202020202020202020202020202",
+			PrevUniverseDigestHex:        "0303030303030303030303030303030303030303030303030303030303030303",
+			PrevComputerDigestHex:        "0404040404040404040404040404040404040404040404040404040404040404",
+			TxDigestHex:                  "0505050505050505050505050505050505050505050505050505050505050505",
+			PrevTransactionBodyHex:       "06070000",
+		},
+		QuorumDiffPerComputor: map[uint32]*archiverproto.QuorumDiff{
+			42: {
+				ExpectedNextTickTxDigestHex: "0808080808080808080808080808080808080808080808080808080808080808",
+				SignatureHex:                "09090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909090909",
+			},
+		},
+	}
+
+	votes, err := convertTickVotes(quorumData)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(168), votes.Epoch)
+	assert.Equal(t, uint32(28400000), votes.TickNumber)
+	assert.Equal(t, uint64(1729080000123), votes.Timestamp)
+	assert.Equal(t, uint32(1), votes.PreviousResourceTestingDigest)
+	assert.Equal(t, uint32(0x0706), votes.PreviousTransactionBodyDigest)
+	assert.Equal(t, byte(2), votes.PreviousSpectrumDigest[31])
+	assert.Equal(t, byte(3), votes.PreviousUniverseDigest[31])
+	assert.Equal(t, byte(4), votes.PreviousComputerDigest[31])
+	assert.Equal(t, byte(5), votes.TxDigest[31])
+	require.Len(t, votes.Votes, 1)
+	assert.Equal(t, byte(8), votes.Votes[42].ExpectedNextTickTxDigest[31])
+	assert.Equal(t, byte(9), votes.Votes[42].Signature[63])
+
+	quorumData.QuorumDiffPerComputor[42].SignatureHex = "0909"
+	_, err = convertTickVotes(quorumData)
+	require.ErrorContains(t, err, "decoding signature of computor [42]")
+}
+
+type FakeArchiveService struct {
+	archiverproto.ArchiveServiceClient
+	err error
+}
+
+func (f *FakeArchiveService) GetTickQuorumDataV2(_ context.Context, _ *archiverproto.GetQuorumTickDataRequest, _ ...grpc.CallOption) (*archiverproto.GetQuorumTickDataResponse, error) {
+	return nil, f.err
+}
+
+func TestArchiveClient_GetTickVotes_Error(t *testing.T) {
+	client := &Client{api: &FakeArchiveService{err: status.Error(codes.NotFound, "no quorum data found for tick")}}
+	_, err := client.GetTickVotes(context.Background(), 1000)
+	assert.ErrorIs(t, err, domain.ErrTickNotFound)
+
+	client = &Client{api: &FakeArchiveService{err: status.Error(codes.Unavailable, "connection refused")}}
+	_, err = client.GetTickVotes(context.Background(), 1000)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrTickNotFound)
+}
@@ -2,19 +2,23 @@ package db
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"path/filepath"
 
 	"github.com/cockroachdb/pebble/v2"
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 var ErrNotFound = errors.New("store resource not found")
 
 const epochKey byte = 0x00
 const computorListSumKey byte = 0x01
+const computorListKey byte = 0x02
 
 type PebbleStore struct {
 	db *pebble.DB
@@ -47,7 +51,7 @@ func (ps *PebbleStore) GetLastProcessedEpoch() (uint32, error) {
 
 	value, closer, err := ps.db.Get(key)
 	if errors.Is(err, pebble.ErrNotFound) {
-		log.Printf("[WARN]: No last processed epoch has been found.")
+		zap.S().Warn("No last processed epoch has been found.")
 		return 0, ErrNotFound
 	}
 	if err != nil {
@@ -56,7 +60,7 @@ func (ps *PebbleStore) GetLastProcessedEpoch() (uint32, error) {
 	defer func(closer io.Closer) {
 		err := closer.Close()
 		if err != nil {
-			log.Printf("[ERROR]: Failed to close database get request.")
+			zap.S().Errorw("Closing database get request failed.", logging.Error, err)
 		}
 	}(closer)
 
@@ -95,6 +99,47 @@ func (ps *PebbleStore) GetLastStoredComputorListSum(epoch uint32) ([]byte, error
 
 }
 
+func (ps *PebbleStore) SetLastStoredComputorList(epoch uint32, list *domain.EpochComputors) error {
+
+	key := []byte{computorListKey}
+	key = binary.LittleEndian.AppendUint32(key, epoch)
+
+	value, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("marshalling computor list for epoch [%d]: %w", epoch, err)
+	}
+
+	err = ps.db.Set(key, value, pebble.Sync)
+	if err != nil {
+		return fmt.Errorf("storing last computor list for epoch [%d]: %w", epoch, err)
+	}
+
+	return nil
+}
+
+func (ps *PebbleStore) GetLastStoredComputorList(epoch uint32) (*domain.EpochComputors, error) {
+
+	key := []byte{computorListKey}
+	key = binary.LittleEndian.AppendUint32(key, epoch)
+
+	value, closer, err := ps.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting last stored computor list for epoch [%d]: %w", epoch, err)
+	}
+	defer closer.Close()
+
+	var list domain.EpochComputors
+	err = json.Unmarshal(value, &list)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling computor list for epoch [%d]: %w", epoch, err)
+	}
+	return &list, nil
+
+}
+
 func (ps *PebbleStore) Close() error {
 	return ps.db.Close()
 }
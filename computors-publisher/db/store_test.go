@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/qubic/computors-publisher/domain"
 	"github.com/stretchr/testify/require"
 )
 
@@ -94,3 +95,34 @@ func TestPebbleStore_SetAndGetLastStoredComputorListSum(t *testing.T) {
 	require.Equal(t, sum, retrieved)
 
 }
+
+func TestPebbleStore_SetAndGetLastStoredComputorList(t *testing.T) {
+
+	tempDir, err := os.MkdirTemp("", "processor_store_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	store, err := NewPebbleStore(tempDir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.GetLastStoredComputorList(162)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	list := &domain.EpochComputors{
+		Epoch:      162,
+		TickNumber: 1000,
+		Identities: []string{"A", "B"},
+		Signature:  "signature",
+	}
+	err = store.SetLastStoredComputorList(162, list)
+	require.NoError(t, err)
+
+	err = store.SetLastStoredComputorList(163, &domain.EpochComputors{Epoch: 163})
+	require.NoError(t, err)
+
+	retrieved, err := store.GetLastStoredComputorList(162)
+	require.NoError(t, err)
+	require.Equal(t, list, retrieved)
+
+}
@@ -1,8 +1,68 @@
 package domain
 
+import "errors"
+
+// NumberOfComputors is the number of seats of the computors list.
+const NumberOfComputors = 676
+
+var ErrInvalidSignature = errors.New("invalid computors list signature")
+
 type EpochComputors struct {
 	Epoch      uint32   `json:"epoch"`
 	TickNumber uint32   `json:"tickNumber"`
 	Identities []string `json:"identities"`
 	Signature  string   `json:"signature"` // hex -> base64
 }
+
+// ComputorChange is an identity, that was added to or removed from a seat (index) of the computors list.
+type ComputorChange struct {
+	Index    int    `json:"index"`
+	Identity string `json:"identity"`
+}
+
+// EpochComputorsDiff contains the changed seats compared to the previously published list. For the initial list of an
+// epoch the previous list is the last list of the previous epoch.
+type EpochComputorsDiff struct {
+	Epoch              uint32           `json:"epoch"`
+	TickNumber         uint32           `json:"tickNumber"`
+	PreviousEpoch      uint32           `json:"previousEpoch"`      // 0, if there is no previous list
+	PreviousTickNumber uint32           `json:"previousTickNumber"` // 0, if there is no previous list
+	Added              []ComputorChange `json:"added"`
+	Removed            []ComputorChange `json:"removed"`
+}
+
+// DiffComputors compares the lists seat by seat. If the previous list is nil all identities are added.
+func DiffComputors(previous, current *EpochComputors) *EpochComputorsDiff {
+	diff := &EpochComputorsDiff{
+		Epoch:      current.Epoch,
+		TickNumber: current.TickNumber,
+		Added:      []ComputorChange{},
+		Removed:    []ComputorChange{},
+	}
+	var previousIdentities []string
+	if previous != nil {
+		diff.PreviousEpoch = previous.Epoch
+		diff.PreviousTickNumber = previous.TickNumber
+		previousIdentities = previous.Identities
+	}
+
+	for i := 0; i < max(len(previousIdentities), len(current.Identities)); i++ {
+		var before, after string
+		if i < len(previousIdentities) {
+			before = previousIdentities[i]
+		}
+		if i < len(current.Identities) {
+			after = current.Identities[i]
+		}
+		if before == after {
+			continue
+		}
+		if before != "" {
+			diff.Removed = append(diff.Removed, ComputorChange{Index: i, Identity: before})
+		}
+		if after != "" {
+			diff.Added = append(diff.Added, ComputorChange{Index: i, Identity: after})
+		}
+	}
+	return diff
+}
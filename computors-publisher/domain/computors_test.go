@@ -51,3 +51,76 @@ func TestEpochComputorsMarshalling(t *testing.T) {
 	assert.Equal(t, epochComputors, unmarshalled)
 
 }
+
+func TestDiffComputors(t *testing.T) {
+	previous := &EpochComputors{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B", "C"}}
+
+	tests := []struct {
+		name     string
+		previous *EpochComputors
+		current  *EpochComputors
+		expected *EpochComputorsDiff
+	}{
+		{
+			name:     "initial",
+			previous: nil,
+			current:  &EpochComputors{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B"}},
+			expected: &EpochComputorsDiff{Epoch: 100, TickNumber: 1000,
+				Added:   []ComputorChange{{Index: 0, Identity: "A"}, {Index: 1, Identity: "B"}},
+				Removed: []ComputorChange{},
+			},
+		},
+		{
+			name:     "unchanged",
+			previous: previous,
+			current:  &EpochComputors{Epoch: 100, TickNumber: 2000, Identities: []string{"A", "B", "C"}},
+			expected: &EpochComputorsDiff{Epoch: 100, TickNumber: 2000, PreviousEpoch: 100, PreviousTickNumber: 1000,
+				Added:   []ComputorChange{},
+				Removed: []ComputorChange{},
+			},
+		},
+		{
+			name:     "replaced seat",
+			previous: previous,
+			current:  &EpochComputors{Epoch: 100, TickNumber: 2000, Identities: []string{"A", "D", "C"}},
+			expected: &EpochComputorsDiff{Epoch: 100, TickNumber: 2000, PreviousEpoch: 100, PreviousTickNumber: 1000,
+				Added:   []ComputorChange{{Index: 1, Identity: "D"}},
+				Removed: []ComputorChange{{Index: 1, Identity: "B"}},
+			},
+		},
+		{
+			name:     "moved seat",
+			previous: previous,
+			current:  &EpochComputors{Epoch: 101, TickNumber: 3000, Identities: []string{"B", "A", "C"}},
+			expected: &EpochComputorsDiff{Epoch: 101, TickNumber: 3000, PreviousEpoch: 100, PreviousTickNumber: 1000,
+				Added:   []ComputorChange{{Index: 0, Identity: "B"}, {Index: 1, Identity: "A"}},
+				Removed: []ComputorChange{{Index: 0, Identity: "A"}, {Index: 1, Identity: "B"}},
+			},
+		},
+		{
+			name:     "different length",
+			previous: previous,
+			current:  &EpochComputors{Epoch: 100, TickNumber: 2000, Identities: []string{"A", "B"}},
+			expected: &EpochComputorsDiff{Epoch: 100, TickNumber: 2000, PreviousEpoch: 100, PreviousTickNumber: 1000,
+				Added:   []ComputorChange{},
+				Removed: []ComputorChange{{Index: 2, Identity: "C"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DiffComputors(tt.previous, tt.current))
+		})
+	}
+}
+
+func TestEpochComputorsDiffMarshalling(t *testing.T) {
+	diff := DiffComputors(nil, &EpochComputors{Epoch: 100, TickNumber: 1000, Identities: []string{"A"}})
+
+	expectedJson := `{"epoch":100,"tickNumber":1000,"previousEpoch":0,"previousTickNumber":0,"added":[{"index":0,"identity":"A"}],"removed":[]}`
+
+	marshalled, err := json.Marshal(diff)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedJson, string(marshalled))
+}
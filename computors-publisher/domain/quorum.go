@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// QuorumDiagnostics contains the computors lists of an epoch, for which the archivers did not reach the quorum.
+type QuorumDiagnostics struct {
+	Epoch    uint32            `json:"epoch"`
+	Time     time.Time         `json:"time"`
+	Required int               `json:"required"` // number of archivers, that need to return the same list
+	Lists    []*ArchiverResult `json:"lists"`
+}
+
+// ArchiverResult is the computors list returned by one archiver.
+type ArchiverResult struct {
+	Host     string          `json:"host"`
+	Checksum string          `json:"checksum,omitempty"`
+	List     *EpochComputors `json:"list,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+// Verification results of an epoch.
+const (
+	VerificationOk          = "ok"
+	VerificationMissing     = "missing"     // no list indexed downstream
+	VerificationMismatch    = "mismatch"    // latest list downstream differs from the archiver list
+	VerificationUnavailable = "unavailable" // archiver has no list
+)
+
+// EpochVerification compares the latest computors list of an epoch in the archiver with the latest list downstream.
+type EpochVerification struct {
+	Epoch              uint32 `json:"epoch"`
+	Result             string `json:"result"`
+	ArchiverChecksum   string `json:"archiverChecksum,omitempty"`
+	DownstreamChecksum string `json:"downstreamChecksum,omitempty"`
+	DownstreamTick     uint32 `json:"downstreamTick,omitempty"`
+	Republished        bool   `json:"republished,omitempty"`
+	Error              string `json:"error,omitempty"` // archiver or republishing error
+}
+
+// VerificationReport contains the verification results of all epochs of the archiver.
+type VerificationReport struct {
+	Epochs      []*EpochVerification `json:"epochs"`
+	Missing     []uint32             `json:"missing"`
+	Mismatched  []uint32             `json:"mismatched"`
+	Republished []uint32             `json:"republished"`
+	Failed      []uint32             `json:"failed"` // epochs, that could not be verified or republished
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "errors"
+
+// ErrTickNotFound is returned, if the archiver has no quorum data of the tick.
+var ErrTickNotFound = errors.New("tick not found")
+
+// TickVotes contains the quorum votes of one tick. The salted digests of the votes are not included. They are derived
+// from the previous digests of the next tick and the public key of the voting computor.
+type TickVotes struct {
+	Epoch                         uint32
+	TickNumber                    uint32
+	Timestamp                     uint64 // milliseconds
+	PreviousResourceTestingDigest uint32
+	PreviousTransactionBodyDigest uint32
+	PreviousSpectrumDigest        [32]byte
+	PreviousUniverseDigest        [32]byte
+	PreviousComputerDigest        [32]byte
+	TxDigest                      [32]byte
+	Votes                         map[uint32]*ComputorVote // by computor index
+}
+
+type ComputorVote struct {
+	ExpectedNextTickTxDigest [32]byte
+	Signature                [64]byte
+}
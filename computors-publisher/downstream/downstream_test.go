@@ -0,0 +1,99 @@
+package downstream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileReader_GetLatestComputorList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.ndjson")
+	content := `{"epoch":100,"tickNumber":1500,"identities":["A","C"],"signature":"sig-2"}
+{"epoch":100,"tickNumber":1000,"identities":["A","B"],"signature":"sig-1"}
+
+{"epoch":101,"tickNumber":2000,"identities":["A","D"],"signature":"sig-3"}
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	reader, err := NewFileReader(path)
+	require.NoError(t, err)
+
+	list, err := reader.GetLatestComputorList(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.EpochComputors{Epoch: 100, TickNumber: 1500, Identities: []string{"A", "C"}, Signature: "sig-2"}, list)
+
+	list, err = reader.GetLatestComputorList(context.Background(), 102)
+	require.NoError(t, err)
+	assert.Nil(t, list)
+}
+
+func TestFileReader_GivenInvalidLine_ThenError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("{\"epoch\":100}\nfoo\n"), 0644))
+
+	_, err := NewFileReader(path)
+	require.ErrorContains(t, err, "line [2]")
+}
+
+func TestElasticReader_GetLatestComputorList(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/qubic-computors-alias/_search", r.URL.Path)
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "secret", password)
+		body, _ := io.ReadAll(r.Body)
+		query = string(body)
+		if query == `{"size":1,"query":{"term":{"epoch":100}},"sort":[{"tickNumber":{"order":"desc"}}]}` {
+			_, _ = w.Write([]byte(`{"hits":{"hits":[{"_source":{"epoch":100,"tickNumber":1500,"identities":["A"],"signature":"sig"}}]}}`))
+		} else {
+			_, _ = w.Write([]byte(`{"hits":{"hits":[]}}`))
+		}
+	}))
+	defer server.Close()
+
+	reader, err := NewElasticReader(ElasticConfig{
+		Address:  server.URL,
+		Index:    "qubic-computors-alias",
+		Username: "user",
+		Password: "secret",
+		Query:    DefaultElasticQuery,
+	})
+	require.NoError(t, err)
+
+	list, err := reader.GetLatestComputorList(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.EpochComputors{Epoch: 100, TickNumber: 1500, Identities: []string{"A"}, Signature: "sig"}, list)
+
+	list, err = reader.GetLatestComputorList(context.Background(), 101)
+	require.NoError(t, err)
+	assert.Nil(t, list)
+	assert.Contains(t, query, `"epoch":101`)
+}
+
+func TestElasticReader_GivenErrorResponse_ThenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"index_not_found_exception"}`))
+	}))
+	defer server.Close()
+
+	reader, err := NewElasticReader(ElasticConfig{Address: server.URL, Index: "foo", Query: DefaultElasticQuery})
+	require.NoError(t, err)
+
+	_, err = reader.GetLatestComputorList(context.Background(), 100)
+	require.ErrorContains(t, err, "index_not_found_exception")
+}
+
+func TestNewElasticReader_GivenQueryWithoutEpoch_ThenError(t *testing.T) {
+	_, err := NewElasticReader(ElasticConfig{Address: "http://localhost:9200", Index: "foo", Query: `{"size":1}`})
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+// Package downstream reads the computor lists, that were indexed downstream, for verification.
+package downstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qubic/computors-publisher/domain"
+)
+
+// DefaultElasticQuery finds the latest list of the epoch. %d is replaced with the epoch.
+const DefaultElasticQuery = `{"size":1,"query":{"term":{"epoch":%d}},"sort":[{"tickNumber":{"order":"desc"}}]}`
+
+type ElasticConfig struct {
+	Address     string
+	Index       string
+	Username    string
+	Password    string
+	Certificate []byte // ca certificate (pem). Optional.
+	Query       string // search query. %d is replaced with the epoch.
+}
+
+// ElasticReader reads the latest list of an epoch with a search query.
+type ElasticReader struct {
+	httpClient *http.Client
+	searchUrl  string
+	config     ElasticConfig
+}
+
+func NewElasticReader(config ElasticConfig) (*ElasticReader, error) {
+	if strings.Count(config.Query, "%d") != 1 {
+		return nil, fmt.Errorf("query [%s] needs to contain the epoch placeholder once", config.Query)
+	}
+	searchUrl, err := url.JoinPath(config.Address, config.Index, "_search")
+	if err != nil {
+		return nil, fmt.Errorf("creating search url: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(config.Certificate) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.Certificate) {
+			return nil, fmt.Errorf("invalid elastic certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &ElasticReader{
+		httpClient: &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		searchUrl:  searchUrl,
+		config:     config,
+	}, nil
+}
+
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source domain.EpochComputors `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func (r *ElasticReader) GetLatestComputorList(ctx context.Context, epoch uint32) (*domain.EpochComputors, error) {
+	query := fmt.Sprintf(r.config.Query, epoch)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.searchUrl, bytes.NewBufferString(query))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if r.config.Username != "" {
+		request.SetBasicAuth(r.config.Username, r.config.Password)
+	}
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("performing search: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return nil, fmt.Errorf("got error response from elastic [%d]: %s", response.StatusCode, string(body))
+	}
+
+	var result searchResponse
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if len(result.Hits.Hits) == 0 {
+		return nil, nil
+	}
+	list := result.Hits.Hits[0].Source
+	if list.Epoch != epoch {
+		return nil, fmt.Errorf("query returned list of epoch [%d]", list.Epoch)
+	}
+	return &list, nil
+}
@@ -0,0 +1,53 @@
+package downstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/qubic/computors-publisher/domain"
+)
+
+// FileReader reads the computor lists from an export file with one json list per line (for example exported from the
+// topic or the index). The list with the highest tick number of an epoch is the latest list.
+type FileReader struct {
+	latestLists map[uint32]*domain.EpochComputors
+}
+
+func NewFileReader(path string) (*FileReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening export file: %w", err)
+	}
+	defer file.Close()
+
+	reader := &FileReader{latestLists: make(map[uint32]*domain.EpochComputors)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // lines with 676 identities are larger than the default
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var list domain.EpochComputors
+		err = json.Unmarshal(scanner.Bytes(), &list)
+		if err != nil {
+			return nil, fmt.Errorf("decoding line [%d]: %w", line, err)
+		}
+		latest, ok := reader.latestLists[list.Epoch]
+		if !ok || latest.TickNumber < list.TickNumber {
+			reader.latestLists[list.Epoch] = &list
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading export file: %w", err)
+	}
+	return reader, nil
+}
+
+func (r *FileReader) GetLatestComputorList(_ context.Context, epoch uint32) (*domain.EpochComputors, error) {
+	return r.latestLists[epoch], nil
+}
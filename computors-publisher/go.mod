@@ -4,13 +4,18 @@ go 1.26
 
 require (
 	github.com/ardanlabs/conf/v3 v3.11.0
+	github.com/cloudflare/circl v1.6.3
 	github.com/cockroachdb/pebble/v2 v2.1.4
 	github.com/prometheus/client_golang v1.23.2
+	github.com/qubic/epochs v0.0.0
 	github.com/qubic/go-archiver-v2 v1.1.0
 	github.com/qubic/go-qubic v0.3.5
+	github.com/qubic/logging v0.0.0
+	github.com/qubic/schnorrq v0.0.0
 	github.com/stretchr/testify v1.11.1
 	github.com/twmb/franz-go v1.20.7
 	github.com/twmb/franz-go/plugin/kprom v1.3.0
+	go.uber.org/zap v1.28.0
 	google.golang.org/grpc v1.79.1
 	google.golang.org/protobuf v1.36.11
 )
@@ -21,7 +26,6 @@ require (
 	github.com/RaduBerinde/btreemap v0.0.0-20260105202824-d3184786f603 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
-	github.com/cloudflare/circl v1.6.3 // indirect
 	github.com/cloudflare/fourq v0.0.0-20241014204117-d1fc726fa289 // indirect
 	github.com/cockroachdb/crlib v0.0.0-20251122031428-fe658a2dbda1 // indirect
 	github.com/cockroachdb/errors v1.12.0 // indirect
@@ -47,6 +51,7 @@ require (
 	github.com/prometheus/procfs v0.20.1 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	github.com/twmb/franz-go/pkg/kmsg v1.12.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
 	go.yaml.in/yaml/v2 v2.4.3 // indirect
 	golang.org/x/crypto v0.48.0 // indirect
 	golang.org/x/exp v0.0.0-20260218203240-3dfff04db8fa // indirect
@@ -57,3 +62,9 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260226221140-a57be14db171 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	github.com/qubic/epochs => ../epochs
+	github.com/qubic/logging => ../logging
+	github.com/qubic/schnorrq => ../schnorrq
+)
@@ -11,15 +11,17 @@ import (
 )
 
 type EpochComputorsProducer struct {
-	kcl *kgo.Client
+	kcl       *kgo.Client
+	diffTopic string
 }
 
-func NewEpochComputorsProducer(client *kgo.Client) *EpochComputorsProducer {
-	return &EpochComputorsProducer{kcl: client}
+// NewEpochComputorsProducer sends the lists to the default topic of the client and the diffs to the diff topic.
+func NewEpochComputorsProducer(client *kgo.Client, diffTopic string) *EpochComputorsProducer {
+	return &EpochComputorsProducer{kcl: client, diffTopic: diffTopic}
 }
 
 func (ecp *EpochComputorsProducer) SendMessage(ctx context.Context, computorList *domain.EpochComputors) error {
-	record, err := createRecord(computorList)
+	record, err := createRecord(computorList.Epoch, computorList)
 	if err != nil {
 		return fmt.Errorf("creating epoch computor list record: %w", err)
 	}
@@ -32,15 +34,30 @@ func (ecp *EpochComputorsProducer) SendMessage(ctx context.Context, computorList
 	return nil
 }
 
-func createRecord(computorList *domain.EpochComputors) (*kgo.Record, error) {
-	payload, err := json.Marshal(computorList)
+func (ecp *EpochComputorsProducer) SendDiff(ctx context.Context, diff *domain.EpochComputorsDiff) error {
+	record, err := createRecord(diff.Epoch, diff)
+	if err != nil {
+		return fmt.Errorf("creating epoch computor list diff record: %w", err)
+	}
+	record.Topic = ecp.diffTopic
+
+	err = ecp.kcl.ProduceSync(ctx, record).FirstErr()
+	if err != nil {
+		return fmt.Errorf("producing epoch computor list diff record: %w", err)
+	}
+
+	return nil
+}
+
+func createRecord(epoch uint32, value any) (*kgo.Record, error) {
+	payload, err := json.Marshal(value)
 	if err != nil {
 		return nil, fmt.Errorf("marshalling to json: %w", err)
 	}
 
 	// set epoch as key to make sure all lists for this epoch go to the same partition
 	key := make([]byte, 4)
-	binary.LittleEndian.PutUint32(key, computorList.Epoch)
+	binary.LittleEndian.PutUint32(key, epoch)
 
 	return &kgo.Record{
 		Key:   key,
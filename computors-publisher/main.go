@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/ardanlabs/conf/v3"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,38 +17,72 @@ import (
 	"github.com/qubic/computors-publisher/api"
 	"github.com/qubic/computors-publisher/archiver"
 	"github.com/qubic/computors-publisher/db"
+	"github.com/qubic/computors-publisher/downstream"
 	"github.com/qubic/computors-publisher/kafka"
 	"github.com/qubic/computors-publisher/metrics"
+	"github.com/qubic/computors-publisher/qubic"
 	"github.com/qubic/computors-publisher/sync"
+	"github.com/qubic/logging"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_COMPUTORS_PUBLISHER"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("computors-publisher", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-	log.SetOutput(os.Stdout)
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Client struct {
 			ArchiverGrpcHost string `conf:"default:localhost:8010"`
 		}
+		Quorum struct {
+			ArchiverGrpcHosts []string `conf:"optional"`            // additional archivers. Enables comparing the computors lists.
+			Required          int      `conf:"optional"`            // archivers, that need to return the same list. Defaults to the majority.
+			DiagnosticsFolder string   `conf:"default:diagnostics"` // conflicting lists are written to this folder
+		}
+		Qubic struct {
+			ArbitratorIdentity string `conf:"default:AFZPUAIYVPNUYGJRQVLUKOPPVLHAZQTGLYAAUUNBXFTVTAMSBKQBLEIEPCVJ"` // signs the computors lists
+		}
 		Broker struct {
 			BootstrapServers []string `conf:"default:localhost:9092"`
 			ProduceTopic     string   `conf:"default:qubic-computors"`
+			DiffTopic        string   `conf:"default:qubic-computors-diff"`
 		}
 		Sync struct {
-			InternalStoreFolder string `conf:"default:store"`
-			ServerPort          int    `conf:"default:8000"`
-			MetricsPort         int    `conf:"default:9999"`
-			MetricsNamespace    string `conf:"default:qubic_kafka"`
-			StartEpoch          uint32 `conf:"optional"`
+			InternalStoreFolder string        `conf:"default:store"`
+			ServerPort          int           `conf:"default:8000"`
+			MetricsPort         int           `conf:"default:9999"`
+			MetricsNamespace    string        `conf:"default:qubic_kafka"`
+			StartEpoch          uint32        `conf:"optional"`
+			MinCheckInterval    time.Duration `conf:"default:5s"`  // around epoch transitions and after new lists
+			MaxCheckInterval    time.Duration `conf:"default:5m"`  // mid-epoch
+			TransitionWindow    time.Duration `conf:"default:30m"` // fast checks after an epoch change or new list and before the expected epoch start
+		}
+		VerifyAndBackfill struct {
+			Enabled            bool   `conf:"default:false"` // verify all epochs, write the report and exit
+			Republish          bool   `conf:"default:false"` // publish missing and mismatched lists again
+			ExportFile         string `conf:"optional"`      // read the downstream lists from this file instead of elastic
+			ReportFile         string `conf:"optional"`      // write the report to this file instead of stdout
+			ElasticAddress     string `conf:"default:https://localhost:9200"`
+			ElasticIndex       string `conf:"default:qubic-computors-alias"`
+			ElasticUsername    string `conf:"optional"`
+			ElasticPassword    string `conf:"optional,mask"`
+			ElasticCertificate string `conf:"default:http_ca.crt"`
+			ElasticQuery       string `conf:"optional"` // defaults to the query for the latest list. %d is the epoch.
+		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
 		}
 	}
 
@@ -63,7 +99,11 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("generating config for output: %w", err)
 	}
-	log.Printf("main: Config :\n%v\n", out)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
+	if err != nil {
+		return fmt.Errorf("setting log level: %w", err)
+	}
 
 	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
 		kprom.Registerer(prometheus.DefaultRegisterer),
@@ -73,10 +113,10 @@ func run() error {
 		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
 		kgo.DefaultProduceTopic(cfg.Broker.ProduceTopic),
 		kgo.ProducerBatchCompression(kgo.ZstdCompression()),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
+		kgo.WithLogger(logging.NewKafkaLogger()),
 	)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("creating kafka client: %w", err)
 	}
 	defer kcl.Close()
 
@@ -91,14 +131,14 @@ func run() error {
 			return fmt.Errorf("getting last processed epoch: %w", err)
 		}
 
-		log.Println("[INFO]: Initializing database with last processed epoch as 0.")
+		zap.S().Info("Initializing database with last processed epoch as 0.")
 		err := store.SetLastProcessedEpoch(0)
 		if err != nil {
 			return fmt.Errorf("initializing database value: %w", err)
 		}
 	}
 	if cfg.Sync.StartEpoch > 0 {
-		log.Printf("[INFO]: Overriding last processed epoch to %d", cfg.Sync.StartEpoch)
+		zap.S().Infow("Overriding last processed epoch.", logging.Epoch, cfg.Sync.StartEpoch)
 		err = store.SetLastProcessedEpoch(cfg.Sync.StartEpoch)
 		if err != nil {
 			return fmt.Errorf("setting last processed epoch: %w", err)
@@ -111,50 +151,143 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("creating archiver client: %w", err)
 	}
-	kafkaProducer := kafka.NewEpochComputorsProducer(kcl)
+	var client sync.ArchiveClient = archiverClient
+	if len(cfg.Quorum.ArchiverGrpcHosts) > 0 {
+		client, err = createQuorumClient(archiverClient, cfg.Client.ArchiverGrpcHost, cfg.Quorum.ArchiverGrpcHosts,
+			cfg.Quorum.Required, cfg.Quorum.DiagnosticsFolder, procMetrics)
+		if err != nil {
+			return fmt.Errorf("creating quorum client: %w", err)
+		}
+	}
+	kafkaProducer := kafka.NewEpochComputorsProducer(kcl, cfg.Broker.DiffTopic)
+
+	verifier, err := qubic.NewVerifier(cfg.Qubic.ArbitratorIdentity)
+	if err != nil {
+		return fmt.Errorf("creating verifier: %w", err)
+	}
+
+	processor := sync.NewEpochComputorsProcessor(client, store, kafkaProducer, verifier, procMetrics)
+
+	if cfg.VerifyAndBackfill.Enabled {
+		var reader sync.DownstreamReader
+		if cfg.VerifyAndBackfill.ExportFile != "" {
+			reader, err = downstream.NewFileReader(cfg.VerifyAndBackfill.ExportFile)
+		} else {
+			cert, certErr := os.ReadFile(cfg.VerifyAndBackfill.ElasticCertificate)
+			if certErr != nil {
+				zap.S().Warnw("Could not read elastic certificate.", logging.Error, certErr)
+			}
+			query := cfg.VerifyAndBackfill.ElasticQuery
+			if query == "" {
+				query = downstream.DefaultElasticQuery
+			}
+			reader, err = downstream.NewElasticReader(downstream.ElasticConfig{
+				Address:     cfg.VerifyAndBackfill.ElasticAddress,
+				Index:       cfg.VerifyAndBackfill.ElasticIndex,
+				Username:    cfg.VerifyAndBackfill.ElasticUsername,
+				Password:    cfg.VerifyAndBackfill.ElasticPassword,
+				Certificate: cert,
+				Query:       query,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("creating downstream reader: %w", err)
+		}
+		return verifyAndBackfill(processor, reader, cfg.VerifyAndBackfill.Republish, cfg.VerifyAndBackfill.ReportFile)
+	}
 
-	processor := sync.NewEpochComputorsProcessor(archiverClient, store, kafkaProducer, procMetrics)
 	procErr := make(chan error, 1)
-	go func() { procErr <- processor.StartProcessing() }()
+	go func() {
+		procErr <- processor.StartProcessing(sync.ScheduleConfig{
+			MinInterval:      cfg.Sync.MinCheckInterval,
+			MaxInterval:      cfg.Sync.MaxCheckInterval,
+			TransitionWindow: cfg.Sync.TransitionWindow,
+		})
+	}()
 
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
 	// status and metrics endpoint
+	server := api.NewHandler(processor)
 	apiError := make(chan error, 1)
 	go func() {
 		mux := http.NewServeMux()
-		server := api.NewHandler()
 		mux.HandleFunc("/health", server.GetHealth)
-		log.Printf("main: Starting server on port [%d].", cfg.Sync.ServerPort)
+		zap.S().Infow("Starting server.", "port", cfg.Sync.ServerPort)
 		apiError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.ServerPort), mux)
 	}()
 
+	// admin endpoints are not exposed on the public server port
 	metricsError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting metrics server on port [%d].", cfg.Sync.MetricsPort)
+		zap.S().Infow("Starting metrics, log level and check server.", "port", cfg.Sync.MetricsPort)
 		http.Handle("/metrics", promhttp.Handler())
+		http.HandleFunc("POST /check", server.PostCheck)
+		logging.Handle(http.DefaultServeMux, logLevel)
 		metricsError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-procErr:
 			if err != nil {
-				return fmt.Errorf("[ERROR] processing: %v", err)
+				return fmt.Errorf("processing: %w", err)
 			} else {
-				log.Printf("main: Finished processing.")
+				zap.S().Info("Finished processing.")
 				return nil
 			}
 		case err := <-metricsError:
-			return fmt.Errorf("[ERROR] starting metrics server: %v", err)
+			return fmt.Errorf("starting metrics server: %w", err)
 		case err := <-apiError:
-			return fmt.Errorf("[ERROR] starting api server: %v", err)
+			return fmt.Errorf("starting api server: %w", err)
+		}
+	}
+}
+
+// createQuorumClient creates a client, that compares the computors lists of the main and the additional archivers.
+func createQuorumClient(mainClient *archiver.Client, mainHost string, hosts []string, required int, diagnosticsFolder string, procMetrics *metrics.ProcessingMetrics) (*sync.QuorumClient, error) {
+	archivers := []sync.QuorumArchiver{{Host: mainHost, Client: mainClient}}
+	for _, host := range hosts {
+		client, err := archiver.NewClient(host)
+		if err != nil {
+			return nil, fmt.Errorf("creating archiver client for [%s]: %w", host, err)
 		}
+		archivers = append(archivers, sync.QuorumArchiver{Host: host, Client: client})
+	}
+	if required == 0 {
+		required = len(archivers)/2 + 1
+	}
+	zap.S().Infow("Comparing computors lists of archivers.", "archivers", len(archivers), "required", required)
+	return sync.NewQuorumClient(archivers, required, diagnosticsFolder, procMetrics)
+}
+
+// verifyAndBackfill verifies the lists of all epochs and writes the report.
+func verifyAndBackfill(processor *sync.EpochComputorsProcessor, reader sync.DownstreamReader, republish bool, reportFile string) error {
+	zap.S().Infow("Verifying computors lists of all epochs.", "republish", republish)
+	report, err := processor.VerifyAndBackfill(reader, republish)
+	if err != nil {
+		return fmt.Errorf("verifying computors lists: %w", err)
+	}
+	zap.S().Infow("Verified computors lists.", "epochs", len(report.Epochs), "missing", report.Missing,
+		"mismatched", report.Mismatched, "republished", report.Republished, "failed", report.Failed)
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling report: %w", err)
+	}
+	if reportFile == "" {
+		fmt.Println(string(content))
+		return nil
+	}
+	err = os.WriteFile(reportFile, content, 0644)
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
 	}
+	return nil
 }
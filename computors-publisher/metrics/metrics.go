@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -13,6 +14,11 @@ type ProcessingMetrics struct {
 	processedTickGauge    prometheus.Gauge
 	processingEpochGauge  prometheus.Gauge
 	processedMessageCount prometheus.Counter
+	invalidListCount      prometheus.Counter
+	checkIntervalGauge    prometheus.Gauge
+	nextCheckGauge        prometheus.Gauge
+	forcedCheckCount      prometheus.Counter
+	disagreementCount     prometheus.Counter
 }
 
 func NewProcessingMetrics(namespace string) *ProcessingMetrics {
@@ -30,6 +36,27 @@ func NewProcessingMetrics(namespace string) *ProcessingMetrics {
 			Name: fmt.Sprintf("%s_processed_message_count", namespace),
 			Help: "The total number of processed message records",
 		}),
+		invalidListCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_invalid_computor_list_count", namespace),
+			Help: "The total number of refused computor lists with invalid arbitrator signature",
+		}),
+		disagreementCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_computor_list_disagreement_count", namespace),
+			Help: "The total number of checks, in which the archivers did not agree on the computor list",
+		}),
+		// metrics for the check schedule
+		checkIntervalGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_check_interval_seconds", namespace),
+			Help: "The current interval between checks for new computor lists",
+		}),
+		nextCheckGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_next_check_timestamp_seconds", namespace),
+			Help: "The time of the next scheduled check for new computor lists",
+		}),
+		forcedCheckCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_forced_check_count", namespace),
+			Help: "The total number of forced checks for new computor lists",
+		}),
 		// metrics for comparison to event source
 		sourceTickGauge: promauto.NewGauge(prometheus.GaugeOpts{
 			Name: fmt.Sprintf("%s_source_tick", namespace),
@@ -56,3 +83,20 @@ func (m *ProcessingMetrics) SetProcessedTick(epoch uint32, tick uint32) {
 func (m *ProcessingMetrics) IncProcessedMessages() {
 	m.processedMessageCount.Inc()
 }
+
+func (m *ProcessingMetrics) SetSchedule(interval time.Duration, nextCheck time.Time) {
+	m.checkIntervalGauge.Set(interval.Seconds())
+	m.nextCheckGauge.Set(float64(nextCheck.Unix()))
+}
+
+func (m *ProcessingMetrics) IncForcedChecks() {
+	m.forcedCheckCount.Inc()
+}
+
+func (m *ProcessingMetrics) IncInvalidComputorLists() {
+	m.invalidListCount.Inc()
+}
+
+func (m *ProcessingMetrics) IncComputorListDisagreements() {
+	m.disagreementCount.Inc()
+}
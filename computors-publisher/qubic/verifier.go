@@ -0,0 +1,82 @@
+package qubic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/go-qubic/common"
+	"github.com/qubic/schnorrq"
+)
+
+// Verifier verifies, that computors lists are signed by the arbitrator.
+type Verifier struct {
+	arbitratorPublicKey [32]byte
+}
+
+func NewVerifier(arbitratorIdentity string) (*Verifier, error) {
+	publicKey, err := toPublicKey(arbitratorIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("decoding arbitrator identity [%s]: %w", arbitratorIdentity, err)
+	}
+	return &Verifier{arbitratorPublicKey: publicKey}, nil
+}
+
+// Verify returns domain.ErrInvalidSignature, if the signature cannot be verified with the arbitrator identity.
+func (v *Verifier) Verify(list *domain.EpochComputors) error {
+	unsignedData, err := marshalBinary(list)
+	if err != nil {
+		return fmt.Errorf("serializing computors list: %w", err)
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(list.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	if len(signatureBytes) != 64 {
+		return fmt.Errorf("%w: invalid signature length [%d]", domain.ErrInvalidSignature, len(signatureBytes))
+	}
+
+	// the signature signs the digest of the list without signature. The tick number is not part of the list.
+	digest, err := common.K12Hash(unsignedData)
+	if err != nil {
+		return fmt.Errorf("hashing computors list: %w", err)
+	}
+	err = schnorrq.Verify(v.arbitratorPublicKey, digest, [64]byte(signatureBytes))
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// marshalBinary serializes the computors list without signature like the qubic network does (epoch and the public
+// keys of all seats).
+func marshalBinary(list *domain.EpochComputors) ([]byte, error) {
+	if list.Epoch > 0xFFFF {
+		return nil, fmt.Errorf("invalid epoch [%d]", list.Epoch)
+	}
+	if len(list.Identities) > domain.NumberOfComputors {
+		return nil, fmt.Errorf("invalid number of identities [%d]", len(list.Identities))
+	}
+
+	var buff bytes.Buffer
+	_ = binary.Write(&buff, binary.LittleEndian, uint16(list.Epoch))
+	for i := range domain.NumberOfComputors {
+		var publicKey [32]byte // empty seats are zero
+		if i < len(list.Identities) && list.Identities[i] != "" {
+			var err error
+			publicKey, err = toPublicKey(list.Identities[i])
+			if err != nil {
+				return nil, fmt.Errorf("decoding identity [%s]: %w", list.Identities[i], err)
+			}
+		}
+		buff.Write(publicKey[:])
+	}
+	return buff.Bytes(), nil
+}
+
+func toPublicKey(identity string) ([32]byte, error) {
+	id := common.Identity(identity)
+	return id.ToPubKey(false)
+}
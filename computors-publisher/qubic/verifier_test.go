@@ -0,0 +1,98 @@
+package qubic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const arbitrator = "AFZPUAIYVPNUYGJRQVLUKOPPVLHAZQTGLYAAUUNBXFTVTAMSBKQBLEIEPCVJ"
+
+// computors list from the qubic network
+func loadComputors(t *testing.T, epoch uint32) *domain.EpochComputors {
+	data, err := os.ReadFile("testdata/computors-epoch-" + strconv.Itoa(int(epoch)) + ".json")
+	require.NoError(t, err)
+	var list domain.EpochComputors
+	require.NoError(t, json.Unmarshal(data, &list))
+	return &list
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	verifier, err := NewVerifier(arbitrator)
+	require.NoError(t, err)
+
+	list := loadComputors(t, 168)
+	err = verifier.Verify(list)
+	require.NoError(t, err)
+}
+
+func TestVerifier_Verify_InvalidSignature(t *testing.T) {
+	verifier, err := NewVerifier(arbitrator)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(list *domain.EpochComputors)
+	}{
+		{
+			name: "other epoch",
+			modify: func(list *domain.EpochComputors) {
+				list.Epoch = 169
+			},
+		},
+		{
+			name: "swapped seats",
+			modify: func(list *domain.EpochComputors) {
+				list.Identities[0], list.Identities[1] = list.Identities[1], list.Identities[0]
+			},
+		},
+		{
+			name: "missing seat",
+			modify: func(list *domain.EpochComputors) {
+				list.Identities = list.Identities[:len(list.Identities)-1]
+			},
+		},
+		{
+			name: "modified signature",
+			modify: func(list *domain.EpochComputors) {
+				signature, _ := base64.StdEncoding.DecodeString(list.Signature)
+				signature[40] ^= 0x01
+				list.Signature = base64.StdEncoding.EncodeToString(signature)
+			},
+		},
+		{
+			name: "short signature",
+			modify: func(list *domain.EpochComputors) {
+				list.Signature = base64.StdEncoding.EncodeToString([]byte("too short"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := loadComputors(t, 168)
+			tt.modify(list)
+			err := verifier.Verify(list)
+			require.ErrorIs(t, err, domain.ErrInvalidSignature)
+		})
+	}
+}
+
+func TestVerifier_Verify_OtherArbitrator(t *testing.T) {
+	verifier, err := NewVerifier("BZBQFLLBNCXEMGLOBHUVFTLUPLVCPQUASSILFABOFFBCADQSSUPNWLZBQEXK")
+	require.NoError(t, err)
+
+	err = verifier.Verify(loadComputors(t, 168))
+	require.ErrorIs(t, err, domain.ErrInvalidSignature)
+}
+
+func TestNewVerifier_InvalidIdentity(t *testing.T) {
+	_, err := NewVerifier("invalid")
+	assert.Error(t, err)
+}
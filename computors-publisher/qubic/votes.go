@@ -0,0 +1,139 @@
+package qubic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/go-qubic/common"
+	"github.com/qubic/schnorrq"
+)
+
+// minimumVoteFormatEpoch is the first epoch with the current vote format (4 byte resource testing and transaction
+// body digests).
+const minimumVoteFormatEpoch = 151
+
+var ErrUnsupportedVoteFormat = errors.New("unsupported vote format")
+
+// tickVote is the signed part of a quorum tick vote like the qubic network serializes it.
+type tickVote struct {
+	ComputorIndex                 uint16
+	Epoch                         uint16
+	Tick                          uint32
+	Millisecond                   uint16
+	Second                        uint8
+	Minute                        uint8
+	Hour                          uint8
+	Day                           uint8
+	Month                         uint8
+	Year                          uint8
+	PreviousResourceTestingDigest uint32
+	SaltedResourceTestingDigest   uint32
+	PreviousTransactionBodyDigest uint32
+	SaltedTransactionBodyDigest   uint32
+	PreviousSpectrumDigest        [32]byte
+	PreviousUniverseDigest        [32]byte
+	PreviousComputerDigest        [32]byte
+	SaltedSpectrumDigest          [32]byte
+	SaltedUniverseDigest          [32]byte
+	SaltedComputerDigest          [32]byte
+	TxDigest                      [32]byte
+	ExpectedNextTickTxDigest      [32]byte
+}
+
+// VerifyVote verifies the vote of the computor with the given identity for the tick. The previous digests of the next
+// tick are the digests, that the computors voted for. Returns domain.ErrInvalidSignature, if the vote was not signed
+// by the identity.
+func (v *Verifier) VerifyVote(tick, next *domain.TickVotes, computorIndex uint32, identity string) error {
+	vote, ok := tick.Votes[computorIndex]
+	if !ok {
+		return fmt.Errorf("no vote of computor [%d] in tick [%d]", computorIndex, tick.TickNumber)
+	}
+	if next.TickNumber != tick.TickNumber+1 {
+		return fmt.Errorf("tick [%d] does not follow tick [%d]", next.TickNumber, tick.TickNumber)
+	}
+	publicKey, err := toPublicKey(identity)
+	if err != nil {
+		return fmt.Errorf("decoding identity [%s]: %w", identity, err)
+	}
+
+	digest, err := voteDigest(tick, next, vote, uint16(computorIndex), publicKey)
+	if err != nil {
+		return fmt.Errorf("creating vote digest: %w", err)
+	}
+	err = schnorrq.Verify(publicKey, digest, vote.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+func voteDigest(tick, next *domain.TickVotes, vote *domain.ComputorVote, computorIndex uint16, publicKey [32]byte) ([32]byte, error) {
+	if tick.Epoch < minimumVoteFormatEpoch || tick.Epoch > 0xFFFF {
+		return [32]byte{}, fmt.Errorf("%w in epoch [%d]", ErrUnsupportedVoteFormat, tick.Epoch)
+	}
+
+	timestamp := time.UnixMilli(int64(tick.Timestamp)).UTC()
+	unsigned := tickVote{
+		ComputorIndex:                 computorIndex ^ 3, // packet type. Prevents reusing the signature for other messages.
+		Epoch:                         uint16(tick.Epoch),
+		Tick:                          tick.TickNumber,
+		Millisecond:                   uint16(tick.Timestamp % 1000),
+		Second:                        uint8(timestamp.Second()),
+		Minute:                        uint8(timestamp.Minute()),
+		Hour:                          uint8(timestamp.Hour()),
+		Day:                           uint8(timestamp.Day()),
+		Month:                         uint8(timestamp.Month()),
+		Year:                          uint8(timestamp.Year() - 2000),
+		PreviousResourceTestingDigest: tick.PreviousResourceTestingDigest,
+		PreviousTransactionBodyDigest: tick.PreviousTransactionBodyDigest,
+		PreviousSpectrumDigest:        tick.PreviousSpectrumDigest,
+		PreviousUniverseDigest:        tick.PreviousUniverseDigest,
+		PreviousComputerDigest:        tick.PreviousComputerDigest,
+		TxDigest:                      tick.TxDigest,
+		ExpectedNextTickTxDigest:      vote.ExpectedNextTickTxDigest,
+	}
+
+	var err error
+	if unsigned.SaltedResourceTestingDigest, err = saltedShortDigest(publicKey, next.PreviousResourceTestingDigest); err != nil {
+		return [32]byte{}, err
+	}
+	if unsigned.SaltedTransactionBodyDigest, err = saltedShortDigest(publicKey, next.PreviousTransactionBodyDigest); err != nil {
+		return [32]byte{}, err
+	}
+	if unsigned.SaltedSpectrumDigest, err = saltedDigest(publicKey, next.PreviousSpectrumDigest); err != nil {
+		return [32]byte{}, err
+	}
+	if unsigned.SaltedUniverseDigest, err = saltedDigest(publicKey, next.PreviousUniverseDigest); err != nil {
+		return [32]byte{}, err
+	}
+	if unsigned.SaltedComputerDigest, err = saltedDigest(publicKey, next.PreviousComputerDigest); err != nil {
+		return [32]byte{}, err
+	}
+
+	var buff bytes.Buffer
+	err = binary.Write(&buff, binary.LittleEndian, unsigned)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("serializing vote: %w", err)
+	}
+	return common.K12Hash(buff.Bytes())
+}
+
+func saltedDigest(publicKey, digest [32]byte) ([32]byte, error) {
+	salted, err := common.K12Hash(append(publicKey[:], digest[:]...))
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("hashing salted digest: %w", err)
+	}
+	return salted, nil
+}
+
+func saltedShortDigest(publicKey [32]byte, digest uint32) (uint32, error) {
+	salted, err := common.K12Hash(binary.LittleEndian.AppendUint32(publicKey[:], digest))
+	if err != nil {
+		return 0, fmt.Errorf("hashing salted digest: %w", err)
+	}
+	return binary.LittleEndian.Uint32(salted[:4]), nil
+}
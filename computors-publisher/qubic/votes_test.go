@@ -0,0 +1,184 @@
+package qubic
+
+import (
+	"encoding/binary"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/cloudflare/circl/ecc/fourq"
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/go-qubic/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTickVotes(epoch, tickNumber uint32) *domain.TickVotes {
+	return &domain.TickVotes{
+		Epoch:                  epoch,
+		TickNumber:             tickNumber,
+		Timestamp:              1729080000123, // 2024-10-16 12:00:00.123
+		PreviousSpectrumDigest: [32]byte{1},
+		Votes:                  map[uint32]*domain.ComputorVote{0: {Signature: [64]byte{1, 2, 3}}},
+	}
+}
+
+// testSigner signs like a computor. The keys are derived from the seed, the nonce from the key and the digest.
+type testSigner struct {
+	privateKey *big.Int
+	publicKey  [32]byte
+}
+
+func newTestSigner(t *testing.T, seed string) *testSigner {
+	hash, err := common.K12Hash([]byte(seed))
+	require.NoError(t, err)
+	signer := testSigner{privateKey: new(big.Int).Mod(fromLittleEndian(hash[:]), fourq.Params().N)}
+	var a fourq.Point
+	privateKey := toLittleEndianScalar(signer.privateKey)
+	a.ScalarBaseMult(&privateKey)
+	a.Marshal(&signer.publicKey)
+	return &signer
+}
+
+func (s *testSigner) identity(t *testing.T) string {
+	var id common.Identity
+	require.NoError(t, id.FromPubKey(s.publicKey, false))
+	return id.String()
+}
+
+// sign returns the signature (R || s) with R = r*G, h = K12(R || A || digest) and s = r - h*a.
+func (s *testSigner) sign(t *testing.T, digest [32]byte) [64]byte {
+	n := fourq.Params().N
+	privateKey := toLittleEndianScalar(s.privateKey)
+	nonce, err := common.K12Hash(slices.Concat(privateKey[:], digest[:]))
+	require.NoError(t, err)
+	r := new(big.Int).Mod(fromLittleEndian(nonce[:]), n)
+
+	var rPoint fourq.Point
+	rScalar := toLittleEndianScalar(r)
+	rPoint.ScalarBaseMult(&rScalar)
+	var signature [64]byte
+	encoded := (*[32]byte)(signature[:32])
+	rPoint.Marshal(encoded)
+
+	h, err := common.K12Hash(slices.Concat(signature[:32], s.publicKey[:], digest[:]))
+	require.NoError(t, err)
+	sValue := new(big.Int).Sub(r, new(big.Int).Mul(fromLittleEndian(h[:]), s.privateKey))
+	sScalar := toLittleEndianScalar(sValue.Mod(sValue, n))
+	copy(signature[32:], sScalar[:])
+	return signature
+}
+
+func fromLittleEndian(data []byte) *big.Int {
+	bigEndian := slices.Clone(data)
+	slices.Reverse(bigEndian)
+	return new(big.Int).SetBytes(bigEndian)
+}
+
+func toLittleEndianScalar(value *big.Int) [32]byte {
+	var scalar [32]byte
+	value.FillBytes(scalar[:])
+	slices.Reverse(scalar[:])
+	return scalar
+}
+
+// signedTickVotes returns a tick of epoch 168 and its next tick with the vote of the computor signed by the signer.
+func signedTickVotes(t *testing.T, signer *testSigner, computorIndex uint32) (*domain.TickVotes, *domain.TickVotes) {
+	tick := &domain.TickVotes{
+		Epoch:                         168,
+		TickNumber:                    27_000_000,
+		Timestamp:                     1751457600123, // 2025-07-02 12:00:00.123
+		PreviousResourceTestingDigest: 0x1a2b3c4d,
+		PreviousTransactionBodyDigest: 0x5e6f7a8b,
+		PreviousSpectrumDigest:        [32]byte{0x11, 0x12, 0x13},
+		PreviousUniverseDigest:        [32]byte{0x21, 0x22, 0x23},
+		PreviousComputerDigest:        [32]byte{0x31, 0x32, 0x33},
+		TxDigest:                      [32]byte{0x41, 0x42, 0x43},
+		Votes: map[uint32]*domain.ComputorVote{
+			computorIndex: {ExpectedNextTickTxDigest: [32]byte{0x51, 0x52, 0x53}},
+		},
+	}
+	next := &domain.TickVotes{
+		Epoch:                         168,
+		TickNumber:                    27_000_001,
+		Timestamp:                     1751457601456,
+		PreviousResourceTestingDigest: 0x9c8d7e6f,
+		PreviousTransactionBodyDigest: 0x5a4b3c2d,
+		PreviousSpectrumDigest:        [32]byte{0x61, 0x62, 0x63},
+		PreviousUniverseDigest:        [32]byte{0x71, 0x72, 0x73},
+		PreviousComputerDigest:        [32]byte{0x81, 0x82, 0x83},
+		TxDigest:                      [32]byte{0x51, 0x52, 0x53},
+		Votes:                         map[uint32]*domain.ComputorVote{},
+	}
+
+	digest, err := voteDigest(tick, next, tick.Votes[computorIndex], uint16(computorIndex), signer.publicKey)
+	require.NoError(t, err)
+	tick.Votes[computorIndex].Signature = signer.sign(t, digest)
+	return tick, next
+}
+
+func TestVerifier_VerifyVote(t *testing.T) {
+	verifier, err := NewVerifier(arbitrator)
+	require.NoError(t, err)
+	signer := newTestSigner(t, "computor")
+	tick, next := signedTickVotes(t, signer, 42)
+
+	require.NoError(t, verifier.VerifyVote(tick, next, 42, signer.identity(t)))
+
+	err = verifier.VerifyVote(tick, next, 42, newTestSigner(t, "other computor").identity(t))
+	assert.ErrorIs(t, err, domain.ErrInvalidSignature, "signed by another computor")
+
+	next.PreviousSpectrumDigest[0]++
+	err = verifier.VerifyVote(tick, next, 42, signer.identity(t))
+	assert.ErrorIs(t, err, domain.ErrInvalidSignature, "voted for other digests")
+}
+
+func TestVerifier_VerifyVote_InvalidSignature(t *testing.T) {
+	verifier, err := NewVerifier(arbitrator)
+	require.NoError(t, err)
+
+	err = verifier.VerifyVote(testTickVotes(168, 1000), testTickVotes(168, 1001), 0, arbitrator)
+	require.ErrorIs(t, err, domain.ErrInvalidSignature)
+}
+
+func TestVerifier_VerifyVote_Error(t *testing.T) {
+	verifier, err := NewVerifier(arbitrator)
+	require.NoError(t, err)
+
+	err = verifier.VerifyVote(testTickVotes(168, 1000), testTickVotes(168, 1001), 1, arbitrator)
+	require.ErrorContains(t, err, "no vote of computor [1]")
+
+	err = verifier.VerifyVote(testTickVotes(168, 1000), testTickVotes(168, 1002), 0, arbitrator)
+	require.ErrorContains(t, err, "does not follow")
+
+	err = verifier.VerifyVote(testTickVotes(150, 1000), testTickVotes(150, 1001), 0, arbitrator)
+	require.ErrorIs(t, err, ErrUnsupportedVoteFormat)
+
+	err = verifier.VerifyVote(testTickVotes(168, 1000), testTickVotes(168, 1001), 0, "invalid")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrInvalidSignature)
+}
+
+func Test_voteDigest(t *testing.T) {
+	assert.Equal(t, 288, binary.Size(tickVote{}), "tick vote of the core without the 64 byte signature")
+
+	publicKey, err := toPublicKey(arbitrator)
+	require.NoError(t, err)
+	tick, next := testTickVotes(168, 1000), testTickVotes(168, 1001)
+
+	digest, err := voteDigest(tick, next, tick.Votes[0], 0, publicKey)
+	require.NoError(t, err)
+
+	// salted with the public key of the computor
+	otherPublicKey := publicKey
+	otherPublicKey[0]++
+	otherDigest, err := voteDigest(tick, next, tick.Votes[0], 0, otherPublicKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, otherDigest)
+
+	// depends on the digests of the next tick
+	next.PreviousSpectrumDigest[0]++
+	otherDigest, err = voteDigest(tick, next, tick.Votes[0], 0, publicKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, otherDigest)
+}
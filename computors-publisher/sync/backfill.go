@@ -0,0 +1,160 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qubic/computors-publisher/db"
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
+)
+
+type DownstreamReader interface {
+	// GetLatestComputorList returns the latest list of the epoch or nil, if there is none.
+	GetLatestComputorList(ctx context.Context, epoch uint32) (*domain.EpochComputors, error)
+}
+
+// VerifyAndBackfill compares the computors list of every epoch of the archiver with the latest list downstream. If
+// republish is set, missing and mismatched lists are published again. Republishing does not publish diffs and does not
+// change the internal store.
+func (p *EpochComputorsProcessor) VerifyAndBackfill(reader DownstreamReader, republish bool) (*domain.VerificationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	status, err := p.archiveClient.GetStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting archive status: %w", err)
+	}
+
+	report := &domain.VerificationReport{
+		Epochs:      make([]*domain.EpochVerification, 0, len(status.EpochList)),
+		Missing:     []uint32{},
+		Mismatched:  []uint32{},
+		Republished: []uint32{},
+		Failed:      []uint32{},
+	}
+	for _, epoch := range status.EpochList {
+		verification, list, err := p.verifyEpoch(reader, epoch)
+		if err != nil {
+			return nil, fmt.Errorf("verifying epoch [%d]: %w", epoch, err)
+		}
+		report.Epochs = append(report.Epochs, verification)
+		switch verification.Result {
+		case domain.VerificationMissing:
+			report.Missing = append(report.Missing, epoch)
+		case domain.VerificationMismatch:
+			report.Mismatched = append(report.Mismatched, epoch)
+		case domain.VerificationUnavailable:
+			report.Failed = append(report.Failed, epoch)
+		}
+		zap.S().Infow("Verified computors list.", logging.Epoch, epoch, "result", verification.Result)
+
+		if republish && (verification.Result == domain.VerificationMissing || verification.Result == domain.VerificationMismatch) {
+			err = p.republishEpoch(list, status)
+			if err != nil {
+				zap.S().Errorw("Republishing computors list failed.", logging.Epoch, epoch, logging.Error, err)
+				verification.Error = err.Error()
+				report.Failed = append(report.Failed, epoch)
+			} else {
+				verification.Republished = true
+				report.Republished = append(report.Republished, epoch)
+			}
+		}
+	}
+	return report, nil
+}
+
+// verifyEpoch compares the lists by checksum. Returns an error only, if the downstream list cannot be read.
+func (p *EpochComputorsProcessor) verifyEpoch(reader DownstreamReader, epoch uint32) (*domain.EpochVerification, *domain.EpochComputors, error) {
+	verification := &domain.EpochVerification{Epoch: epoch}
+	list, err := p.fetchArchiverComputorList(epoch)
+	if err == nil && list.Epoch != epoch {
+		err = fmt.Errorf("wrong epoch computor list returned by archiver. expected [%d] got [%d]", epoch, list.Epoch)
+	}
+	if err != nil {
+		verification.Result = domain.VerificationUnavailable
+		verification.Error = err.Error()
+		return verification, nil, nil
+	}
+	checksum, err := computeComputorsChecksum(*list)
+	if err != nil {
+		return nil, nil, fmt.Errorf("computing computors checksum: %w", err)
+	}
+	verification.ArchiverChecksum = hex.EncodeToString(checksum)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	downstream, err := reader.GetLatestComputorList(ctx, epoch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading downstream computors list: %w", err)
+	}
+	if downstream == nil {
+		verification.Result = domain.VerificationMissing
+		return verification, list, nil
+	}
+
+	downstreamChecksum, err := computeComputorsChecksum(*downstream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("computing downstream computors checksum: %w", err)
+	}
+	verification.DownstreamChecksum = hex.EncodeToString(downstreamChecksum)
+	verification.DownstreamTick = downstream.TickNumber
+	if bytes.Equal(checksum, downstreamChecksum) {
+		verification.Result = domain.VerificationOk
+	} else {
+		verification.Result = domain.VerificationMismatch
+	}
+	return verification, list, nil
+}
+
+// republishEpoch verifies and publishes the list again. The tick number is taken from the stored list, if it was
+// published before, or resolved like for new lists.
+func (p *EpochComputorsProcessor) republishEpoch(list *domain.EpochComputors, status *domain.Status) error {
+	err := p.verifier.Verify(list)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSignature) {
+			p.processingMetrics.IncInvalidComputorLists()
+		}
+		return fmt.Errorf("verifying computors list: %w", err)
+	}
+
+	if list.TickNumber == 0 {
+		list.TickNumber, err = p.republishedTickNumber(list, status)
+		if err != nil {
+			return fmt.Errorf("resolving tick number: %w", err)
+		}
+	}
+
+	zap.S().Infow("Republish computors list.", logging.Epoch, list.Epoch, logging.Tick, list.TickNumber,
+		"signature", list.Signature)
+	err = p.Producer.SendMessage(context.Background(), list)
+	if err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	return nil
+}
+
+func (p *EpochComputorsProcessor) republishedTickNumber(list *domain.EpochComputors, status *domain.Status) (uint32, error) {
+	stored, err := p.dataStore.GetLastStoredComputorList(list.Epoch)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return 0, fmt.Errorf("getting last stored computor list for epoch [%d]: %w", list.Epoch, err)
+	}
+	if stored != nil && stored.Signature == list.Signature {
+		return stored.TickNumber, nil // published before
+	}
+
+	lastStoredChecksum, err := p.dataStore.GetLastStoredComputorListSum(list.Epoch)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return 0, fmt.Errorf("getting last stored computor list checksum for epoch [%d]: %w", list.Epoch, err)
+	}
+	isInitialListOfEpoch := len(lastStoredChecksum) == 0
+	previous, err := p.findPreviousComputorList(list.Epoch, isInitialListOfEpoch)
+	if err != nil {
+		return 0, fmt.Errorf("finding previous computor list: %w", err)
+	}
+	return p.resolveTickNumber(status, list, previous, isInitialListOfEpoch)
+}
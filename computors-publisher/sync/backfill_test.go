@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/computors-publisher/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type FakeDownstreamReader struct {
+	lists map[uint32]*domain.EpochComputors
+	err   error
+}
+
+func (f *FakeDownstreamReader) GetLatestComputorList(_ context.Context, epoch uint32) (*domain.EpochComputors, error) {
+	return f.lists[epoch], f.err
+}
+
+type FakeArchiveClientWithMissingLists struct {
+	FakeArchiveClient
+	missing map[uint32]bool
+}
+
+func (f *FakeArchiveClientWithMissingLists) GetEpochComputors(ctx context.Context, epoch uint32) (*domain.EpochComputors, error) {
+	if f.missing[epoch] {
+		return nil, errors.New("not found")
+	}
+	return f.FakeArchiveClient.GetEpochComputors(ctx, epoch)
+}
+
+var backfillMetrics = metrics.NewProcessingMetrics("test_backfill")
+
+func backfillTestSetup() (*domain.Status, *FakeArchiveClientWithMissingLists, *FakeDownstreamReader) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 103, TickNumber: 4500},
+		EpochList:         []uint32{100, 101, 102, 103},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+			101: {{FirstTick: 2000, LastTick: 2999}},
+			102: {{FirstTick: 3000, LastTick: 3999}},
+			103: {{FirstTick: 4000, LastTick: 4999}},
+		},
+	}
+	client := &FakeArchiveClientWithMissingLists{
+		FakeArchiveClient: FakeArchiveClient{status: status, computors: map[uint32]*domain.EpochComputors{
+			100: {Epoch: 100, Identities: []string{"A", "B"}, Signature: "sig-100"},
+			101: {Epoch: 101, Identities: []string{"A", "C"}, Signature: "sig-101"},
+			102: {Epoch: 102, Identities: []string{"A", "D"}, Signature: "sig-102"},
+		}},
+		missing: map[uint32]bool{103: true},
+	}
+	reader := &FakeDownstreamReader{lists: map[uint32]*domain.EpochComputors{
+		// tick number is not compared
+		100: {Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B"}, Signature: "sig-100"},
+		102: {Epoch: 102, TickNumber: 3000, Identities: []string{"A", "E"}, Signature: "sig-102-old"},
+	}}
+	return status, client, reader
+}
+
+func TestEpochComputorsProcessor_VerifyAndBackfill_Report(t *testing.T) {
+	_, client, reader := backfillTestSetup()
+	producer := &FakeProducer{}
+	proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, producer, &FakeVerifier{}, backfillMetrics)
+
+	report, err := proc.VerifyAndBackfill(reader, false)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{101}, report.Missing)
+	assert.Equal(t, []uint32{102}, report.Mismatched)
+	assert.Equal(t, []uint32{103}, report.Failed)
+	assert.Empty(t, report.Republished)
+	assert.Empty(t, producer.lists)
+
+	require.Len(t, report.Epochs, 4)
+	assert.Equal(t, domain.VerificationOk, report.Epochs[0].Result)
+	assert.Equal(t, report.Epochs[0].ArchiverChecksum, report.Epochs[0].DownstreamChecksum)
+	assert.Equal(t, uint32(1000), report.Epochs[0].DownstreamTick)
+	assert.Equal(t, domain.VerificationMissing, report.Epochs[1].Result)
+	assert.Empty(t, report.Epochs[1].DownstreamChecksum)
+	assert.Equal(t, domain.VerificationMismatch, report.Epochs[2].Result)
+	assert.NotEqual(t, report.Epochs[2].ArchiverChecksum, report.Epochs[2].DownstreamChecksum)
+	assert.Equal(t, domain.VerificationUnavailable, report.Epochs[3].Result)
+	assert.Contains(t, report.Epochs[3].Error, "not found")
+}
+
+func TestEpochComputorsProcessor_VerifyAndBackfill_Republish(t *testing.T) {
+	_, client, reader := backfillTestSetup()
+	store := &FakeDataStore{
+		checksums: map[uint32][]byte{101: []byte("sum"), 102: []byte("sum")},
+		lists: map[uint32]*domain.EpochComputors{
+			101: {Epoch: 101, TickNumber: 2000, Identities: []string{"A", "C"}, Signature: "sig-101"}, // published before
+			102: {Epoch: 102, TickNumber: 3000, Identities: []string{"A", "E"}, Signature: "sig-102-old"},
+		},
+	}
+	producer := &FakeProducer{}
+	verifier := &FakeVerifier{effectiveTick: 3456}
+	proc := NewEpochComputorsProcessor(client, store, producer, verifier, backfillMetrics)
+
+	report, err := proc.VerifyAndBackfill(reader, true)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{101, 102}, report.Republished)
+	assert.True(t, report.Epochs[1].Republished)
+	assert.True(t, report.Epochs[2].Republished)
+
+	require.Len(t, producer.lists, 2)
+	assert.Equal(t, uint32(2000), producer.lists[0].TickNumber) // from stored list
+	assert.Equal(t, uint32(3456), producer.lists[1].TickNumber) // resolved change within epoch
+	assert.Empty(t, producer.diffs)
+	assert.Equal(t, "sig-102-old", store.lists[102].Signature) // store not changed
+}
+
+func TestEpochComputorsProcessor_VerifyAndBackfill_GivenInvalidSignature_ThenFailed(t *testing.T) {
+	_, client, reader := backfillTestSetup()
+	producer := &FakeProducer{}
+	verifier := &FakeVerifier{err: domain.ErrInvalidSignature}
+	proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, producer, verifier, backfillMetrics)
+
+	report, err := proc.VerifyAndBackfill(reader, true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Republished)
+	assert.Equal(t, []uint32{101, 102, 103}, report.Failed)
+	assert.Contains(t, report.Epochs[1].Error, "invalid computors list signature")
+	assert.Empty(t, producer.lists)
+}
+
+func TestEpochComputorsProcessor_VerifyAndBackfill_GivenDownstreamError_ThenError(t *testing.T) {
+	_, client, reader := backfillTestSetup()
+	reader.err = errors.New("test")
+	proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, &FakeProducer{}, &FakeVerifier{}, backfillMetrics)
+
+	_, err := proc.VerifyAndBackfill(reader, false)
+	require.Error(t, err)
+}
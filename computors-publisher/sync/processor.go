@@ -7,19 +7,21 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/qubic/computors-publisher/db"
 	"github.com/qubic/computors-publisher/domain"
 	"github.com/qubic/computors-publisher/metrics"
 	"github.com/qubic/go-qubic/common"
+	"github.com/qubic/logging"
 	"github.com/twmb/franz-go/pkg/kerr"
+	"go.uber.org/zap"
 )
 
 type ArchiveClient interface {
 	GetStatus(ctx context.Context) (*domain.Status, error)
 	GetEpochComputors(ctx context.Context, epoch uint32) (*domain.EpochComputors, error)
+	GetTickVotes(ctx context.Context, tickNumber uint32) (*domain.TickVotes, error)
 }
 
 type DataStore interface {
@@ -27,43 +29,81 @@ type DataStore interface {
 	GetLastProcessedEpoch() (uint32, error)
 	SetLastStoredComputorListSum(epoch uint32, sum []byte) error
 	GetLastStoredComputorListSum(epoch uint32) ([]byte, error)
+	SetLastStoredComputorList(epoch uint32, list *domain.EpochComputors) error
+	GetLastStoredComputorList(epoch uint32) (*domain.EpochComputors, error)
 }
 
 type Producer interface {
 	SendMessage(ctx context.Context, computorList *domain.EpochComputors) error
+	SendDiff(ctx context.Context, diff *domain.EpochComputorsDiff) error
+}
+
+type Verifier interface {
+	Verify(list *domain.EpochComputors) error
+	VerifyVote(tick, next *domain.TickVotes, computorIndex uint32, identity string) error
 }
 
 type EpochComputorsProcessor struct {
 	archiveClient     ArchiveClient
 	dataStore         DataStore
 	Producer          Producer
+	verifier          Verifier
 	processingMetrics *metrics.ProcessingMetrics
+	forceCheck        chan struct{}
+	archiverEpoch     uint32 // of the last check
+	publishedLists    int
 }
 
-func NewEpochComputorsProcessor(client ArchiveClient, store DataStore, producer Producer, metrics *metrics.ProcessingMetrics) *EpochComputorsProcessor {
+func NewEpochComputorsProcessor(client ArchiveClient, store DataStore, producer Producer, verifier Verifier, metrics *metrics.ProcessingMetrics) *EpochComputorsProcessor {
 	return &EpochComputorsProcessor{
 		archiveClient:     client,
 		dataStore:         store,
 		Producer:          producer,
+		verifier:          verifier,
 		processingMetrics: metrics,
+		forceCheck:        make(chan struct{}, 1),
 	}
 }
 
-func (p *EpochComputorsProcessor) StartProcessing() error {
+// StartProcessing checks for new computors lists according to the schedule or if a check is forced.
+func (p *EpochComputorsProcessor) StartProcessing(config ScheduleConfig) error {
 	// do one initial processing, so we do not wait until first tick
 	err := p.process()
 	if err != nil {
 		return err
 	}
-	log.Println("Initial processing completed. Starting loop...")
-	ticker := time.Tick(time.Second * 10)
-	for range ticker {
+	zap.S().Info("Initial processing completed. Starting loop.")
+	checkSchedule := &schedule{config: config}
+	for {
+		now := time.Now()
+		delay := checkSchedule.next(now, p.archiverEpoch, p.publishedLists)
+		p.processingMetrics.SetSchedule(delay, now.Add(delay))
+		zap.S().Debugw("Scheduled next check.", logging.Duration, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-p.forceCheck:
+			timer.Stop()
+			p.processingMetrics.IncForcedChecks()
+			zap.S().Info("Forced check.")
+		}
+
 		err = p.process()
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+}
+
+// ForceCheck triggers a check without waiting for the schedule. Returns false, if a check is already pending.
+func (p *EpochComputorsProcessor) ForceCheck() bool {
+	select {
+	case p.forceCheck <- struct{}{}:
+		return true
+	default:
+		return false
+	}
 }
 
 func (p *EpochComputorsProcessor) process() error {
@@ -75,7 +115,7 @@ func (p *EpochComputorsProcessor) process() error {
 			}
 		}
 		// only exit, if non-retriable kafka error
-		log.Printf("Error processing computors: %v", err)
+		zap.S().Errorw("Processing computors failed.", logging.Error, err)
 	}
 	return nil
 }
@@ -88,6 +128,7 @@ func (p *EpochComputorsProcessor) processEpochs() error {
 	}
 	archiverEpoch := status.LastProcessedTick.Epoch
 	archiverTick := status.LastProcessedTick.TickNumber
+	p.archiverEpoch = archiverEpoch
 	p.processingMetrics.SetSourceTick(archiverEpoch, archiverTick)
 
 	lastProcessedEpoch, err := p.dataStore.GetLastProcessedEpoch()
@@ -107,12 +148,29 @@ func (p *EpochComputorsProcessor) processEpochs() error {
 		return fmt.Errorf("finding epochs to publish: %w", err)
 	}
 
+	// a failing epoch does not block the newer epochs. The last processed epoch is not advanced beyond it, so that it
+	// is retried with the next check.
+	var failures []error
 	for _, epoch := range epochsToProcess {
 		err = p.processEpoch(epoch, status)
 		if err != nil {
-			return fmt.Errorf("processing epoch [%d]: %w", epoch, err)
+			if _, ok := errors.AsType[*kerr.Error](err); ok {
+				return fmt.Errorf("processing epoch [%d]: %w", epoch, err) // affects all epochs
+			}
+			failures = append(failures, fmt.Errorf("processing epoch [%d]: %w", epoch, err))
+			continue
+		}
+		if len(failures) == 0 && epoch != lastProcessedEpoch {
+			err = p.dataStore.SetLastProcessedEpoch(epoch)
+			if err != nil {
+				return fmt.Errorf("failed to store last processed epoch [%d]: %w", epoch, err)
+			}
+			lastProcessedEpoch = epoch
 		}
 	}
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
 	p.processingMetrics.SetProcessedTick(archiverEpoch, archiverTick)
 	return nil
 }
@@ -140,42 +198,84 @@ func (p *EpochComputorsProcessor) processEpoch(epoch uint32, status *domain.Stat
 	if bytes.Equal(checksum, lastStoredChecksum) {
 		return nil // same list already published
 	}
-	log.Printf("New computors list checksum [%s] for epoch [%d]. Previous: [%s]",
-		hex.EncodeToString(checksum), epoch,
-		hex.EncodeToString(lastStoredChecksum))
+	zap.S().Infow("New computors list checksum.", logging.Epoch, epoch, "checksum", hex.EncodeToString(checksum),
+		"previous", hex.EncodeToString(lastStoredChecksum))
 
-	if epochComputorList.TickNumber == 0 { // archiver might return '0' for initial list it collected in one epoch
-		epochComputorList.TickNumber, err = calculateTickNumber(status, epoch, len(lastStoredChecksum) == 0)
-		if err != nil {
-			return fmt.Errorf("calculating tick number: %w", err)
+	err = p.verifier.Verify(epochComputorList)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSignature) {
+			p.processingMetrics.IncInvalidComputorLists()
+			zap.S().Errorw("Refusing computors list with invalid signature.", logging.Epoch, epoch,
+				"signature", epochComputorList.Signature, logging.Error, err)
 		}
-	} else {
-		log.Printf("[WARN] tick number already set. Remove old tick number calculation code.")
+		return fmt.Errorf("verifying computors list for epoch [%d]: %w", epoch, err)
 	}
 
-	log.Printf("Publish new list for epoch [%d], tick [%d], signature [%s].",
-		epochComputorList.Epoch, epochComputorList.TickNumber, epochComputorList.Signature)
+	previousList, err := p.findPreviousComputorList(epoch, len(lastStoredChecksum) == 0)
+	if err != nil {
+		return fmt.Errorf("finding previous computor list: %w", err)
+	}
+
+	// archiver might return '0' for lists it collected before it knew the tick
+	epochComputorList.TickNumber, err = p.resolveTickNumber(status, epochComputorList, previousList, len(lastStoredChecksum) == 0)
+	if err != nil {
+		return fmt.Errorf("resolving tick number: %w", err)
+	}
+
+	zap.S().Infow("Publish new computors list.", logging.Epoch, epochComputorList.Epoch, logging.Tick, epochComputorList.TickNumber,
+		"signature", epochComputorList.Signature)
 	err = p.Producer.SendMessage(context.Background(), epochComputorList)
 	if err != nil {
 		return fmt.Errorf("sending message: %w", err)
 	}
 	p.processingMetrics.SetProcessedTick(epochComputorList.Epoch, epochComputorList.TickNumber)
 	p.processingMetrics.IncProcessedMessages()
+	p.publishedLists++
 
-	err = p.dataStore.SetLastStoredComputorListSum(epoch, checksum)
+	if previousList == nil && len(lastStoredChecksum) > 0 {
+		// list was published before the lists were stored. Diff would be wrong.
+		zap.S().Warnw("Previous computors list unknown. Skipping diff.", logging.Epoch, epoch)
+	} else {
+		diff := domain.DiffComputors(previousList, epochComputorList)
+		zap.S().Infow("Publish computors list diff.", logging.Epoch, diff.Epoch, logging.Tick, diff.TickNumber,
+			"previousEpoch", diff.PreviousEpoch, "added", len(diff.Added), "removed", len(diff.Removed))
+		err = p.Producer.SendDiff(context.Background(), diff)
+		if err != nil {
+			return fmt.Errorf("sending diff: %w", err)
+		}
+	}
+
+	err = p.dataStore.SetLastStoredComputorList(epoch, epochComputorList)
 	if err != nil {
-		return fmt.Errorf("setting last stored computor list sum for epoch [%d]: %w", epoch, err)
+		return fmt.Errorf("setting last stored computor list for epoch [%d]: %w", epoch, err)
 	}
 
-	err = p.dataStore.SetLastProcessedEpoch(epoch)
+	err = p.dataStore.SetLastStoredComputorListSum(epoch, checksum)
 	if err != nil {
-		return fmt.Errorf("failed to store last processed epoch [%d]: %w", epoch, err)
+		return fmt.Errorf("setting last stored computor list sum for epoch [%d]: %w", epoch, err)
 	}
 
-	log.Printf("Successfully published computors list for epoch [%d].", epoch)
+	zap.S().Infow("Published computors list.", logging.Epoch, epoch)
 	return nil
 }
 
+// findPreviousComputorList returns the last published list of the epoch or, for the initial list of an epoch, the last
+// published list of the previous epoch. Returns nil, if there is no such list.
+func (p *EpochComputorsProcessor) findPreviousComputorList(epoch uint32, isInitialListOfEpoch bool) (*domain.EpochComputors, error) {
+	listEpoch := epoch
+	if isInitialListOfEpoch {
+		listEpoch = epoch - 1
+	}
+	list, err := p.dataStore.GetLastStoredComputorList(listEpoch)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting last stored computor list for epoch [%d]: %w", listEpoch, err)
+	}
+	return list, nil
+}
+
 func (p *EpochComputorsProcessor) findEpochsToPublish(status *domain.Status, currentEpoch, lastProcessedEpoch uint32) ([]uint32, error) {
 	if currentEpoch-lastProcessedEpoch > 1 { // Process multiple epochs
 		startIndex := -1
@@ -206,25 +306,6 @@ func (p *EpochComputorsProcessor) fetchArchiverComputorList(epoch uint32) (*doma
 
 }
 
-func calculateTickNumber(status *domain.Status, epoch uint32, isInitialListOfEpoch bool) (uint32, error) {
-	if isInitialListOfEpoch {
-		// initial list of epoch - return initial tick
-		tickIntervals, ok := status.TickIntervals[epoch]
-		if !ok || len(tickIntervals) == 0 {
-			return 0, fmt.Errorf("calculating initial tick of epoch [%d]", epoch)
-		}
-		return tickIntervals[0].FirstTick, nil
-	} else {
-		log.Printf("Computors list changed within epoch.")
-		if epoch != status.LastProcessedTick.Epoch {
-			// setting tick number for changes in old epochs is not supported
-			return 0, fmt.Errorf("unexpected list change in epoch [%d]", epoch)
-		}
-		// list changed within epoch. Return current tick.
-		return status.LastProcessedTick.TickNumber, nil
-	}
-}
-
 func computeComputorsChecksum(computors domain.EpochComputors) ([]byte, error) {
 	var buff bytes.Buffer
 
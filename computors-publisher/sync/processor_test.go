@@ -3,9 +3,11 @@ package sync
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/qubic/computors-publisher/db"
 	"github.com/qubic/computors-publisher/domain"
 	"github.com/qubic/computors-publisher/metrics"
 	"github.com/stretchr/testify/assert"
@@ -31,8 +33,13 @@ func TestProcessor_computeComputorsChecksum(t *testing.T) {
 	assert.Equal(t, "846e6cd5a26cd76c361d802bcf12d4c4eb02cf66268ebff18af9e921dae0118f", hex.EncodeToString(sum))
 }
 
+var testSchedule = ScheduleConfig{MinInterval: 10 * time.Millisecond, MaxInterval: 100 * time.Millisecond, TransitionWindow: time.Second}
+
 type FakeArchiveClient struct {
-	status *domain.Status
+	status       *domain.Status
+	computors    map[uint32]*domain.EpochComputors // optional
+	missingVotes map[uint32]bool                   // optional. ticks without votes.
+	tickErrors   map[uint32]error                  // optional. errors of the votes requests.
 }
 
 func (f *FakeArchiveClient) GetStatus(_ context.Context) (*domain.Status, error) {
@@ -40,6 +47,10 @@ func (f *FakeArchiveClient) GetStatus(_ context.Context) (*domain.Status, error)
 }
 
 func (f *FakeArchiveClient) GetEpochComputors(_ context.Context, epoch uint32) (*domain.EpochComputors, error) {
+	if list, ok := f.computors[epoch]; ok {
+		copied := *list
+		return &copied, nil
+	}
 	return &domain.EpochComputors{
 		Epoch:      epoch,
 		TickNumber: 1000,
@@ -48,9 +59,23 @@ func (f *FakeArchiveClient) GetEpochComputors(_ context.Context, epoch uint32) (
 	}, nil
 }
 
+func (f *FakeArchiveClient) GetTickVotes(_ context.Context, tickNumber uint32) (*domain.TickVotes, error) {
+	if err, ok := f.tickErrors[tickNumber]; ok {
+		return nil, err
+	}
+	votes := map[uint32]*domain.ComputorVote{}
+	if !f.missingVotes[tickNumber] {
+		for i := range uint32(3) {
+			votes[i] = &domain.ComputorVote{}
+		}
+	}
+	return &domain.TickVotes{TickNumber: tickNumber, Votes: votes}, nil
+}
+
 type FakeDataStore struct {
 	lastProcessedEpoch uint32
 	checksums          map[uint32][]byte
+	lists              map[uint32]*domain.EpochComputors
 }
 
 func (f *FakeDataStore) SetLastProcessedEpoch(epoch uint32) error {
@@ -77,6 +102,55 @@ func (f *FakeDataStore) GetLastStoredComputorListSum(epoch uint32) ([]byte, erro
 	return f.checksums[epoch], nil
 }
 
+func (f *FakeDataStore) SetLastStoredComputorList(epoch uint32, list *domain.EpochComputors) error {
+	if f.lists == nil {
+		f.lists = make(map[uint32]*domain.EpochComputors)
+	}
+	f.lists[epoch] = list
+	return nil
+}
+
+func (f *FakeDataStore) GetLastStoredComputorList(epoch uint32) (*domain.EpochComputors, error) {
+	list, ok := f.lists[epoch]
+	if !ok {
+		return nil, db.ErrNotFound
+	}
+	return list, nil
+}
+
+type FakeProducer struct {
+	lists []*domain.EpochComputors
+	diffs []*domain.EpochComputorsDiff
+}
+
+func (f *FakeProducer) SendMessage(_ context.Context, computorList *domain.EpochComputors) error {
+	f.lists = append(f.lists, computorList)
+	return nil
+}
+
+func (f *FakeProducer) SendDiff(_ context.Context, diff *domain.EpochComputorsDiff) error {
+	f.diffs = append(f.diffs, diff)
+	return nil
+}
+
+type FakeVerifier struct {
+	err           error
+	effectiveTick uint32 // first tick with votes of the new computors
+	verifiedTicks []uint32
+}
+
+func (f *FakeVerifier) Verify(_ *domain.EpochComputors) error {
+	return f.err
+}
+
+func (f *FakeVerifier) VerifyVote(tick, _ *domain.TickVotes, _ uint32, _ string) error {
+	f.verifiedTicks = append(f.verifiedTicks, tick.TickNumber)
+	if tick.TickNumber < f.effectiveTick {
+		return domain.ErrInvalidSignature
+	}
+	return nil
+}
+
 type FakeProducerWithError struct {
 	err error
 }
@@ -85,6 +159,10 @@ func (f *FakeProducerWithError) SendMessage(_ context.Context, _ *domain.EpochCo
 	return f.err
 }
 
+func (f *FakeProducerWithError) SendDiff(_ context.Context, _ *domain.EpochComputorsDiff) error {
+	return f.err
+}
+
 func TestEpochComputorsProcessor_StartProcessing_NonRetriableKafkaError(t *testing.T) {
 	status := &domain.Status{
 		LastProcessedTick: domain.ProcessedTick{
@@ -104,12 +182,12 @@ func TestEpochComputorsProcessor_StartProcessing_NonRetriableKafkaError(t *testi
 	nonRetriableErr := kerr.MessageTooLarge
 	producer := &FakeProducerWithError{err: nonRetriableErr}
 	m := metrics.NewProcessingMetrics("test_nonretriable")
-	proc := NewEpochComputorsProcessor(client, store, producer, m)
+	proc := NewEpochComputorsProcessor(client, store, producer, &FakeVerifier{}, m)
 
 	// run with a timeout
 	errChan := make(chan error, 1)
 	go func() {
-		errChan <- proc.StartProcessing()
+		errChan <- proc.StartProcessing(testSchedule)
 	}()
 
 	// wait for the error or timeout
@@ -141,12 +219,12 @@ func TestEpochComputorsProcessor_StartProcessing_RetriableKafkaError(t *testing.
 	retriableErr := kerr.UnknownTopicOrPartition
 	producer := &FakeProducerWithError{err: retriableErr}
 	m := metrics.NewProcessingMetrics("test_retriable")
-	proc := NewEpochComputorsProcessor(client, store, producer, m)
+	proc := NewEpochComputorsProcessor(client, store, producer, &FakeVerifier{}, m)
 
 	// run with a short timeout
 	errChan := make(chan error, 1)
 	go func() {
-		errChan <- proc.StartProcessing()
+		errChan <- proc.StartProcessing(testSchedule)
 	}()
 
 	// wait briefly - should continue running with retriable errors
@@ -157,3 +235,162 @@ func TestEpochComputorsProcessor_StartProcessing_RetriableKafkaError(t *testing.
 		// expected - StartProcessing continues running with retriable errors
 	}
 }
+
+func TestEpochComputorsProcessor_processEpochs_FailingEpochDoesNotBlock(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 102, TickNumber: 3500},
+		EpochList:         []uint32{100, 101, 102},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+			101: {{FirstTick: 2000, LastTick: 2999}},
+			102: {{FirstTick: 3000, LastTick: 3999}},
+		},
+	}
+	// change within the old epoch 100 without votes in the archive
+	previous := &domain.EpochComputors{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B", "C"}, Signature: "sig-100"}
+	checksum, err := computeComputorsChecksum(*previous)
+	require.NoError(t, err)
+	client := &FakeArchiveClient{status: status, missingVotes: ticks(1001, 1999), computors: map[uint32]*domain.EpochComputors{
+		100: {Epoch: 100, Identities: []string{"A", "D", "C"}, Signature: "sig-100-2"},
+	}}
+	store := &FakeDataStore{
+		lastProcessedEpoch: 100,
+		checksums:          map[uint32][]byte{100: checksum},
+		lists:              map[uint32]*domain.EpochComputors{100: previous},
+	}
+	producer := &FakeProducer{}
+	proc := NewEpochComputorsProcessor(client, store, producer, &FakeVerifier{effectiveTick: 1500}, metrics.NewProcessingMetrics("test_failing_epoch"))
+
+	err = proc.processEpochs()
+	require.ErrorIs(t, err, errNoConclusiveTick)
+	require.Len(t, producer.lists, 2)
+	assert.Equal(t, uint32(101), producer.lists[0].Epoch)
+	assert.Equal(t, uint32(102), producer.lists[1].Epoch)
+	assert.Equal(t, uint32(100), store.lastProcessedEpoch, "failed epoch is retried")
+
+	// votes available
+	client.missingVotes = nil
+	err = proc.processEpochs()
+	require.NoError(t, err)
+	require.Len(t, producer.lists, 3)
+	assert.Equal(t, uint32(100), producer.lists[2].Epoch)
+	assert.Equal(t, uint32(1500), producer.lists[2].TickNumber)
+	assert.Equal(t, uint32(102), store.lastProcessedEpoch)
+}
+
+func TestEpochComputorsProcessor_processEpoch_PublishDiff(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 101, TickNumber: 2500},
+		EpochList:         []uint32{100, 101},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+			101: {{FirstTick: 2000, LastTick: 2999}},
+		},
+	}
+	client := &FakeArchiveClient{status: status, computors: map[uint32]*domain.EpochComputors{
+		100: {Epoch: 100, Identities: []string{"A", "B", "C"}, Signature: "sig-100"},
+	}}
+	store := &FakeDataStore{}
+	producer := &FakeProducer{}
+	verifier := &FakeVerifier{effectiveTick: 2500}
+	proc := NewEpochComputorsProcessor(client, store, producer, verifier, metrics.NewProcessingMetrics("test_diff"))
+
+	// initial list without previous list
+	err := proc.processEpoch(100, status)
+	require.NoError(t, err)
+	require.Len(t, producer.diffs, 1)
+	assert.Equal(t, &domain.EpochComputorsDiff{
+		Epoch:      100,
+		TickNumber: 1000,
+		Added:      []domain.ComputorChange{{Index: 0, Identity: "A"}, {Index: 1, Identity: "B"}, {Index: 2, Identity: "C"}},
+		Removed:    []domain.ComputorChange{},
+	}, producer.diffs[0])
+
+	// same list again
+	err = proc.processEpoch(100, status)
+	require.NoError(t, err)
+	require.Len(t, producer.lists, 1)
+	require.Len(t, producer.diffs, 1)
+
+	// epoch transition. compare with the last list of the previous epoch.
+	client.computors[101] = &domain.EpochComputors{Epoch: 101, Identities: []string{"A", "D", "C"}, Signature: "sig-101"}
+	err = proc.processEpoch(101, status)
+	require.NoError(t, err)
+	require.Len(t, producer.diffs, 2)
+	assert.Equal(t, &domain.EpochComputorsDiff{
+		Epoch:              101,
+		TickNumber:         2000,
+		PreviousEpoch:      100,
+		PreviousTickNumber: 1000,
+		Added:              []domain.ComputorChange{{Index: 1, Identity: "D"}},
+		Removed:            []domain.ComputorChange{{Index: 1, Identity: "B"}},
+	}, producer.diffs[1])
+
+	// change within epoch
+	client.computors[101] = &domain.EpochComputors{Epoch: 101, Identities: []string{"E", "D", "C"}, Signature: "sig-101-2"}
+	err = proc.processEpoch(101, status)
+	require.NoError(t, err)
+	require.Len(t, producer.lists, 3)
+	require.Len(t, producer.diffs, 3)
+	assert.Equal(t, &domain.EpochComputorsDiff{
+		Epoch:              101,
+		TickNumber:         2500,
+		PreviousEpoch:      101,
+		PreviousTickNumber: 2000,
+		Added:              []domain.ComputorChange{{Index: 0, Identity: "E"}},
+		Removed:            []domain.ComputorChange{{Index: 0, Identity: "A"}},
+	}, producer.diffs[2])
+	assert.Equal(t, []string{"E", "D", "C"}, store.lists[101].Identities)
+}
+
+func TestEpochComputorsProcessor_processEpoch_SkipDiffWithoutPreviousList(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 100, TickNumber: 1500},
+		EpochList:         []uint32{100},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+		},
+	}
+	client := &FakeArchiveClient{status: status, computors: map[uint32]*domain.EpochComputors{
+		100: {Epoch: 100, Identities: []string{"A"}, Signature: "sig-100"},
+	}}
+	// checksum of an older list, that was published before the lists were stored
+	store := &FakeDataStore{checksums: map[uint32][]byte{100: []byte("old")}}
+	producer := &FakeProducer{}
+	proc := NewEpochComputorsProcessor(client, store, producer, &FakeVerifier{}, metrics.NewProcessingMetrics("test_diff_skip"))
+
+	err := proc.processEpoch(100, status)
+	require.NoError(t, err)
+	assert.Len(t, producer.lists, 1)
+	assert.Empty(t, producer.diffs)
+	assert.NotNil(t, store.lists[100])
+}
+
+func TestEpochComputorsProcessor_processEpoch_RefuseInvalidSignature(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 100, TickNumber: 1500},
+		EpochList:         []uint32{100},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+		},
+	}
+	client := &FakeArchiveClient{status: status}
+	store := &FakeDataStore{}
+	producer := &FakeProducer{}
+	verifier := &FakeVerifier{err: fmt.Errorf("verifying: %w", domain.ErrInvalidSignature)}
+	proc := NewEpochComputorsProcessor(client, store, producer, verifier, metrics.NewProcessingMetrics("test_invalid_signature"))
+
+	err := proc.processEpoch(100, status)
+	require.ErrorIs(t, err, domain.ErrInvalidSignature)
+	assert.Empty(t, producer.lists)
+	assert.Empty(t, producer.diffs)
+	assert.Empty(t, store.checksums)
+	assert.Empty(t, store.lists)
+
+	// published after the archiver serves a valid list
+	verifier.err = nil
+	err = proc.processEpoch(100, status)
+	require.NoError(t, err)
+	assert.Len(t, producer.lists, 1)
+	assert.Len(t, producer.diffs, 1)
+}
@@ -0,0 +1,155 @@
+package sync
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/computors-publisher/metrics"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
+)
+
+var ErrNoQuorum = errors.New("no quorum")
+
+type QuorumArchiver struct {
+	Host   string
+	Client ArchiveClient
+}
+
+// QuorumClient queries the computors lists of several archivers and only returns a list, if the required number of
+// archivers returned the same list (same checksum). Status and votes are read from the first archiver.
+type QuorumClient struct {
+	archivers         []QuorumArchiver
+	required          int
+	diagnosticsFolder string
+	processingMetrics *metrics.ProcessingMetrics
+	lastDiagnostics   string // key of the last written diagnostics. Avoids writing the same disagreement every check.
+}
+
+func NewQuorumClient(archivers []QuorumArchiver, required int, diagnosticsFolder string, metrics *metrics.ProcessingMetrics) (*QuorumClient, error) {
+	if len(archivers) == 0 {
+		return nil, errors.New("no archivers")
+	}
+	if required < 1 || required > len(archivers) {
+		return nil, fmt.Errorf("required archivers [%d] needs to be between 1 and the number of archivers [%d]", required, len(archivers))
+	}
+	return &QuorumClient{
+		archivers:         archivers,
+		required:          required,
+		diagnosticsFolder: diagnosticsFolder,
+		processingMetrics: metrics,
+	}, nil
+}
+
+func (c *QuorumClient) GetStatus(ctx context.Context) (*domain.Status, error) {
+	return c.archivers[0].Client.GetStatus(ctx)
+}
+
+func (c *QuorumClient) GetTickVotes(ctx context.Context, tickNumber uint32) (*domain.TickVotes, error) {
+	return c.archivers[0].Client.GetTickVotes(ctx, tickNumber)
+}
+
+// GetEpochComputors returns the list, that the required number of archivers agree on. If the archivers returned
+// different lists, the disagreement is counted and the lists are written to the diagnostics folder.
+func (c *QuorumClient) GetEpochComputors(ctx context.Context, epoch uint32) (*domain.EpochComputors, error) {
+	results := c.fetchComputorLists(ctx, epoch)
+
+	agreeing := map[string]int{}
+	for _, result := range results {
+		if result.List != nil {
+			agreeing[result.Checksum]++
+		}
+	}
+	for _, result := range results {
+		if result.List != nil && agreeing[result.Checksum] >= c.required {
+			return result.List, nil
+		}
+	}
+
+	if len(agreeing) > 1 {
+		c.processingMetrics.IncComputorListDisagreements()
+		zap.S().Errorw("Archivers disagree on computors list.", logging.Epoch, epoch, "checksums", agreeing,
+			"required", c.required)
+		err := c.writeDiagnostics(epoch, results)
+		if err != nil {
+			zap.S().Errorw("Writing quorum diagnostics failed.", logging.Epoch, epoch, logging.Error, err)
+		}
+	}
+	return nil, fmt.Errorf("%w for computors list of epoch [%d]: %d of %d archivers required, checksums %v", ErrNoQuorum,
+		epoch, c.required, len(c.archivers), agreeing)
+}
+
+func (c *QuorumClient) fetchComputorLists(ctx context.Context, epoch uint32) []*domain.ArchiverResult {
+	results := make([]*domain.ArchiverResult, len(c.archivers))
+	var wg gosync.WaitGroup
+	for i, archiver := range c.archivers {
+		wg.Go(func() {
+			results[i] = fetchComputorList(ctx, archiver, epoch)
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+func fetchComputorList(ctx context.Context, archiver QuorumArchiver, epoch uint32) *domain.ArchiverResult {
+	result := &domain.ArchiverResult{Host: archiver.Host}
+	list, err := archiver.Client.GetEpochComputors(ctx, epoch)
+	if err == nil {
+		var checksum []byte
+		checksum, err = computeComputorsChecksum(*list)
+		result.Checksum = hex.EncodeToString(checksum)
+		result.List = list
+	}
+	if err != nil {
+		zap.S().Warnw("Getting computors list failed.", logging.Epoch, epoch, "host", archiver.Host, logging.Error, err)
+		result.Error = err.Error()
+		result.List = nil
+		result.Checksum = ""
+	}
+	return result
+}
+
+func (c *QuorumClient) writeDiagnostics(epoch uint32, results []*domain.ArchiverResult) error {
+	checksums := make([]string, 0, len(results))
+	for _, result := range results {
+		checksums = append(checksums, result.Host+"="+result.Checksum)
+	}
+	slices.Sort(checksums)
+	key := fmt.Sprintf("%d:%s", epoch, strings.Join(checksums, ","))
+	if key == c.lastDiagnostics {
+		return nil // already written
+	}
+
+	now := time.Now().UTC()
+	content, err := json.MarshalIndent(domain.QuorumDiagnostics{
+		Epoch:    epoch,
+		Time:     now,
+		Required: c.required,
+		Lists:    results,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling diagnostics: %w", err)
+	}
+	err = os.MkdirAll(c.diagnosticsFolder, 0755)
+	if err != nil {
+		return fmt.Errorf("creating diagnostics folder: %w", err)
+	}
+	path := filepath.Join(c.diagnosticsFolder, fmt.Sprintf("computors-%d-%d.json", epoch, now.UnixMilli()))
+	err = os.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("writing diagnostics file: %w", err)
+	}
+	c.lastDiagnostics = key
+	zap.S().Infow("Wrote quorum diagnostics.", logging.Epoch, epoch, "file", path)
+	return nil
+}
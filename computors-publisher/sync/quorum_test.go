@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/computors-publisher/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var quorumMetrics = metrics.NewProcessingMetrics("test_quorum")
+
+type FailingArchiveClient struct {
+	FakeArchiveClient
+}
+
+func (f *FailingArchiveClient) GetEpochComputors(_ context.Context, _ uint32) (*domain.EpochComputors, error) {
+	return nil, errors.New("test error")
+}
+
+func quorumArchiver(host, signature string) QuorumArchiver {
+	return QuorumArchiver{Host: host, Client: &FakeArchiveClient{computors: map[uint32]*domain.EpochComputors{
+		100: {Epoch: 100, Identities: []string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}, Signature: signature},
+	}}}
+}
+
+func TestNewQuorumClient_InvalidRequired(t *testing.T) {
+	archivers := []QuorumArchiver{quorumArchiver("a", "sig"), quorumArchiver("b", "sig")}
+	_, err := NewQuorumClient(archivers, 0, t.TempDir(), quorumMetrics)
+	assert.Error(t, err)
+	_, err = NewQuorumClient(archivers, 3, t.TempDir(), quorumMetrics)
+	assert.Error(t, err)
+	_, err = NewQuorumClient(nil, 1, t.TempDir(), quorumMetrics)
+	assert.Error(t, err)
+}
+
+func TestQuorumClient_GetEpochComputors_Agree(t *testing.T) {
+	folder := t.TempDir()
+	archivers := []QuorumArchiver{
+		quorumArchiver("a", "sig-1"),
+		quorumArchiver("b", "sig-2"),
+		quorumArchiver("c", "sig-2"),
+		{Host: "d", Client: &FailingArchiveClient{}},
+	}
+	client, err := NewQuorumClient(archivers, 2, folder, quorumMetrics)
+	require.NoError(t, err)
+
+	list, err := client.GetEpochComputors(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, "sig-2", list.Signature)
+
+	files, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestQuorumClient_GetEpochComputors_Disagree(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "diagnostics")
+	archivers := []QuorumArchiver{
+		quorumArchiver("a", "sig-1"),
+		quorumArchiver("b", "sig-2"),
+		{Host: "c", Client: &FailingArchiveClient{}},
+	}
+	client, err := NewQuorumClient(archivers, 2, folder, quorumMetrics)
+	require.NoError(t, err)
+
+	_, err = client.GetEpochComputors(context.Background(), 100)
+	assert.ErrorIs(t, err, ErrNoQuorum)
+	_, err = client.GetEpochComputors(context.Background(), 100)
+	assert.ErrorIs(t, err, ErrNoQuorum)
+
+	files, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	require.Len(t, files, 1) // same disagreement is written once
+
+	content, err := os.ReadFile(filepath.Join(folder, files[0].Name()))
+	require.NoError(t, err)
+	var diagnostics domain.QuorumDiagnostics
+	require.NoError(t, json.Unmarshal(content, &diagnostics))
+	assert.Equal(t, uint32(100), diagnostics.Epoch)
+	assert.Equal(t, 2, diagnostics.Required)
+	require.Len(t, diagnostics.Lists, 3)
+	assert.Equal(t, "a", diagnostics.Lists[0].Host)
+	assert.Equal(t, "sig-1", diagnostics.Lists[0].List.Signature)
+	assert.Equal(t, "sig-2", diagnostics.Lists[1].List.Signature)
+	assert.NotEqual(t, diagnostics.Lists[0].Checksum, diagnostics.Lists[1].Checksum)
+	assert.Nil(t, diagnostics.Lists[2].List)
+	assert.Equal(t, "test error", diagnostics.Lists[2].Error)
+}
+
+func TestQuorumClient_GetEpochComputors_Unavailable(t *testing.T) {
+	folder := t.TempDir()
+	archivers := []QuorumArchiver{
+		quorumArchiver("a", "sig-1"),
+		{Host: "b", Client: &FailingArchiveClient{}},
+	}
+	client, err := NewQuorumClient(archivers, 2, folder, quorumMetrics)
+	require.NoError(t, err)
+
+	_, err = client.GetEpochComputors(context.Background(), 100)
+	assert.ErrorIs(t, err, ErrNoQuorum)
+
+	files, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Empty(t, files) // no conflicting lists
+}
@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/qubic/epochs"
+)
+
+// ScheduleConfig configures the processing cadence. The computors lists change only a few times per epoch, mostly
+// around the epoch transition.
+type ScheduleConfig struct {
+	MinInterval      time.Duration // interval around epoch transitions and after new lists
+	MaxInterval      time.Duration // maximum interval mid-epoch
+	TransitionWindow time.Duration // fast polling after an epoch change or a new list and around the expected epoch start
+}
+
+// schedule calculates the delay until the next check. It polls with the minimum interval in the transition windows and
+// backs off exponentially otherwise.
+type schedule struct {
+	config         ScheduleConfig
+	interval       time.Duration
+	epoch          uint32
+	publishedLists int
+	fastUntil      time.Time
+}
+
+func (s *schedule) next(now time.Time, epoch uint32, publishedLists int) time.Duration {
+	if epoch != s.epoch || publishedLists != s.publishedLists {
+		// epoch transition or new list. There might be more changes soon.
+		s.epoch, s.publishedLists = epoch, publishedLists
+		s.fastUntil = now.Add(s.config.TransitionWindow)
+	}
+
+	untilEpochStart := epochs.NextStart(now).Sub(now)
+	sinceEpochStart := now.Sub(epochs.Start(now))
+	if now.Before(s.fastUntil) || untilEpochStart <= s.config.TransitionWindow || sinceEpochStart < s.config.TransitionWindow {
+		s.interval = s.config.MinInterval
+		return s.interval
+	}
+
+	s.interval = min(max(s.interval*2, s.config.MinInterval), s.config.MaxInterval)
+	// wake up at the beginning of the window before the expected epoch start
+	return max(min(s.interval, untilEpochStart-s.config.TransitionWindow), s.config.MinInterval)
+}
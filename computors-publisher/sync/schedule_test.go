@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/computors-publisher/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var scheduleConfig = ScheduleConfig{MinInterval: 5 * time.Second, MaxInterval: 5 * time.Minute, TransitionWindow: 30 * time.Minute}
+
+func Test_schedule_next_BackOffMidEpoch(t *testing.T) {
+	s := &schedule{config: scheduleConfig}
+	now := time.Date(2025, time.March, 7, 8, 0, 0, 0, time.UTC) // friday
+
+	// first check. epoch is new.
+	assert.Equal(t, 5*time.Second, s.next(now, 100, 0))
+	assert.Equal(t, 5*time.Second, s.next(now.Add(29*time.Minute), 100, 0))
+
+	// after transition window
+	now = now.Add(31 * time.Minute)
+	var delays []time.Duration
+	for range 9 {
+		delays = append(delays, s.next(now, 100, 0))
+	}
+	assert.Equal(t, []time.Duration{
+		10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 160 * time.Second,
+		5 * time.Minute, 5 * time.Minute, 5 * time.Minute, 5 * time.Minute,
+	}, delays)
+
+	// new list published
+	assert.Equal(t, 5*time.Second, s.next(now, 100, 1))
+	assert.Equal(t, 5*time.Second, s.next(now.Add(29*time.Minute), 100, 1))
+	assert.Equal(t, 10*time.Second, s.next(now.Add(31*time.Minute), 100, 1))
+}
+
+func Test_schedule_next_FastAroundEpochTransition(t *testing.T) {
+	s := &schedule{config: scheduleConfig, interval: 5 * time.Minute, epoch: 100}
+	epochStartTime := time.Date(2025, time.March, 12, 12, 0, 0, 0, time.UTC)
+
+	// wake up at the beginning of the window before the expected epoch start
+	assert.Equal(t, 2*time.Minute, s.next(epochStartTime.Add(-32*time.Minute), 100, 0))
+	assert.Equal(t, 5*time.Second, s.next(epochStartTime.Add(-30*time.Minute), 100, 0))
+	assert.Equal(t, 5*time.Second, s.next(epochStartTime.Add(-time.Minute), 100, 0))
+
+	// archiver switches to new epoch later than expected
+	assert.Equal(t, 5*time.Second, s.next(epochStartTime.Add(time.Minute), 100, 0))
+	assert.Equal(t, 5*time.Second, s.next(epochStartTime.Add(20*time.Minute), 101, 0))
+	assert.Equal(t, 5*time.Second, s.next(epochStartTime.Add(49*time.Minute), 101, 0))
+	assert.Equal(t, 10*time.Second, s.next(epochStartTime.Add(51*time.Minute), 101, 0))
+}
+
+func TestEpochComputorsProcessor_ForceCheck(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 100, TickNumber: 1000},
+		EpochList:         []uint32{100},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+		},
+	}
+	client := &FakeArchiveClient{status: status, computors: map[uint32]*domain.EpochComputors{
+		100: {Epoch: 100, Identities: []string{"A"}, Signature: "sig-1"},
+	}}
+	producer := &NotifyingProducer{lists: make(chan *domain.EpochComputors)}
+	proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, producer, &FakeVerifier{}, metrics.NewProcessingMetrics("test_force_check"))
+
+	go func() {
+		_ = proc.StartProcessing(ScheduleConfig{MinInterval: time.Hour, MaxInterval: time.Hour, TransitionWindow: time.Hour})
+	}()
+	require.Equal(t, "sig-1", receive(t, producer.lists).Signature)
+
+	// change the list and force check instead of waiting an hour
+	client.computors[100] = &domain.EpochComputors{Epoch: 100, Identities: []string{"B"}, Signature: "sig-2"}
+	require.True(t, proc.ForceCheck())
+	require.Equal(t, "sig-2", receive(t, producer.lists).Signature)
+}
+
+type NotifyingProducer struct {
+	lists chan *domain.EpochComputors
+}
+
+func (f *NotifyingProducer) SendMessage(_ context.Context, computorList *domain.EpochComputors) error {
+	f.lists <- computorList
+	return nil
+}
+
+func (f *NotifyingProducer) SendDiff(_ context.Context, _ *domain.EpochComputorsDiff) error {
+	return nil
+}
+
+func receive(t *testing.T, lists chan *domain.EpochComputors) *domain.EpochComputors {
+	select {
+	case list := <-lists:
+		return list
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for computors list")
+		return nil
+	}
+}
@@ -0,0 +1,203 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
+)
+
+// maxProbedTicks limits the number of ticks, that are checked after a tick without usable votes.
+const maxProbedTicks = 20
+
+var errNoConclusiveTick = errors.New("no tick with usable votes")
+
+// tickRange is a range of ticks, that can be searched by position.
+type tickRange []*domain.TickInterval
+
+func (r tickRange) length() int {
+	var length int
+	for _, interval := range r {
+		length += int(interval.LastTick-interval.FirstTick) + 1
+	}
+	return length
+}
+
+func (r tickRange) tick(position int) uint32 {
+	for _, interval := range r {
+		size := int(interval.LastTick-interval.FirstTick) + 1
+		if position < size {
+			return interval.FirstTick + uint32(position)
+		}
+		position -= size
+	}
+	panic(fmt.Sprintf("position [%d] out of range", position))
+}
+
+// isLastOfInterval returns true, if the tick at the position is the last tick of its interval. The next tick is not
+// available then.
+func (r tickRange) isLastOfInterval(position int) bool {
+	tickNumber := r.tick(position)
+	for _, interval := range r {
+		if interval.LastTick == tickNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTickNumber finds the tick, in which the computors list became effective:
+//   - the tick number provided by the archiver, if set.
+//   - the first tick of the epoch for the initial list of an epoch.
+//   - the first tick, in which a computor of a changed seat voted with the key of the new list, for a list change
+//     within the epoch. If there are no votes in the current epoch, the current tick is used. Lists of older epochs
+//     without votes can not be resolved. The epoch is retried with the next check.
+func (p *EpochComputorsProcessor) resolveTickNumber(status *domain.Status, list, previous *domain.EpochComputors, isInitialListOfEpoch bool) (uint32, error) {
+	if list.TickNumber > 0 {
+		return list.TickNumber, nil
+	}
+
+	intervals := status.TickIntervals[list.Epoch]
+	if len(intervals) == 0 {
+		return 0, fmt.Errorf("no ticks of epoch [%d] available", list.Epoch)
+	}
+	if isInitialListOfEpoch {
+		return intervals[0].FirstTick, nil
+	}
+
+	zap.S().Infow("Computors list changed within epoch.", logging.Epoch, list.Epoch)
+	if previous == nil {
+		// list was published before the lists were stored. The changed seats are unknown.
+		if list.Epoch != status.LastProcessedTick.Epoch {
+			return 0, fmt.Errorf("previous computors list of epoch [%d] unknown", list.Epoch)
+		}
+		zap.S().Warnw("Previous computors list unknown. Using current tick.", logging.Epoch, list.Epoch)
+		return status.LastProcessedTick.TickNumber, nil
+	}
+
+	changed := domain.DiffComputors(previous, list).Added
+	if len(changed) == 0 {
+		return 0, fmt.Errorf("no changed seats in computors list of epoch [%d]", list.Epoch)
+	}
+	searchRange := ticksAfter(intervals, previous.TickNumber)
+	tickNumber, err := p.findFirstTickVotedBy(searchRange, changed)
+	if errors.Is(err, errNoConclusiveTick) && list.Epoch == status.LastProcessedTick.Epoch {
+		// the votes of the new computors might not be available yet
+		zap.S().Warnw("No votes of the new computors found. Using current tick.", logging.Epoch, list.Epoch,
+			logging.Error, err)
+		return status.LastProcessedTick.TickNumber, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("searching first tick of computors list of epoch [%d]: %w", list.Epoch, err)
+	}
+	zap.S().Infow("Found first tick of computors list.", logging.Epoch, list.Epoch, logging.Tick, tickNumber,
+		"changedSeats", len(changed))
+	return tickNumber, nil
+}
+
+// ticksAfter returns the ticks of the intervals, that are larger than the given tick.
+func ticksAfter(intervals []*domain.TickInterval, tickNumber uint32) tickRange {
+	var result tickRange
+	for _, interval := range intervals {
+		if interval.LastTick <= tickNumber {
+			continue
+		}
+		result = append(result, &domain.TickInterval{FirstTick: max(interval.FirstTick, tickNumber+1), LastTick: interval.LastTick})
+	}
+	return result
+}
+
+// findFirstTickVotedBy binary searches the first tick, in which one of the changed computors voted with its new key.
+// Ticks without a vote of a changed computor are inconclusive and skipped.
+func (p *EpochComputorsProcessor) findFirstTickVotedBy(ticks tickRange, changed []domain.ComputorChange) (uint32, error) {
+	low, high := 0, ticks.length() // the result is in [low, high)
+	found := false
+	for low < high {
+		middle := low + (high-low)/2
+		position, effective, err := p.probe(ticks, middle, high, changed)
+		if err != nil {
+			return 0, err
+		}
+		if effective {
+			high = position
+			found = true
+		} else {
+			low = position + 1
+		}
+	}
+	if !found {
+		return 0, errors.New("no tick with votes of the new computors found")
+	}
+	return ticks.tick(high), nil
+}
+
+// probe checks the tick at the position and, if it is inconclusive, the following ticks before the limit. Returns the
+// position of the checked tick and whether the new computors list was in effect.
+func (p *EpochComputorsProcessor) probe(ticks tickRange, position, limit int, changed []domain.ComputorChange) (int, bool, error) {
+	for i := position; i < limit && i < position+maxProbedTicks; i++ {
+		if ticks.isLastOfInterval(i) {
+			continue // the votes can only be verified with the next tick
+		}
+		effective, err := p.isListEffective(ticks.tick(i), changed)
+		if errors.Is(err, errNoConclusiveTick) {
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		return i, effective, nil
+	}
+	if limit-position <= maxProbedTicks {
+		return limit - 1, false, nil // all remaining ticks are inconclusive. Keep searching below.
+	}
+	return 0, false, fmt.Errorf("%w after tick [%d]", errNoConclusiveTick, ticks.tick(position))
+}
+
+// isListEffective returns true, if a computor of a changed seat voted in the tick with the key of the new list. Ticks,
+// that are not found in the archive, are inconclusive. Other archiver errors are returned.
+func (p *EpochComputorsProcessor) isListEffective(tickNumber uint32, changed []domain.ComputorChange) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	tick, err := p.getTickVotes(ctx, tickNumber)
+	if err != nil {
+		return false, err
+	}
+	next, err := p.getTickVotes(ctx, tickNumber+1)
+	if err != nil {
+		return false, err
+	}
+
+	voted := false
+	for _, seat := range changed {
+		if _, ok := tick.Votes[uint32(seat.Index)]; !ok {
+			continue
+		}
+		voted = true
+		err = p.verifier.VerifyVote(tick, next, uint32(seat.Index), seat.Identity)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, domain.ErrInvalidSignature) {
+			return false, fmt.Errorf("verifying vote of computor [%d] in tick [%d]: %w", seat.Index, tickNumber, err)
+		}
+	}
+	if !voted {
+		return false, errNoConclusiveTick
+	}
+	return false, nil
+}
+
+func (p *EpochComputorsProcessor) getTickVotes(ctx context.Context, tickNumber uint32) (*domain.TickVotes, error) {
+	votes, err := p.archiveClient.GetTickVotes(ctx, tickNumber)
+	if errors.Is(err, domain.ErrTickNotFound) {
+		return nil, fmt.Errorf("%w: getting votes of tick [%d]: %w", errNoConclusiveTick, tickNumber, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting votes of tick [%d]: %w", tickNumber, err)
+	}
+	return votes, nil
+}
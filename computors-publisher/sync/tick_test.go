@@ -0,0 +1,243 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/qubic/computors-publisher/domain"
+	"github.com/qubic/computors-publisher/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var resolveTickMetrics = metrics.NewProcessingMetrics("test_resolve_tick")
+
+var notFound = fmt.Errorf("getting archiver quorum data: %w", domain.ErrTickNotFound)
+
+func TestEpochComputorsProcessor_resolveTickNumber(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 101, TickNumber: 2999},
+		EpochList:         []uint32{100, 101},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1199}, {FirstTick: 1500, LastTick: 1999}},
+			101: {{FirstTick: 2000, LastTick: 2999}},
+		},
+	}
+	previous := &domain.EpochComputors{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B", "C"}}
+	changed := &domain.EpochComputors{Epoch: 100, Identities: []string{"A", "D", "C"}}
+
+	tests := []struct {
+		name          string
+		list          *domain.EpochComputors
+		previous      *domain.EpochComputors
+		isInitialList bool
+		effectiveTick uint32
+		missingVotes  map[uint32]bool
+		tickErrors    map[uint32]error
+		expected      uint32
+	}{
+		{
+			name:     "tick number provided by archiver",
+			list:     &domain.EpochComputors{Epoch: 100, TickNumber: 1234},
+			expected: 1234,
+		},
+		{
+			name:          "initial list of epoch",
+			list:          &domain.EpochComputors{Epoch: 101},
+			isInitialList: true,
+			expected:      2000,
+		},
+		{
+			name:          "change in old epoch",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1100,
+			expected:      1100,
+		},
+		{
+			name:          "change in second interval",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1600,
+			expected:      1600,
+		},
+		{
+			name:          "change in first tick of second interval",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1300, // gap between the intervals
+			expected:      1500,
+		},
+		{
+			name:          "change in first tick after previous list",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 0,
+			expected:      1001,
+		},
+		{
+			name:          "skip ticks without votes",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1600,
+			missingVotes:  map[uint32]bool{1600: true, 1601: true, 1602: true},
+			expected:      1603,
+		},
+		{
+			name:          "skip ticks not found in archive",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1600,
+			tickErrors:    map[uint32]error{1601: notFound, 1602: notFound},
+			expected:      1603,
+		},
+		{
+			name:          "skip last tick of interval",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1199,
+			tickErrors:    map[uint32]error{1200: errors.New("no data available for requested tick")},
+			expected:      1500,
+		},
+		{
+			name:         "no votes in current epoch",
+			list:         &domain.EpochComputors{Epoch: 101, Identities: []string{"A", "D", "C"}},
+			previous:     &domain.EpochComputors{Epoch: 101, TickNumber: 2000, Identities: []string{"A", "B", "C"}},
+			missingVotes: ticks(2001, 2999),
+			expected:     2999,
+		},
+		{
+			name:     "unknown previous list in current epoch",
+			list:     &domain.EpochComputors{Epoch: 101, Identities: []string{"A"}},
+			expected: 2999,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &FakeArchiveClient{status: status, missingVotes: tc.missingVotes, tickErrors: tc.tickErrors}
+			verifier := &FakeVerifier{effectiveTick: tc.effectiveTick}
+			proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, &FakeProducer{}, verifier, resolveTickMetrics)
+
+			tick, err := proc.resolveTickNumber(status, tc.list, tc.previous, tc.isInitialList)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tick)
+			assert.LessOrEqual(t, len(verifier.verifiedTicks), 50) // binary search
+		})
+	}
+}
+
+func TestEpochComputorsProcessor_resolveTickNumber_Error(t *testing.T) {
+	status := &domain.Status{
+		LastProcessedTick: domain.ProcessedTick{Epoch: 101, TickNumber: 2999},
+		EpochList:         []uint32{100, 101},
+		TickIntervals: map[uint32][]*domain.TickInterval{
+			100: {{FirstTick: 1000, LastTick: 1999}},
+			101: {{FirstTick: 2000, LastTick: 2999}},
+		},
+	}
+	previous := &domain.EpochComputors{Epoch: 100, TickNumber: 1000, Identities: []string{"A", "B", "C"}}
+	changed := &domain.EpochComputors{Epoch: 100, Identities: []string{"A", "D", "C"}}
+
+	tests := []struct {
+		name          string
+		list          *domain.EpochComputors
+		previous      *domain.EpochComputors
+		effectiveTick uint32
+		missingVotes  map[uint32]bool
+		tickErrors    map[uint32]error
+	}{
+		{
+			name: "unknown epoch",
+			list: &domain.EpochComputors{Epoch: 99},
+		},
+		{
+			name: "unknown previous list in old epoch",
+			list: changed,
+		},
+		{
+			name:     "no changed seats",
+			list:     &domain.EpochComputors{Epoch: 100, Identities: []string{"A", "B", "C"}},
+			previous: previous,
+		},
+		{
+			name:          "new computors never voted",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 5000,
+		},
+		{
+			name:          "too many ticks without votes",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1600,
+			missingVotes:  ticks(1001, 1999),
+		},
+		{
+			name:          "archiver error",
+			list:          changed,
+			previous:      previous,
+			effectiveTick: 1600,
+			tickErrors:    tickErrors(1001, 1999, errors.New("connection refused")),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &FakeArchiveClient{status: status, missingVotes: tc.missingVotes, tickErrors: tc.tickErrors}
+			verifier := &FakeVerifier{effectiveTick: tc.effectiveTick}
+			proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, &FakeProducer{}, verifier, resolveTickMetrics)
+
+			_, err := proc.resolveTickNumber(status, tc.list, tc.previous, false)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEpochComputorsProcessor_isListEffective_NextTickError(t *testing.T) {
+	changed := []domain.ComputorChange{{Index: 1, Identity: "D"}}
+	client := &FakeArchiveClient{tickErrors: map[uint32]error{1501: notFound, 1601: errors.New("connection refused")}}
+	proc := NewEpochComputorsProcessor(client, &FakeDataStore{}, &FakeProducer{}, &FakeVerifier{}, resolveTickMetrics)
+
+	_, err := proc.isListEffective(1500, changed)
+	assert.ErrorIs(t, err, errNoConclusiveTick, "next tick not found")
+
+	_, err = proc.isListEffective(1600, changed)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, errNoConclusiveTick, "other errors are not inconclusive")
+}
+
+func Test_ticksAfter(t *testing.T) {
+	intervals := []*domain.TickInterval{{FirstTick: 10, LastTick: 19}, {FirstTick: 30, LastTick: 39}}
+	assert.Equal(t, tickRange{{FirstTick: 10, LastTick: 19}, {FirstTick: 30, LastTick: 39}}, ticksAfter(intervals, 0))
+	assert.Equal(t, tickRange{{FirstTick: 16, LastTick: 19}, {FirstTick: 30, LastTick: 39}}, ticksAfter(intervals, 15))
+	assert.Equal(t, tickRange{{FirstTick: 30, LastTick: 39}}, ticksAfter(intervals, 19))
+	assert.Empty(t, ticksAfter(intervals, 39))
+
+	ticks := ticksAfter(intervals, 15)
+	assert.Equal(t, 14, ticks.length())
+	assert.Equal(t, uint32(16), ticks.tick(0))
+	assert.Equal(t, uint32(19), ticks.tick(3))
+	assert.Equal(t, uint32(30), ticks.tick(4))
+	assert.Equal(t, uint32(39), ticks.tick(13))
+	assert.False(t, ticks.isLastOfInterval(2))
+	assert.True(t, ticks.isLastOfInterval(3))
+	assert.True(t, ticks.isLastOfInterval(13))
+}
+
+func ticks(from, to uint32) map[uint32]bool {
+	result := make(map[uint32]bool)
+	for tick := from; tick <= to; tick++ {
+		result[tick] = true
+	}
+	return result
+}
+
+func tickErrors(from, to uint32, err error) map[uint32]error {
+	result := make(map[uint32]error)
+	for tick := from; tick <= to; tick++ {
+		result[tick] = err
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+// Package epochs calculates the expected boundaries of qubic epochs. Epochs start on Wednesday 12:00 UTC and last
+// one week. The network might switch to the next epoch a bit later than expected.
+package epochs
+
+import "time"
+
+// Length is the duration of one epoch.
+const Length = 7 * 24 * time.Hour
+
+// reference is the start of some epoch. Used as reference for the epoch boundaries.
+var reference = time.Date(2024, time.January, 3, 12, 0, 0, 0, time.UTC) // a wednesday
+
+// Start returns the expected start of the epoch, that contains the time.
+func Start(t time.Time) time.Time {
+	epochs := t.Sub(reference) / Length
+	if t.Before(reference.Add(epochs * Length)) {
+		epochs-- // the division truncates towards zero
+	}
+	return reference.Add(epochs * Length)
+}
+
+// NextStart returns the expected start of the epoch after the epoch, that contains the time.
+func NextStart(t time.Time) time.Time {
+	return Start(t).Add(Length)
+}
@@ -0,0 +1,28 @@
+package epochs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStart(t *testing.T) {
+	friday := time.Date(2025, time.March, 7, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.March, 5, 12, 0, 0, 0, time.UTC), Start(friday))
+
+	epochStart := time.Date(2025, time.March, 12, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, epochStart, Start(epochStart))
+	assert.Equal(t, time.Date(2025, time.March, 5, 12, 0, 0, 0, time.UTC), Start(epochStart.Add(-time.Nanosecond)))
+
+	beforeReference := time.Date(2023, time.December, 29, 8, 0, 0, 0, time.UTC) // a friday
+	assert.Equal(t, time.Date(2023, time.December, 27, 12, 0, 0, 0, time.UTC), Start(beforeReference))
+}
+
+func TestNextStart(t *testing.T) {
+	friday := time.Date(2025, time.March, 7, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.March, 12, 12, 0, 0, 0, time.UTC), NextStart(friday))
+
+	epochStart := time.Date(2025, time.March, 12, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.March, 19, 12, 0, 0, 0, time.UTC), NextStart(epochStart))
+}
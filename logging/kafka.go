@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// KafkaLogger writes the log entries of the kafka client. It follows the level of the logger.
+type KafkaLogger struct {
+	logger *zap.SugaredLogger
+}
+
+// NewKafkaLogger uses the global logger. Create it after New.
+func NewKafkaLogger() *KafkaLogger {
+	return &KafkaLogger{logger: zap.S().With("component", "kafka")}
+}
+
+func (l *KafkaLogger) Level() kgo.LogLevel {
+	switch l.logger.Level() {
+	case zapcore.DebugLevel:
+		return kgo.LogLevelDebug
+	case zapcore.InfoLevel:
+		return kgo.LogLevelInfo
+	case zapcore.WarnLevel:
+		return kgo.LogLevelWarn
+	default:
+		return kgo.LogLevelError
+	}
+}
+
+func (l *KafkaLogger) Log(level kgo.LogLevel, msg string, keyvals ...any) {
+	switch level {
+	case kgo.LogLevelError:
+		l.logger.Errorw(msg, keyvals...)
+	case kgo.LogLevelWarn:
+		l.logger.Warnw(msg, keyvals...)
+	case kgo.LogLevelInfo:
+		l.logger.Infow(msg, keyvals...)
+	default:
+		l.logger.Debugw(msg, keyvals...)
+	}
+}
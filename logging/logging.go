@@ -0,0 +1,60 @@
+// Package logging creates the structured (json) logger of the services. All services of this repository use this
+// module, so that the log pipeline can rely on the field names.
+package logging
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field names. Use them as keys of the sugared logger, for example logger.Infow("Processed tick.", logging.Tick, 42).
+const (
+	Service   = "service"
+	Epoch     = "epoch"
+	Tick      = "tick"
+	Partition = "partition"
+	Offset    = "offset"
+	Error     = "error"
+	Duration  = "duration" // seconds
+)
+
+// LevelPath is the path of the endpoint, that reads (GET) and changes (PUT {"level":"debug"}) the log level.
+const LevelPath = "/log/level"
+
+// New creates the json logger and replaces the global zap loggers and the output of the standard library logger with
+// it. Every entry contains the service name.
+func New(service, level string) (*zap.SugaredLogger, zap.AtomicLevel, error) {
+	atomicLevel, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, atomicLevel, fmt.Errorf("parsing log level: %w", err)
+	}
+
+	config := zap.NewProductionConfig()
+	config.Level = atomicLevel
+	config.Sampling = nil // do not drop entries
+	config.OutputPaths = []string{"stdout"}
+	config.EncoderConfig.TimeKey = "time"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	config.EncoderConfig.EncodeDuration = zapcore.SecondsDurationEncoder
+	config.DisableStacktrace = true
+	config.InitialFields = map[string]any{Service: service}
+
+	logger, err := config.Build()
+	if err != nil {
+		return nil, atomicLevel, fmt.Errorf("building logger: %w", err)
+	}
+	zap.ReplaceGlobals(logger)
+	_, err = zap.RedirectStdLogAt(logger, zapcore.InfoLevel) // log output of libraries
+	if err != nil {
+		return nil, atomicLevel, fmt.Errorf("redirecting standard logger: %w", err)
+	}
+	return logger.Sugar(), atomicLevel, nil
+}
+
+// Handle registers the level endpoint.
+func Handle(mux *http.ServeMux, level zap.AtomicLevel) {
+	mux.Handle(LevelPath, level)
+}
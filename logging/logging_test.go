@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNew_InvalidLevel(t *testing.T) {
+	_, _, err := New("test", "verbose")
+	assert.Error(t, err)
+}
+
+func TestHandle_ChangesLevel(t *testing.T) {
+	logger, level, err := New("test", "info")
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	Handle(mux, level)
+
+	assert.False(t, logger.Desugar().Core().Enabled(zapcore.DebugLevel))
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(http.MethodPut, LevelPath, strings.NewReader(`{"level":"debug"}`)))
+	require.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, logger.Desugar().Core().Enabled(zapcore.DebugLevel))
+	assert.True(t, zap.S().Desugar().Core().Enabled(zapcore.DebugLevel), "global logger")
+
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(http.MethodGet, LevelPath, nil))
+	assert.JSONEq(t, `{"level":"debug"}`, res.Body.String())
+
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(http.MethodPut, LevelPath, strings.NewReader(`{"level":"verbose"}`)))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
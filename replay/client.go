@@ -0,0 +1,261 @@
+// Package replay consumes a range of a topic again, for example to rebuild a broken index. The consumers of this
+// repository share it.
+package replay
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/qubic/logging"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+// idlePolls is the number of empty polls after which the replay ends, although the end offsets were not reached (for
+// example because of deleted records).
+const idlePolls = 3
+
+const pollTimeout = 10 * time.Second
+
+// Config defines the records to replay. The start is either given per partition (Offsets), by time (StartTime) or
+// the beginning of the topic. The end are the end offsets at the start of the replay. The tick range can only be used
+// for topics, that are keyed by tick number.
+type Config struct {
+	Topic            string
+	Offsets          map[int32]int64 // start offsets per partition. Other partitions are not replayed.
+	StartTime        time.Time       // start with the first record at or after this time
+	FromTick         uint32          // optional. Only records of ticks within the range are replayed.
+	ToTick           uint32          // optional
+	RecordsPerSecond int             // optional rate limit
+	ProgressInterval time.Duration
+}
+
+// Client consumes the records of a topic range directly from the partitions. It does not use a consumer group, so
+// the offsets of the live consumer group are not touched. It can be used as kafka client of a consumer.
+type Client struct {
+	kcl              *kgo.Client
+	config           Config
+	end              map[int32]int64 // exclusive
+	position         map[int32]int64 // next offset to consume
+	consumed         int
+	replayed         int
+	idle             int
+	started          time.Time
+	lastProgress     time.Time
+	progressInterval time.Duration
+}
+
+type partitionOffsets map[int32]int64
+
+// NewClient calculates the start and end offsets and creates a client that consumes the partitions.
+func NewClient(ctx context.Context, seedBrokers []string, config Config) (*Client, error) {
+	adminClient, err := kgo.NewClient(kgo.SeedBrokers(seedBrokers...))
+	if err != nil {
+		return nil, fmt.Errorf("creating admin client: %w", err)
+	}
+	defer adminClient.Close()
+	admin := kadm.NewClient(adminClient)
+
+	end, err := listOffsets(admin.ListEndOffsets(ctx, config.Topic))
+	if err != nil {
+		return nil, fmt.Errorf("listing end offsets: %w", err)
+	}
+	var start partitionOffsets
+	switch {
+	case len(config.Offsets) > 0:
+		start = config.Offsets
+	case !config.StartTime.IsZero():
+		start, err = listOffsets(admin.ListOffsetsAfterMilli(ctx, config.StartTime.UnixMilli(), config.Topic))
+	default:
+		start, err = listOffsets(admin.ListStartOffsets(ctx, config.Topic))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing start offsets: %w", err)
+	}
+
+	start, end, err = offsetRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make(map[int32]kgo.Offset)
+	for partition, offset := range start {
+		partitions[partition] = kgo.NewOffset().At(offset)
+		zap.S().Infow("Replaying partition.", logging.Partition, partition, logging.Offset, offset, "endOffset", end[partition])
+	}
+
+	kcl, err := kgo.NewClient(
+		kgo.SeedBrokers(seedBrokers...),
+		kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{config.Topic: partitions}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating replay client: %w", err)
+	}
+	return newClient(kcl, config, start, end), nil
+}
+
+func newClient(kcl *kgo.Client, config Config, start, end partitionOffsets) *Client {
+	progressInterval := config.ProgressInterval
+	if progressInterval <= 0 {
+		progressInterval = 10 * time.Second
+	}
+	return &Client{
+		kcl:              kcl,
+		config:           config,
+		end:              end,
+		position:         maps.Clone(start),
+		started:          time.Now(),
+		lastProgress:     time.Now(),
+		progressInterval: progressInterval,
+	}
+}
+
+func listOffsets(listed kadm.ListedOffsets, err error) (partitionOffsets, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err = listed.Error(); err != nil {
+		return nil, err
+	}
+	offsets := make(partitionOffsets)
+	listed.Each(func(o kadm.ListedOffset) {
+		offsets[o.Partition] = o.Offset
+	})
+	return offsets, nil
+}
+
+// offsetRange removes the partitions without records to replay.
+func offsetRange(start, end partitionOffsets) (partitionOffsets, partitionOffsets, error) {
+	rangeStart, rangeEnd := make(partitionOffsets), make(partitionOffsets)
+	for partition, offset := range start {
+		endOffset, ok := end[partition]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown partition [%d]", partition)
+		}
+		if offset < 0 || offset >= endOffset {
+			continue // nothing to replay (-1 if there are no records after the start time)
+		}
+		rangeStart[partition] = offset
+		rangeEnd[partition] = endOffset
+	}
+	return rangeStart, rangeEnd, nil
+}
+
+// Done returns true, if all partitions were consumed up to the end offsets.
+func (c *Client) Done() bool {
+	if c.idle >= idlePolls {
+		zap.S().Warn("No further records. Ending replay before reaching the end offsets.")
+		return true
+	}
+	for partition, end := range c.end {
+		if c.position[partition] < end {
+			return false
+		}
+	}
+	return true
+}
+
+// PollRecords polls the next records and removes the records after the end offsets and outside the tick range.
+func (c *Client) PollRecords(ctx context.Context, maxPollRecords int) kgo.Fetches {
+	pollCtx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+	fetches := c.kcl.PollRecords(pollCtx, maxPollRecords)
+	if ctx.Err() == nil && errors.Is(fetches.Err0(), context.DeadlineExceeded) {
+		c.idle++
+		return nil
+	}
+	c.idle = 0
+
+	fetches = c.filter(fetches)
+	c.throttle()
+	c.logProgress(false)
+	return fetches
+}
+
+func (c *Client) filter(fetches kgo.Fetches) kgo.Fetches {
+	for i := range fetches {
+		for j := range fetches[i].Topics {
+			for k := range fetches[i].Topics[j].Partitions {
+				partition := &fetches[i].Topics[j].Partitions[k]
+				records := partition.Records[:0]
+				for _, record := range partition.Records {
+					if record.Offset >= c.end[partition.Partition] {
+						continue
+					}
+					c.position[partition.Partition] = record.Offset + 1
+					c.consumed++
+					if c.inTickRange(record) {
+						records = append(records, record)
+					}
+				}
+				partition.Records = records
+				c.replayed += len(records)
+			}
+		}
+	}
+	return fetches
+}
+
+// inTickRange checks the tick number of the record key (little endian).
+func (c *Client) inTickRange(record *kgo.Record) bool {
+	if c.config.FromTick == 0 && c.config.ToTick == 0 {
+		return true
+	}
+	if len(record.Key) != 4 {
+		return false
+	}
+	tick := binary.LittleEndian.Uint32(record.Key)
+	return tick >= c.config.FromTick && (c.config.ToTick == 0 || tick <= c.config.ToTick)
+}
+
+// throttle waits, until the consumed records are within the rate limit.
+func (c *Client) throttle() {
+	if c.config.RecordsPerSecond <= 0 {
+		return
+	}
+	expected := time.Duration(float64(c.consumed) / float64(c.config.RecordsPerSecond) * float64(time.Second))
+	if wait := expected - time.Since(c.started); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (c *Client) logProgress(force bool) {
+	if !force && time.Since(c.lastProgress) < c.progressInterval {
+		return
+	}
+	c.lastProgress = time.Now()
+	var remaining int64
+	for partition, end := range c.end {
+		remaining += max(end-c.position[partition], 0)
+	}
+	zap.S().Infow("Replay progress.", "consumed", c.consumed, "replayed", c.replayed, "remaining", remaining,
+		logging.Duration, time.Since(c.started))
+}
+
+// Close logs the final progress and closes the underlying client.
+func (c *Client) Close() {
+	c.logProgress(true)
+	if c.kcl != nil {
+		c.kcl.Close()
+	}
+}
+
+// CommitRecords does nothing. Replays do not commit offsets.
+func (c *Client) CommitRecords(_ context.Context, _ ...*kgo.Record) error {
+	return nil
+}
+
+// CommitUncommittedOffsets does nothing. Replays do not commit offsets.
+func (c *Client) CommitUncommittedOffsets(_ context.Context) error {
+	return nil
+}
+
+func (c *Client) CommittedOffsets() map[string]map[int32]kgo.EpochOffset {
+	return nil
+}
+
+func (c *Client) AllowRebalance() {}
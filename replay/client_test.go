@@ -0,0 +1,75 @@
+package replay
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func tickKey(tick uint32) []byte {
+	key := make([]byte, 4)
+	binary.LittleEndian.PutUint32(key, tick)
+	return key
+}
+
+func fetchesOf(partition int32, records ...*kgo.Record) kgo.Fetches {
+	return kgo.Fetches{{Topics: []kgo.FetchTopic{{
+		Topic:      "topic",
+		Partitions: []kgo.FetchPartition{{Partition: partition, Records: records}},
+	}}}}
+}
+
+func TestOffsetRange(t *testing.T) {
+	start, end, err := offsetRange(partitionOffsets{0: 10, 1: 20, 2: -1}, partitionOffsets{0: 15, 1: 20, 2: 30})
+	require.NoError(t, err)
+	assert.Equal(t, partitionOffsets{0: 10}, start)
+	assert.Equal(t, partitionOffsets{0: 15}, end)
+
+	_, _, err = offsetRange(partitionOffsets{3: 0}, partitionOffsets{0: 15})
+	require.ErrorContains(t, err, "unknown partition [3]")
+}
+
+func TestClient_Filter(t *testing.T) {
+	client := newClient(nil, Config{FromTick: 100, ToTick: 101}, partitionOffsets{0: 5}, partitionOffsets{0: 8})
+
+	fetches := client.filter(fetchesOf(0,
+		&kgo.Record{Offset: 5, Key: tickKey(99)},
+		&kgo.Record{Offset: 6, Key: tickKey(100)},
+		&kgo.Record{Offset: 7, Key: tickKey(101)},
+		&kgo.Record{Offset: 8, Key: tickKey(101)}, // after the end
+	))
+
+	var offsets []int64
+	fetches.EachRecord(func(record *kgo.Record) {
+		offsets = append(offsets, record.Offset)
+	})
+	assert.Equal(t, []int64{6, 7}, offsets)
+	assert.Equal(t, 3, client.consumed)
+	assert.Equal(t, 2, client.replayed)
+	assert.True(t, client.Done())
+}
+
+func TestClient_Done(t *testing.T) {
+	client := newClient(nil, Config{}, partitionOffsets{0: 0, 1: 0}, partitionOffsets{0: 1, 1: 1})
+	client.filter(fetchesOf(0, &kgo.Record{Offset: 0}))
+	assert.False(t, client.Done())
+	client.filter(fetchesOf(1, &kgo.Record{Offset: 0}))
+	assert.True(t, client.Done())
+
+	idle := newClient(nil, Config{}, partitionOffsets{0: 0}, partitionOffsets{0: 1})
+	idle.idle = idlePolls
+	assert.True(t, idle.Done())
+}
+
+func TestClient_InTickRange(t *testing.T) {
+	all := newClient(nil, Config{}, nil, nil)
+	assert.True(t, all.inTickRange(&kgo.Record{}))
+
+	from := newClient(nil, Config{FromTick: 10}, nil, nil)
+	assert.False(t, from.inTickRange(&kgo.Record{Key: tickKey(9)}))
+	assert.True(t, from.inTickRange(&kgo.Record{Key: tickKey(1000)}))
+	assert.False(t, from.inTickRange(&kgo.Record{Key: []byte("invalid")}))
+}
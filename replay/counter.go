@@ -0,0 +1,28 @@
+package replay
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Counter counts the documents per target index for dry runs.
+type Counter struct {
+	counts map[string]int
+}
+
+func NewCounter() *Counter {
+	return &Counter{counts: make(map[string]int)}
+}
+
+func (c *Counter) Add(index string, count int) {
+	c.counts[index] += count
+}
+
+// Print prints the counts per index, for example "qubic-tick-data: 42 documents".
+func (c *Counter) Print(out io.Writer, unit string) {
+	for _, index := range slices.Sorted(maps.Keys(c.counts)) {
+		_, _ = fmt.Fprintf(out, "%s: %d %s\n", index, c.counts[index], unit)
+	}
+}
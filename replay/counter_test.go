@@ -0,0 +1,19 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter_Print(t *testing.T) {
+	counter := NewCounter()
+	counter.Add("b", 2)
+	counter.Add("a", 1)
+	counter.Add("b", 3)
+
+	var out strings.Builder
+	counter.Print(&out, "documents")
+	assert.Equal(t, "a: 1 documents\nb: 5 documents\n", out.String())
+}
@@ -0,0 +1,72 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Usage describes the replay command.
+const Usage = `usage:
+  replay    consume the topic range defined by the --replay-* options`
+
+// Options are the command line options of the replay command. Embed them into the replay section of the service
+// config.
+type Options struct {
+	Offsets          []string      `conf:"optional"`      // start offsets per partition (partition:offset)
+	StartTime        string        `conf:"optional"`      // start time (RFC 3339)
+	RecordsPerSecond int           `conf:"optional"`      // rate limit
+	ProgressInterval time.Duration `conf:"default:10s"`   // interval for progress logging
+	DryRun           bool          `conf:"default:false"` // only count the documents per target index
+}
+
+// TickRangeOptions limit the replay to a tick range. Only for topics, that are keyed by tick number.
+type TickRangeOptions struct {
+	FromTick uint32 `conf:"optional"` // first tick to replay
+	ToTick   uint32 `conf:"optional"` // last tick to replay
+}
+
+// NewConfig converts the command line options.
+func NewConfig(topic string, options Options, ticks TickRangeOptions) (Config, error) {
+	config := Config{
+		Topic:            topic,
+		FromTick:         ticks.FromTick,
+		ToTick:           ticks.ToTick,
+		RecordsPerSecond: options.RecordsPerSecond,
+		ProgressInterval: options.ProgressInterval,
+	}
+	if ticks.ToTick > 0 && ticks.FromTick > ticks.ToTick {
+		return config, fmt.Errorf("invalid tick range [%d-%d]", ticks.FromTick, ticks.ToTick)
+	}
+	if len(options.Offsets) > 0 && options.StartTime != "" {
+		return config, errors.New("offsets and start time are mutually exclusive")
+	}
+	if options.StartTime != "" {
+		startTime, err := time.Parse(time.RFC3339, options.StartTime)
+		if err != nil {
+			return config, fmt.Errorf("parsing start time: %w", err)
+		}
+		config.StartTime = startTime
+	}
+	if len(options.Offsets) > 0 {
+		config.Offsets = make(map[int32]int64)
+		for _, option := range options.Offsets {
+			partition, offset, ok := strings.Cut(option, ":")
+			if !ok {
+				return config, fmt.Errorf("invalid partition offset [%s]", option)
+			}
+			p, err := strconv.ParseInt(partition, 10, 32)
+			if err != nil {
+				return config, fmt.Errorf("parsing partition of [%s]: %w", option, err)
+			}
+			o, err := strconv.ParseInt(offset, 10, 64)
+			if err != nil {
+				return config, fmt.Errorf("parsing offset of [%s]: %w", option, err)
+			}
+			config.Offsets[int32(p)] = o
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,42 @@
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig(t *testing.T) {
+	config, err := NewConfig("topic", Options{Offsets: []string{"0:1200", "1:1180"}, RecordsPerSecond: 100},
+		TickRangeOptions{FromTick: 10, ToTick: 20})
+	require.NoError(t, err)
+	assert.Equal(t, Config{Topic: "topic", Offsets: map[int32]int64{0: 1200, 1: 1180}, FromTick: 10, ToTick: 20,
+		RecordsPerSecond: 100}, config)
+
+	config, err = NewConfig("topic", Options{StartTime: "2025-04-14T00:00:00Z"}, TickRangeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.April, 14, 0, 0, 0, 0, time.UTC), config.StartTime)
+}
+
+func TestNewConfig_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		ticks   TickRangeOptions
+	}{
+		{name: "invalid tick range", ticks: TickRangeOptions{FromTick: 20, ToTick: 10}},
+		{name: "offsets and start time", options: Options{Offsets: []string{"0:1"}, StartTime: "2025-04-14T00:00:00Z"}},
+		{name: "invalid start time", options: Options{StartTime: "yesterday"}},
+		{name: "missing offset", options: Options{Offsets: []string{"0"}}},
+		{name: "invalid partition", options: Options{Offsets: []string{"a:1"}}},
+		{name: "invalid offset", options: Options{Offsets: []string{"0:a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConfig("topic", tt.options, tt.ticks)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/qubic/schnorrq
+
+go 1.26
+
+require (
+	github.com/cloudflare/circl v1.6.3
+	github.com/qubic/go-qubic v0.3.5
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.30.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,68 @@
+// Package schnorrq verifies SchnorrQ signatures of the qubic network. go-qubic does not provide signature
+// verification, so the services share this implementation.
+package schnorrq
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/cloudflare/circl/ecc/fourq"
+	"github.com/qubic/go-qubic/common"
+)
+
+// cofactor of the FourQ curve. The point multiplication of the curve library clears the cofactor, so the scalar
+// needs to be multiplied with the inverse of the cofactor.
+const cofactor = 392
+
+var (
+	curveOrder      = fourq.Params().N
+	inverseCofactor = new(big.Int).ModInverse(big.NewInt(cofactor), curveOrder)
+)
+
+// Verify verifies the SchnorrQ signature (R || s) of the digest like the qubic core does. The signature is valid, if
+// s*G + h*A == R with h = K12(R || A || digest).
+func Verify(publicKey [32]byte, digest [32]byte, signature [64]byte) error {
+	if publicKey[15]&0x80 != 0 || signature[15]&0x80 != 0 || signature[62]&0xC0 != 0 || signature[63] != 0 {
+		return fmt.Errorf("invalid encoding")
+	}
+
+	var a fourq.Point
+	if !a.Unmarshal(&publicKey) {
+		return fmt.Errorf("decoding public key")
+	}
+
+	// the qubic core calculates a 64 byte digest but only uses the first 32 bytes as scalar
+	h, err := common.K12Hash(slices.Concat(signature[:32], publicKey[:], digest[:]))
+	if err != nil {
+		return fmt.Errorf("hashing signature data: %w", err)
+	}
+	s := toScalar(fromLittleEndian(signature[32:]))
+	hScalar := toScalar(new(big.Int).Mul(fromLittleEndian(h[:]), inverseCofactor))
+
+	var sG, hA, r fourq.Point
+	sG.ScalarBaseMult(&s)
+	hA.ScalarMult(&hScalar, &a)
+	r.Add(&sG, &hA)
+
+	var encoded [32]byte
+	r.Marshal(&encoded)
+	if encoded != [32]byte(signature[:32]) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
+
+func fromLittleEndian(data []byte) *big.Int {
+	bigEndian := slices.Clone(data)
+	slices.Reverse(bigEndian)
+	return new(big.Int).SetBytes(bigEndian)
+}
+
+// toScalar reduces the value modulo the curve order and encodes it little endian.
+func toScalar(value *big.Int) [32]byte {
+	var scalar [32]byte
+	new(big.Int).Mod(value, curveOrder).FillBytes(scalar[:])
+	slices.Reverse(scalar[:])
+	return scalar
+}
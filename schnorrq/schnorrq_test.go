@@ -0,0 +1,78 @@
+package schnorrq
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testVector struct {
+	publicKey string
+	digest    string
+	signature string
+}
+
+// signatures from the qubic network
+var (
+	// transaction czxyxioyrhtkbbinsnhoieectcugxmbscizlynmaieilqhmnwojaekdczaki (tick 23582758) signed by
+	// FZTXBUWQTOWAHBODSZKVMUQRRPDDASKDOQLSDGLIUCVWDSYWIBAKAXRBKEJJ
+	transactionSignature = testVector{
+		publicKey: "e3e9a263c45dbf2b0c467fffba08126cd41761ed4b3dc85fcd2745c5a7781e3a",
+		digest:    "062ff5a6205708c368903ae7d059413dc3f1785037c6d6332c3436507fb4ef4a",
+		signature: "234a4503b71b3e81092c9bacc2bc2436de49097ee94f947f6ff46c583fd62cad8d9c0086ce229dd4301b2b9c5a1ed9511daa6f3cae3b2375cbe98aefac920300",
+	}
+	// computors list of epoch 168 signed by the arbitrator AFZPUAIYVPNUYGJRQVLUKOPPVLHAZQTGLYAAUUNBXFTVTAMSBKQBLEIEPCVJ
+	computorsSignature = testVector{
+		publicKey: "9e1a100cfb556def7bcc6252e47ddf0985428637c3d1b3caa16f33fd98438d94",
+		digest:    "7368f468f14d79f2b842fb8e8f0a50e59aacdd112e482b322703e00b6d0a7c1e",
+		signature: "5af6bfc3da82a0dfbec9abddd373c02b8060c047a3e53a78f4676bb57a544f4b5a557e2822d061b5a5e5348fea1f5ef26c76138f705aa0d6e8728dec35c02400",
+	}
+)
+
+func decode(t *testing.T, vector testVector) ([32]byte, [32]byte, [64]byte) {
+	publicKey, err := hex.DecodeString(vector.publicKey)
+	require.NoError(t, err)
+	digest, err := hex.DecodeString(vector.digest)
+	require.NoError(t, err)
+	signature, err := hex.DecodeString(vector.signature)
+	require.NoError(t, err)
+	return [32]byte(publicKey), [32]byte(digest), [64]byte(signature)
+}
+
+func TestVerify(t *testing.T) {
+	for _, vector := range []testVector{transactionSignature, computorsSignature} {
+		publicKey, digest, signature := decode(t, vector)
+		require.NoError(t, Verify(publicKey, digest, signature))
+	}
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	publicKey, digest, signature := decode(t, transactionSignature)
+	otherPublicKey, otherDigest, otherSignature := decode(t, computorsSignature)
+
+	tests := []struct {
+		name      string
+		publicKey [32]byte
+		digest    [32]byte
+		signature [64]byte
+	}{
+		{name: "other public key", publicKey: otherPublicKey, digest: digest, signature: signature},
+		{name: "other digest", publicKey: publicKey, digest: otherDigest, signature: signature},
+		{name: "other signature", publicKey: publicKey, digest: digest, signature: otherSignature},
+		{name: "modified r", publicKey: publicKey, digest: digest, signature: flipBit(signature, 3)},
+		{name: "modified s", publicKey: publicKey, digest: digest, signature: flipBit(signature, 40)},
+		{name: "invalid encoding", publicKey: publicKey, digest: digest, signature: flipBit(signature, 63)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, Verify(tt.publicKey, tt.digest, tt.signature))
+		})
+	}
+}
+
+func flipBit(signature [64]byte, index int) [64]byte {
+	signature[index] ^= 0x01
+	return signature
+}
@@ -0,0 +1,125 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
+)
+
+// ElasticClient sends documents with the bulk indexer of the official elasticsearch client.
+type ElasticClient struct {
+	esClient *elasticsearch.Client
+}
+
+func NewElasticClient(esClient *elasticsearch.Client) *ElasticClient {
+	return &ElasticClient{
+		esClient: esClient,
+	}
+}
+
+func (c *ElasticClient) BulkIndex(ctx context.Context, data []Document, indexName string) error {
+	return c.bulk(ctx, "index", data, indexName, "")
+}
+
+// BulkIndexAndWait waits for the refresh (refresh=wait_for), so that the documents are visible to searches.
+func (c *ElasticClient) BulkIndexAndWait(ctx context.Context, data []Document, indexName string) error {
+	return c.bulk(ctx, "index", data, indexName, "wait_for")
+}
+
+// BulkUpdate sends update requests. The payloads are the update request bodies (partial document or script).
+func (c *ElasticClient) BulkUpdate(ctx context.Context, data []Document, indexName string) error {
+	return c.bulk(ctx, "update", data, indexName, "")
+}
+
+func (c *ElasticClient) Search(ctx context.Context, indexName string, query string, result any) error {
+	res, err := c.esClient.Search(
+		c.esClient.Search.WithContext(ctx),
+		c.esClient.Search.WithIndex(indexName),
+		c.esClient.Search.WithBody(strings.NewReader(query)),
+	)
+	if err != nil {
+		return fmt.Errorf("performing search: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("got error response from elastic: %s", res.String())
+	}
+	err = json.NewDecoder(res.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func (c *ElasticClient) bulk(ctx context.Context, action string, data []Document, indexName, refresh string) error {
+	start := time.Now().UnixMilli()
+	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Index:      indexName,
+		Client:     c.esClient,
+		NumWorkers: min(runtime.NumCPU(), 8), // 8 parallel connections are enough
+		Refresh:    refresh,
+	})
+	if err != nil {
+		return fmt.Errorf("creating bulk indexer: %w", err)
+	}
+
+	for _, d := range data {
+		item := esutil.BulkIndexerItem{
+			Action:       action,
+			DocumentID:   d.Id,
+			RequireAlias: true,
+			Body:         bytes.NewReader(d.Payload),
+			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+				if err == nil {
+					err = fmt.Errorf("%s: %s", res.Error.Type, res.Error.Reason)
+				}
+				zap.S().Errorw("Processing document failed.", "action", action, "id", d.Id, "payload", string(d.Payload),
+					logging.Error, err)
+			},
+		}
+		if action == "update" {
+			retries := retryOnConflict
+			item.RetryOnConflict = &retries
+		}
+		err = bi.Add(ctx, item)
+		if err != nil {
+			_ = bi.Close(ctx)
+			return fmt.Errorf("adding document: %w", err)
+		}
+	}
+
+	err = bi.Close(ctx)
+	if err != nil {
+		return fmt.Errorf("closing bulk indexer: %w", err)
+	}
+
+	biStats := bi.Stats()
+	end := time.Now().UnixMilli()
+	if biStats.NumFailed > 0 {
+		return fmt.Errorf("%d errors processing [%d] documents (%s)",
+			biStats.NumFailed,
+			biStats.NumFlushed,
+			action,
+		)
+	} else {
+		zap.S().Infow("Processed documents.",
+			"documents", biStats.NumFlushed,
+			"action", action,
+			"bytes", biStats.FlushedBytes,
+			"requests", biStats.NumRequests,
+			"index", indexName,
+			logging.Duration, time.Duration(end-start)*time.Millisecond,
+		)
+	}
+	return nil
+}
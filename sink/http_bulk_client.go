@@ -0,0 +1,333 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
+)
+
+const defaultMaxRequestBytes = 5 * 1024 * 1024
+
+type HttpBulkConfig struct {
+	Sink            string   // opensearch or ndjson
+	Addresses       []string // base urls. The first one is used.
+	BulkUrl         string   // optional. Defaults to <address>/_bulk.
+	Username        string
+	Password        string
+	CACert          []byte
+	MaxRetries      int
+	RetryBackoff    func(attempt int) time.Duration
+	MaxRequestBytes int // requests are split, if the payload gets larger
+}
+
+// Capabilities of the target, detected at startup.
+type Capabilities struct {
+	Distribution string // opensearch or unknown
+	Version      string
+	RequireAlias bool // supports the require_alias parameter
+}
+
+// HttpBulkClient sends documents with the bulk api format over plain http. It does not depend on the product checks
+// of the elasticsearch client.
+type HttpBulkClient struct {
+	httpClient   *http.Client
+	config       HttpBulkConfig
+	bulkUrl      string
+	waitUrl      string // bulk url, that waits for the refresh
+	capabilities Capabilities
+}
+
+func NewHttpBulkClient(ctx context.Context, config HttpBulkConfig) (*HttpBulkClient, error) {
+	if len(config.Addresses) == 0 && config.BulkUrl == "" {
+		return nil, errors.New("missing address")
+	}
+	if config.MaxRequestBytes <= 0 {
+		config.MaxRequestBytes = defaultMaxRequestBytes
+	}
+	if config.RetryBackoff == nil {
+		config.RetryBackoff = func(attempt int) time.Duration { return time.Duration(attempt) * time.Second }
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(config.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CACert) {
+			return nil, errors.New("invalid ca certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	client := &HttpBulkClient{
+		httpClient: &http.Client{Transport: transport, Timeout: time.Minute},
+		config:     config,
+		bulkUrl:    config.BulkUrl,
+	}
+	if client.bulkUrl == "" {
+		client.bulkUrl = strings.TrimSuffix(config.Addresses[0], "/") + "/_bulk"
+	}
+	waitUrl, err := url.Parse(client.bulkUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bulk url: %w", err)
+	}
+	query := waitUrl.Query()
+	query.Set("refresh", "wait_for")
+	waitUrl.RawQuery = query.Encode()
+	client.waitUrl = waitUrl.String()
+
+	capabilities, err := client.detectCapabilities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("detecting capabilities: %w", err)
+	}
+	client.capabilities = capabilities
+	zap.S().Infow("Using http bulk sink.", "sink", config.Sink, "url", client.bulkUrl, "distribution", capabilities.Distribution,
+		"version", capabilities.Version, "requireAlias", capabilities.RequireAlias)
+	return client, nil
+}
+
+func (c *HttpBulkClient) Capabilities() Capabilities {
+	return c.capabilities
+}
+
+// detectCapabilities reads the cluster info. OpenSearch clusters need to identify as OpenSearch. Generic endpoints
+// do not need to provide cluster info.
+func (c *HttpBulkClient) detectCapabilities(ctx context.Context) (Capabilities, error) {
+	capabilities := Capabilities{Distribution: "unknown"}
+	if c.config.Sink == Ndjson && len(c.config.Addresses) == 0 {
+		return capabilities, nil
+	}
+
+	var info struct {
+		Version struct {
+			Distribution string `json:"distribution"`
+			Number       string `json:"number"`
+		} `json:"version"`
+	}
+	err := c.request(ctx, http.MethodGet, c.address()+"/", nil, &info)
+	switch {
+	case err != nil && c.config.Sink == Ndjson:
+		zap.S().Warnw("No cluster info available.", logging.Error, err)
+		return capabilities, nil
+	case err != nil:
+		return capabilities, err
+	}
+
+	if info.Version.Distribution == OpenSearch {
+		capabilities.Distribution = OpenSearch
+		capabilities.Version = info.Version.Number
+		capabilities.RequireAlias = true // all versions are based on elasticsearch 7.10, that supports require_alias
+	}
+	if c.config.Sink == OpenSearch && capabilities.Distribution != OpenSearch {
+		return capabilities, fmt.Errorf("target is not an OpenSearch cluster (version [%s])", info.Version.Number)
+	}
+	return capabilities, nil
+}
+
+// Search sends the query to the search api of the index. Needs an address, the bulk url is not enough.
+func (c *HttpBulkClient) Search(ctx context.Context, indexName string, query string, result any) error {
+	if len(c.config.Addresses) == 0 {
+		return errors.New("search needs an address")
+	}
+	return c.request(ctx, http.MethodPost, c.address()+"/"+url.PathEscape(indexName)+"/_search", []byte(query), result)
+}
+
+func (c *HttpBulkClient) address() string {
+	return strings.TrimSuffix(c.config.Addresses[0], "/")
+}
+
+func (c *HttpBulkClient) request(ctx context.Context, method, url string, body []byte, target any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authenticate(req)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("unexpected status [%d]: %s", res.StatusCode, string(responseBody))
+	}
+	err = json.NewDecoder(res.Body).Decode(target)
+	if err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func (c *HttpBulkClient) authenticate(req *http.Request) {
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+}
+
+func (c *HttpBulkClient) BulkIndex(ctx context.Context, data []Document, indexName string) error {
+	return c.bulk(ctx, "index", data, indexName, c.bulkUrl)
+}
+
+// BulkIndexAndWait waits for the refresh (refresh=wait_for), so that the documents are visible to searches.
+func (c *HttpBulkClient) BulkIndexAndWait(ctx context.Context, data []Document, indexName string) error {
+	return c.bulk(ctx, "index", data, indexName, c.waitUrl)
+}
+
+// BulkUpdate sends update requests. The payloads are the update request bodies (partial document or script).
+func (c *HttpBulkClient) BulkUpdate(ctx context.Context, data []Document, indexName string) error {
+	return c.bulk(ctx, "update", data, indexName, c.bulkUrl)
+}
+
+type bulkAction struct {
+	Index           string `json:"_index"`
+	Id              string `json:"_id"`
+	RequireAlias    bool   `json:"require_alias,omitempty"`
+	RetryOnConflict int    `json:"retry_on_conflict,omitempty"`
+}
+
+type bulkResponse struct {
+	Errors bool                                `json:"errors"`
+	Items  []map[string]bulkResponseItemResult `json:"items"`
+}
+
+type bulkResponseItemResult struct {
+	Id     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+func (c *HttpBulkClient) bulk(ctx context.Context, action string, data []Document, indexName, bulkUrl string) error {
+	start := time.Now()
+	var body bytes.Buffer
+	requests, failed, flushed := 0, 0, 0
+	flush := func() error {
+		if body.Len() == 0 {
+			return nil
+		}
+		count, err := c.send(ctx, bulkUrl, body.Bytes())
+		if err != nil {
+			return err
+		}
+		requests++
+		failed += count
+		body.Reset()
+		return nil
+	}
+
+	for _, d := range data {
+		meta := bulkAction{Index: indexName, Id: d.Id, RequireAlias: c.capabilities.RequireAlias}
+		if action == "update" {
+			meta.RetryOnConflict = retryOnConflict
+		}
+		line, err := json.Marshal(map[string]bulkAction{action: meta})
+		if err != nil {
+			return fmt.Errorf("marshalling bulk action: %w", err)
+		}
+		if body.Len() > 0 && body.Len()+len(line)+len(d.Payload)+2 > c.config.MaxRequestBytes {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+		body.Write(bytes.TrimSpace(d.Payload)) // must not contain newlines
+		body.WriteByte('\n')
+		flushed++
+	}
+	err := flush()
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d errors processing [%d] documents (%s)", failed, flushed, action)
+	}
+	zap.S().Infow("Processed documents.", "documents", flushed, "action", action, "requests", requests,
+		"index", indexName, logging.Duration, time.Since(start))
+	return nil
+}
+
+// send sends one bulk request and returns the number of failed items. Retries on overload and gateway errors.
+func (c *HttpBulkClient) send(ctx context.Context, bulkUrl string, body []byte) (int, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, bulkUrl, bytes.NewReader(body))
+		if err != nil {
+			return 0, fmt.Errorf("creating bulk request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		c.authenticate(req)
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("sending bulk request: %w", err)
+		}
+		responseBody, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("reading bulk response: %w", err)
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			return countFailedItems(responseBody)
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if attempt > c.config.MaxRetries {
+				return 0, fmt.Errorf("bulk request failed with status [%d] after [%d] attempts", res.StatusCode, attempt)
+			}
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(c.config.RetryBackoff(attempt)):
+			}
+		default:
+			return 0, fmt.Errorf("bulk request failed with status [%d]: %s", res.StatusCode, string(responseBody))
+		}
+	}
+}
+
+// countFailedItems parses the bulk response. Generic endpoints may respond without items.
+func countFailedItems(body []byte) (int, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return 0, nil
+	}
+	var response bulkResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return 0, fmt.Errorf("decoding bulk response: %w", err)
+	}
+	if !response.Errors {
+		return 0, nil
+	}
+	failed := 0
+	for _, item := range response.Items {
+		for action, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+			failed++
+			if result.Error != nil {
+				zap.S().Errorw("Processing document failed.", "action", action, "id", result.Id,
+					logging.Error, result.Error.Type+": "+result.Error.Reason)
+			} else {
+				zap.S().Errorw("Processing document failed.", "action", action, "id", result.Id, "status", result.Status)
+			}
+		}
+	}
+	return failed, nil
+}
@@ -0,0 +1,307 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bulkItem struct {
+	action string
+	meta   bulkAction
+	source json.RawMessage
+}
+
+// fakeBulkServer is a stand-in for a bulk api. It validates the ndjson format of the requests.
+type fakeBulkServer struct {
+	t            *testing.T
+	distribution string // empty for a generic endpoint without cluster info
+	statuses     []int  // status codes of the next bulk requests. Default 200.
+	failedIds    map[string]struct{}
+	mu           sync.Mutex
+	requests     [][]bulkItem
+	refreshes    []string // refresh parameter per bulk request
+	searches     []string // bodies of the search requests
+}
+
+func (s *fakeBulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		if s.distribution == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":{"distribution":"` + s.distribution + `","number":"2.11.0"}}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+		assert.Equal(s.t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		user, password, _ := r.BasicAuth()
+		assert.Equal(s.t, "user", user)
+		assert.Equal(s.t, "secret", password)
+		items := s.parse(r.Body)
+		s.requests = append(s.requests, items)
+		s.refreshes = append(s.refreshes, r.URL.Query().Get("refresh"))
+
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+		}
+		s.respond(w, items)
+	case r.Method == http.MethodPost && r.URL.Path == "/test-index/_search":
+		assert.Equal(s.t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(s.t, err)
+		s.searches = append(s.searches, string(body))
+		_, _ = w.Write([]byte(`{"hits":{"hits":[{"_source":{"epoch":123}}]}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// parse checks that the body consists of action and source line pairs, each terminated by a newline.
+func (s *fakeBulkServer) parse(body io.Reader) []bulkItem {
+	data, err := io.ReadAll(body)
+	require.NoError(s.t, err)
+	require.True(s.t, bytes.HasSuffix(data, []byte("\n")), "body needs to end with a newline")
+
+	var items []bulkItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var action map[string]bulkAction
+		require.NoError(s.t, json.Unmarshal(scanner.Bytes(), &action))
+		require.Len(s.t, action, 1)
+		require.True(s.t, scanner.Scan(), "missing source line")
+		source := json.RawMessage(bytes.Clone(scanner.Bytes()))
+		require.True(s.t, json.Valid(source), "invalid source line")
+		for name, meta := range action {
+			items = append(items, bulkItem{action: name, meta: meta, source: source})
+		}
+	}
+	return items
+}
+
+func (s *fakeBulkServer) respond(w http.ResponseWriter, items []bulkItem) {
+	var response bulkResponse
+	for _, item := range items {
+		result := bulkResponseItemResult{Id: item.meta.Id, Status: http.StatusCreated}
+		if _, ok := s.failedIds[item.meta.Id]; ok {
+			response.Errors = true
+			result.Status = http.StatusBadRequest
+		}
+		response.Items = append(response.Items, map[string]bulkResponseItemResult{item.action: result})
+	}
+	data, err := json.Marshal(response)
+	require.NoError(s.t, err)
+	_, _ = w.Write(data)
+}
+
+func newTestBulkClient(t *testing.T, server *fakeBulkServer, sink string, maxRequestBytes int) (*HttpBulkClient, error) {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewHttpBulkClient(context.Background(), HttpBulkConfig{
+		Sink:            sink,
+		Addresses:       []string{httpServer.URL},
+		Username:        "user",
+		Password:        "secret",
+		MaxRetries:      2,
+		RetryBackoff:    func(int) time.Duration { return time.Millisecond },
+		MaxRequestBytes: maxRequestBytes,
+	})
+}
+
+func TestHttpBulkClient_DetectCapabilities(t *testing.T) {
+	tests := []struct {
+		name         string
+		sink         string
+		distribution string
+		expected     Capabilities
+		wantErr      bool
+	}{
+		{name: "opensearch", sink: OpenSearch, distribution: "opensearch",
+			expected: Capabilities{Distribution: "opensearch", Version: "2.11.0", RequireAlias: true}},
+		{name: "opensearch sink with other cluster", sink: OpenSearch, distribution: "other", wantErr: true},
+		{name: "opensearch sink without cluster info", sink: OpenSearch, wantErr: true},
+		{name: "ndjson without cluster info", sink: Ndjson,
+			expected: Capabilities{Distribution: "unknown"}},
+		{name: "ndjson with opensearch", sink: Ndjson, distribution: "opensearch",
+			expected: Capabilities{Distribution: "opensearch", Version: "2.11.0", RequireAlias: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newTestBulkClient(t, &fakeBulkServer{t: t, distribution: tt.distribution}, tt.sink, 0)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, client.Capabilities())
+		})
+	}
+}
+
+func TestHttpBulkClient_BulkIndex(t *testing.T) {
+	server := &fakeBulkServer{t: t, distribution: "opensearch"}
+	client, err := newTestBulkClient(t, server, OpenSearch, 0)
+	require.NoError(t, err)
+
+	err = client.BulkIndex(context.Background(), []Document{
+		{Id: "a", Payload: []byte(`{"hash":"a"}`)},
+		{Id: "b", Payload: []byte("{\"hash\":\"b\"}\n")},
+	}, "test-index")
+	require.NoError(t, err)
+
+	require.Len(t, server.requests, 1)
+	assert.Equal(t, []bulkItem{
+		{action: "index", meta: bulkAction{Index: "test-index", Id: "a", RequireAlias: true}, source: json.RawMessage(`{"hash":"a"}`)},
+		{action: "index", meta: bulkAction{Index: "test-index", Id: "b", RequireAlias: true}, source: json.RawMessage(`{"hash":"b"}`)},
+	}, server.requests[0])
+}
+
+func TestHttpBulkClient_BulkUpdate(t *testing.T) {
+	server := &fakeBulkServer{t: t}
+	client, err := newTestBulkClient(t, server, Ndjson, 0)
+	require.NoError(t, err)
+
+	err = client.BulkUpdate(context.Background(), []Document{{Id: "a", Payload: []byte(`{"doc":{"x":1}}`)}}, "test-index")
+	require.NoError(t, err)
+
+	require.Len(t, server.requests, 1)
+	assert.Equal(t, []bulkItem{
+		{action: "update", meta: bulkAction{Index: "test-index", Id: "a", RetryOnConflict: retryOnConflict}, source: json.RawMessage(`{"doc":{"x":1}}`)},
+	}, server.requests[0])
+}
+
+func TestHttpBulkClient_BulkIndex_SplitRequests(t *testing.T) {
+	server := &fakeBulkServer{t: t}
+	client, err := newTestBulkClient(t, server, Ndjson, 120) // two documents per request
+	require.NoError(t, err)
+
+	var documents []Document
+	for _, id := range []string{"a", "b", "c"} {
+		documents = append(documents, Document{Id: id, Payload: []byte(`{"hash":"` + id + `"}`)})
+	}
+	err = client.BulkIndex(context.Background(), documents, "test-index")
+	require.NoError(t, err)
+
+	require.Len(t, server.requests, 2)
+	assert.Len(t, server.requests[0], 2)
+	assert.Len(t, server.requests[1], 1)
+}
+
+func TestHttpBulkClient_BulkIndex_ItemErrors(t *testing.T) {
+	server := &fakeBulkServer{t: t, failedIds: map[string]struct{}{"b": {}}}
+	client, err := newTestBulkClient(t, server, Ndjson, 0)
+	require.NoError(t, err)
+
+	err = client.BulkIndex(context.Background(), []Document{
+		{Id: "a", Payload: []byte(`{}`)},
+		{Id: "b", Payload: []byte(`{}`)},
+	}, "test-index")
+	require.ErrorContains(t, err, "1 errors processing [2] documents")
+}
+
+func TestHttpBulkClient_BulkIndex_Retries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		wantErr  bool
+	}{
+		{name: "retry on overload", statuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, requests: 3},
+		{name: "give up after max retries", statuses: []int{502, 502, 502}, requests: 3, wantErr: true},
+		{name: "no retry on client error", statuses: []int{http.StatusBadRequest}, requests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeBulkServer{t: t, statuses: tt.statuses}
+			client, err := newTestBulkClient(t, server, Ndjson, 0)
+			require.NoError(t, err)
+
+			err = client.BulkIndex(context.Background(), []Document{{Id: "a", Payload: []byte(`{}`)}}, "test-index")
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, server.requests, tt.requests)
+		})
+	}
+}
+
+func TestHttpBulkClient_BulkIndexAndWait(t *testing.T) {
+	server := &fakeBulkServer{t: t}
+	client, err := newTestBulkClient(t, server, Ndjson, 0)
+	require.NoError(t, err)
+
+	documents := []Document{{Id: "a", Payload: []byte(`{}`)}}
+	require.NoError(t, client.BulkIndexAndWait(context.Background(), documents, "test-index"))
+	require.NoError(t, client.BulkIndex(context.Background(), documents, "test-index"))
+
+	assert.Equal(t, []string{"wait_for", ""}, server.refreshes)
+}
+
+func TestHttpBulkClient_Search(t *testing.T) {
+	server := &fakeBulkServer{t: t, distribution: "opensearch"}
+	client, err := newTestBulkClient(t, server, OpenSearch, 0)
+	require.NoError(t, err)
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source struct {
+					Epoch uint32 `json:"epoch"`
+				} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	err = client.Search(context.Background(), "test-index", `{"query":{"term":{"epoch":123}}}`, &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`{"query":{"term":{"epoch":123}}}`}, server.searches)
+	require.Len(t, result.Hits.Hits, 1)
+	assert.Equal(t, uint32(123), result.Hits.Hits[0].Source.Epoch)
+
+	err = client.Search(context.Background(), "unknown-index", `{}`, &result)
+	assert.ErrorContains(t, err, "unexpected status [404]")
+}
+
+func TestHttpBulkClient_Search_NeedsAddress(t *testing.T) {
+	server := httptest.NewServer(&fakeBulkServer{t: t})
+	t.Cleanup(server.Close)
+	client, err := NewHttpBulkClient(context.Background(), HttpBulkConfig{Sink: Ndjson, BulkUrl: server.URL + "/_bulk"})
+	require.NoError(t, err)
+
+	var result any
+	assert.ErrorContains(t, client.Search(context.Background(), "test-index", `{}`, &result), "needs an address")
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := NewClient(context.Background(), nil, HttpBulkConfig{Sink: Elasticsearch})
+	require.NoError(t, err)
+	assert.IsType(t, &ElasticClient{}, client)
+
+	server := httptest.NewServer(&fakeBulkServer{t: t})
+	t.Cleanup(server.Close)
+	client, err = NewClient(context.Background(), nil, HttpBulkConfig{Sink: Ndjson, Addresses: []string{server.URL}})
+	require.NoError(t, err)
+	assert.IsType(t, &HttpBulkClient{}, client)
+
+	_, err = NewClient(context.Background(), nil, HttpBulkConfig{Sink: "unknown"})
+	assert.ErrorContains(t, err, "unknown sink")
+}
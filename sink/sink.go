@@ -0,0 +1,52 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// Sinks for the documents.
+const (
+	Elasticsearch = "elasticsearch" // official elasticsearch client
+	OpenSearch    = "opensearch"    // bulk api of an OpenSearch cluster
+	Ndjson        = "ndjson"        // any http endpoint, that accepts the bulk format (newline delimited json)
+)
+
+// retryOnConflict is needed for updates, because the bulk requests can update the same document in parallel.
+const retryOnConflict = 5
+
+type Document struct {
+	Id      string
+	Payload []byte
+}
+
+// Client writes documents with the bulk api and reads them with the search api.
+type Client interface {
+	// BulkIndex creates or replaces the documents.
+	BulkIndex(ctx context.Context, data []Document, indexName string) error
+	// BulkIndexAndWait creates or replaces the documents and waits until they are visible to searches.
+	BulkIndexAndWait(ctx context.Context, data []Document, indexName string) error
+	// BulkUpdate sends update requests. The payloads are the update request bodies (partial document or script).
+	BulkUpdate(ctx context.Context, data []Document, indexName string) error
+	// Search sends the query to the search api of the index and decodes the response into the result.
+	Search(ctx context.Context, indexName string, query string, result any) error
+}
+
+// NewClient creates the client of the configured sink. The elasticsearch client is only used by the elasticsearch
+// sink. The other sinks use the http bulk config.
+func NewClient(ctx context.Context, esClient *elasticsearch.Client, config HttpBulkConfig) (Client, error) {
+	switch config.Sink {
+	case Elasticsearch:
+		return NewElasticClient(esClient), nil
+	case OpenSearch, Ndjson:
+		client, err := NewHttpBulkClient(ctx, config)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s client: %w", config.Sink, err)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown sink [%s]", config.Sink)
+	}
+}
@@ -6,10 +6,11 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 
 	archiverproto "github.com/qubic/go-archiver/protobuff"
 	"github.com/qubic/go-data-publisher/status-service/domain"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -55,9 +56,9 @@ func (c *Client) GetTickData(ctx context.Context, tickNumber uint32) (*domain.Ti
 		return nil, errors.New("nil tick data response")
 	}
 	if response.GetTickData() == nil {
-		log.Printf("[INFO] Archiver tick [%d] is empty.", tickNumber)
+		zap.S().Infow("Archiver tick is empty.", logging.Tick, tickNumber)
 	} else if response.GetTickData().GetTransactionIds() == nil { // it's ok to call this on nil
-		log.Printf("[INFO] Archiver tick [%d] has no transactions.", tickNumber)
+		zap.S().Infow("Archiver tick has no transactions.", logging.Tick, tickNumber)
 	}
 	convertedTd, err := convertTickData(response.GetTickData())
 	if err != nil {
@@ -6,7 +6,6 @@ import (
 	"encoding/gob"
 	"fmt"
 	"io"
-	"log"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -15,6 +14,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/qubic/go-data-publisher/status-service/domain"
 	"github.com/qubic/go-data-publisher/status-service/util"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 var ErrNotFound = errors.New("store resource not found")
@@ -122,7 +123,7 @@ func (ps *PebbleStore) loadSkippedTicksSet() (map[string]bool, error) {
 	err := ps.load(skippedTicksKey, &result)
 	if err != nil {
 		if errors.Is(err, pebble.ErrNotFound) {
-			log.Println("[WARN] Skipped ticks not found.")
+			zap.S().Warn("Skipped ticks not found.")
 			return util.NewSet(), nil
 		} else {
 			return nil, err
@@ -157,7 +158,7 @@ func (ps *PebbleStore) load(keyStr string, target any) error {
 	defer func(closer io.Closer) {
 		err = closer.Close()
 		if err != nil {
-			log.Printf("[WARN] closing db get in load: %v", err)
+			zap.S().Warnw("Closing db get in load failed.", logging.Error, err)
 		}
 	}(closer)
 
@@ -189,7 +190,7 @@ func (ps *PebbleStore) getUint32(keyStr string) (value uint32, err error) {
 
 	binVal, closer, err := ps.db.Get(key)
 	if errors.Is(err, pebble.ErrNotFound) {
-		log.Printf("[WARN] key [%s] not found.", keyStr)
+		zap.S().Warnw("Key not found.", "key", keyStr)
 		return 0, ErrNotFound
 	}
 	if err != nil {
@@ -198,7 +199,7 @@ func (ps *PebbleStore) getUint32(keyStr string) (value uint32, err error) {
 	defer func(closer io.Closer) {
 		err = closer.Close()
 		if err != nil {
-			log.Printf("[WARN] closing db get in getUint32: %v", err)
+			zap.S().Warnw("Closing db get in getUint32 failed.", logging.Error, err)
 		}
 	}(closer)
 
@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type TickData struct {
@@ -55,7 +56,7 @@ func queryTickData(call func(c context.Context, tn uint32) (*esapi.Response, err
 	defer func(Body io.ReadCloser) {
 		err = Body.Close()
 		if err != nil {
-			log.Printf("Error closing body: %v", err)
+			zap.S().Warnw("Closing body failed.", logging.Error, err)
 		}
 	}(res.Body)
 
@@ -67,7 +68,7 @@ func queryTickData(call func(c context.Context, tn uint32) (*esapi.Response, err
 		return nil, fmt.Errorf("[%s] %s: %s", res.Status(), e["error"].(map[string]interface{})["type"], e["error"].(map[string]interface{})["reason"])
 	}
 	if res.HasWarnings() {
-		log.Printf("[WARN] elastic returned warnings: %v", res.Warnings())
+		zap.S().Warnw("Elastic returned warnings.", "warnings", res.Warnings())
 	}
 
 	var response elasticHits
@@ -76,7 +77,7 @@ func queryTickData(call func(c context.Context, tn uint32) (*esapi.Response, err
 	}
 
 	if len(response.Hits.Hits) == 0 {
-		log.Printf("[INFO] Elastic tick [%d]: no tick data.", tickNumber)
+		zap.S().Infow("Elastic tick has no tick data.", logging.Tick, tickNumber)
 		return nil, nil
 	}
 
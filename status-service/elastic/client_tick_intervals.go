@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 
 	"github.com/qubic/go-data-publisher/status-service/domain"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 func (c *Client) GetTickIntervals(ctx context.Context, beforeEpoch uint32) ([]*domain.TickInterval, error) {
@@ -30,7 +31,7 @@ func (c *Client) GetTickIntervals(ctx context.Context, beforeEpoch uint32) ([]*d
 	defer func(Body io.ReadCloser) {
 		err = Body.Close()
 		if err != nil {
-			log.Printf("Error closing body: %v", err)
+			zap.S().Warnw("Closing body failed.", logging.Error, err)
 		}
 	}(res.Body)
 
@@ -42,7 +43,7 @@ func (c *Client) GetTickIntervals(ctx context.Context, beforeEpoch uint32) ([]*d
 		return nil, fmt.Errorf("[%s] %s: %s", res.Status(), e["error"].(map[string]interface{})["type"], e["error"].(map[string]interface{})["reason"])
 	}
 	if res.HasWarnings() {
-		log.Printf("[WARN] elastic returned warnings: %v", res.Warnings())
+		zap.S().Warnw("Elastic returned warnings.", "warnings", res.Warnings())
 	}
 
 	var response elasticHits
@@ -50,11 +51,10 @@ func (c *Client) GetTickIntervals(ctx context.Context, beforeEpoch uint32) ([]*d
 		return nil, fmt.Errorf("decoding response information: %w", err)
 	}
 	if response.Took > 100 {
-		log.Printf("[WARN] slow elastic response for tick intervals: %d hits (%dms)", len(response.Hits.Hits), response.Took)
+		zap.S().Warnw("Slow elastic response for tick intervals.", "hits", len(response.Hits.Hits), "tookMillis", response.Took)
 	}
 
 	if len(response.Hits.Hits) == 0 {
-		log.Printf("[ERROR] Elastic: no tick intervals found.")
 		return nil, fmt.Errorf("no tick intervals found")
 	}
 
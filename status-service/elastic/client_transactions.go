@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
+
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 func (c *Client) GetTransactionHashes(ctx context.Context, tickNumber uint32) ([]string, error) {
@@ -23,7 +25,7 @@ func (c *Client) GetTransactionHashes(ctx context.Context, tickNumber uint32) ([
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
-			log.Printf("Error closing body: %v", err)
+			zap.S().Warnw("Closing body failed.", logging.Error, err)
 		}
 	}(res.Body)
 
@@ -35,7 +37,7 @@ func (c *Client) GetTransactionHashes(ctx context.Context, tickNumber uint32) ([
 		return nil, fmt.Errorf("[%s] %s: %s", res.Status(), e["error"].(map[string]interface{})["type"], e["error"].(map[string]interface{})["reason"])
 	}
 	if res.HasWarnings() {
-		log.Printf("[WARN] elastic returned warnings: %v", res.Warnings())
+		zap.S().Warnw("Elastic returned warnings.", "warnings", res.Warnings())
 	}
 
 	var response elasticHits
@@ -43,11 +45,11 @@ func (c *Client) GetTransactionHashes(ctx context.Context, tickNumber uint32) ([
 		return nil, fmt.Errorf("decoding response information: %w", err)
 	}
 	if response.Took > 100 {
-		log.Printf("[WARN] slow elastic response for tick [%d]: %d hits (%dms)", tickNumber, len(response.Hits.Hits), response.Took)
+		zap.S().Warnw("Slow elastic response.", logging.Tick, tickNumber, "hits", len(response.Hits.Hits), "tookMillis", response.Took)
 	}
 
 	if len(response.Hits.Hits) == 0 {
-		log.Printf("[INFO] Elastic tick [%d]: no transactions.", tickNumber)
+		zap.S().Infow("Elastic tick has no transactions.", logging.Tick, tickNumber)
 		return []string{}, nil
 	}
 
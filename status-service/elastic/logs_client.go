@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 const logCountQuery = `{ "query": { "term": { "tickNumber": %d } } }`
@@ -38,7 +39,7 @@ func (c *LogsClient) GetLogCountForTick(ctx context.Context, tickNumber uint32)
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
-			log.Printf("Error closing body: %v", err)
+			zap.S().Warnw("Closing body failed.", logging.Error, err)
 		}
 	}(res.Body)
 
@@ -54,7 +55,7 @@ func (c *LogsClient) GetLogCountForTick(ctx context.Context, tickNumber uint32)
 		return 0, fmt.Errorf("[%s] %s: %s", res.Status(), errObj["type"], errObj["reason"])
 	}
 	if res.HasWarnings() {
-		log.Printf("[WARN] elastic returned warnings: %v", res.Warnings())
+		zap.S().Warnw("Elastic returned warnings.", "warnings", res.Warnings())
 	}
 
 	var response elasticCount
@@ -24,19 +24,24 @@ import (
 	"github.com/qubic/go-data-publisher/status-service/redis"
 	"github.com/qubic/go-data-publisher/status-service/rpc"
 	"github.com/qubic/go-data-publisher/status-service/sync"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_STATUS_SERVICE"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("status-service", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-	log.SetOutput(os.Stdout) // default is stderr
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Archiver struct {
 			Host   string `conf:"default:localhost:8010"`
@@ -85,6 +90,9 @@ func run() error {
 			IntervalsCacheDuration time.Duration `conf:"default:1m"`
 			EventLogs              bool          `conf:"default:true"`
 		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
 	}
 
 	// load config
@@ -112,7 +120,11 @@ func run() error {
 	if err != nil {
 		return errors.Wrap(err, "generating config for output")
 	}
-	log.Printf("main: Config :\n%v\n", out)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
+	if err != nil {
+		return errors.Wrap(err, "setting log level")
+	}
 
 	store, err := db.NewPebbleStore(cfg.Sync.InternalStoreFolder)
 	if err != nil {
@@ -121,7 +133,7 @@ func run() error {
 	defer func(store *db.PebbleStore) {
 		err := store.Close()
 		if err != nil {
-			log.Printf("[ERROR] closing db: %v", err)
+			zap.S().Errorw("Closing db failed.", logging.Error, err)
 		}
 	}(store)
 
@@ -130,17 +142,17 @@ func run() error {
 	if err != nil {
 		return errors.Wrap(err, "initializing last processed tick")
 	}
-	log.Printf("Resuming from tick: [%d].", startTick)
+	zap.S().Infow("Resuming from tick.", logging.Tick, startTick)
 
 	logsStartTick, err := initializeLogsLastProcessedTick(store)
 	if err != nil {
 		return fmt.Errorf("initializing event logs last processed tick: %w", err)
 	}
-	log.Printf("Resuming event logs from tick: [%d].", logsStartTick)
+	zap.S().Infow("Resuming event logs from tick.", logging.Tick, logsStartTick)
 
 	cert, err := os.ReadFile(cfg.Elastic.CertificatePath)
 	if err != nil {
-		log.Printf("[WARN] main: could not read elastic certificate: %v", err)
+		zap.S().Warnw("Could not read elastic certificate.", logging.Error, err)
 	}
 	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
 		Addresses:     cfg.Elastic.Addresses,
@@ -156,7 +168,7 @@ func run() error {
 
 	var cl sync.ArchiveClient
 	if cfg.Archiver.Legacy {
-		log.Printf("[WARN] legacy archiver client")
+		zap.S().Warn("Using legacy archiver client.")
 		cl, err = archiverv1.NewClient(cfg.Archiver.Host)
 	} else {
 		cl, err = archiverv2.NewClient(cfg.Archiver.Host)
@@ -176,15 +188,15 @@ func run() error {
 	})
 	if cfg.Sync.Transactions || cfg.Sync.TickData {
 		go processor.Synchronize()
-		log.Println("main: starting to process")
+		zap.S().Info("Starting to process.")
 	} else {
-		log.Println("[WARN] main: sync disabled")
+		zap.S().Warn("Sync disabled.")
 	}
 
 	if cfg.Sync.EventLogs {
 		eventLogsCert, err := os.ReadFile(cfg.EventsElastic.CertificatePath)
 		if err != nil {
-			log.Printf("[WARN] main: could not read event logs elastic certificate: %v", err)
+			zap.S().Warnw("Could not read event logs elastic certificate.", logging.Error, err)
 		}
 		eventLogsEsClient, err := elasticsearch.NewClient(elasticsearch.Config{
 			Addresses:     cfg.EventsElastic.Addresses,
@@ -210,9 +222,9 @@ func run() error {
 		eventLogsProcessor := sync.NewLogProcessor(eventLogsElasticClient, eventLogsRedisClient, store, cfg.EventsElastic.Delay, m)
 
 		go eventLogsProcessor.Synchronize()
-		log.Println("main: starting to process event logs")
+		zap.S().Info("Starting to process event logs.")
 	} else {
-		log.Println("[WARN] main: event logs sync disabled")
+		zap.S().Warn("Event logs sync disabled.")
 	}
 
 	shutdown := make(chan os.Signal, 1)
@@ -239,27 +251,28 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("starting server: %w", err)
 	}
-	log.Println("main: started web server")
+	zap.S().Info("Started web server.")
 
 	// metrics endpoint
 	metricsServerError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting metrics server on addr [%s].", cfg.Server.MetricsHttpHost)
+		zap.S().Infow("Starting metrics and log level server.", "address", cfg.Server.MetricsHttpHost)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		metricsServerError <- http.ListenAndServe(cfg.Server.MetricsHttpHost, nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-metricsServerError:
-			return errors.Wrapf(err, "[ERROR] starting metrics endpoint.")
+			return errors.Wrap(err, "starting metrics endpoint")
 		case err := <-serverError:
-			return errors.Wrapf(err, "[ERROR] starting server endpoint(s).")
+			return errors.Wrap(err, "starting server endpoint(s)")
 		}
 	}
 }
@@ -3,12 +3,13 @@ package rpc
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/qubic/go-data-publisher/status-service/protobuf"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -41,24 +42,24 @@ func NewStatusServiceServer(listenAddrGRPC string, listenAddrHTTP string, status
 func (s *StatusServiceServer) GetStatus(context.Context, *emptypb.Empty) (*protobuf.GetStatusResponse, error) {
 	lastProcessedTick, err := s.statusCache.GetLastProcessedTick()
 	if err != nil {
-		log.Printf("[ERROR] getting status (last processed tick): %v", err)
+		zap.S().Errorw("Getting status (last processed tick) failed.", logging.Error, err)
 		return nil, status.Error(codes.Internal, "getting status")
 	}
 	lastProcessedEpoch, err := s.statusCache.GetLastProcessedEpoch()
 	if err != nil {
-		log.Printf("[ERROR] getting status (last processed epoch): %v", err)
+		zap.S().Errorw("Getting status (last processed epoch) failed.", logging.Error, err)
 		return nil, status.Error(codes.Internal, "getting status")
 	}
 
 	initialTickOfCurrentTickRange, err := s.statusCache.GetInitialTickOfCurrentTickRange()
 	if err != nil {
-		log.Printf("[ERROR] getting status (initial tick of current tick range): %v", err)
+		zap.S().Errorw("Getting status (initial tick of current tick range) failed.", logging.Error, err)
 		return nil, status.Errorf(codes.Internal, "getting status")
 	}
 
 	lastProcessedLogTick, err := s.statusCache.GetLogLastProcessedTick()
 	if err != nil {
-		log.Printf("[ERROR] getting status (log last processed tick): %v", err)
+		zap.S().Errorw("Getting status (log last processed tick) failed.", logging.Error, err)
 		return nil, status.Errorf(codes.Internal, "getting status")
 	}
 
@@ -73,7 +74,7 @@ func (s *StatusServiceServer) GetStatus(context.Context, *emptypb.Empty) (*proto
 func (s *StatusServiceServer) GetArchiverStatus(context.Context, *emptypb.Empty) (*protobuf.GetArchiverStatusResponse, error) {
 	response, err := s.statusCache.GetArchiverStatusResponse()
 	if err != nil {
-		log.Printf("[ERROR] getting archiver status: %v", err)
+		zap.S().Errorw("Getting archiver status failed.", logging.Error, err)
 		return nil, status.Error(codes.Internal, "getting archiver status")
 	}
 	return response, nil
@@ -82,7 +83,7 @@ func (s *StatusServiceServer) GetArchiverStatus(context.Context, *emptypb.Empty)
 func (s *StatusServiceServer) GetTickIntervals(ctx context.Context, _ *emptypb.Empty) (*protobuf.GetTickIntervalsResponse, error) {
 	response, err := s.statusCache.GetTickIntervals(ctx)
 	if err != nil {
-		log.Printf("[ERROR] getting tick intervals: %v", err)
+		zap.S().Errorw("Getting tick intervals failed.", logging.Error, err)
 		return nil, status.Error(codes.Internal, "getting tick intervals")
 	}
 	return response, nil
@@ -91,7 +92,7 @@ func (s *StatusServiceServer) GetTickIntervals(ctx context.Context, _ *emptypb.E
 func (s *StatusServiceServer) GetErroneousSkippedTicks(context.Context, *emptypb.Empty) (*protobuf.GetSkippedTicksResponse, error) {
 	ticks, err := s.statusCache.GetErroneousSkippedTicks()
 	if err != nil {
-		log.Printf("[ERROR] getting skipped ticks: %v", err)
+		zap.S().Errorw("Getting skipped ticks failed.", logging.Error, err)
 		return nil, status.Error(codes.Internal, "getting skipped ticks")
 	}
 	return &protobuf.GetSkippedTicksResponse{SkippedTicks: ticks}, nil
@@ -3,13 +3,14 @@ package rpc
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/qubic/go-data-publisher/status-service/domain"
 	"github.com/qubic/go-data-publisher/status-service/protobuf"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type ElasticClient interface {
@@ -152,7 +153,7 @@ func (s *StatusService) getElasticIntervals(ctx context.Context, beforeEpoch uin
 			return nil, fmt.Errorf("getting elastic intervals: %w", err)
 		}
 		s.tickIntervalsCache.Set(tickIntervalsKey, elasticIntervals, ttlcache.DefaultTTL)
-		log.Printf("Updated intervals cache. Took %d ms.", time.Since(start).Milliseconds())
+		zap.S().Infow("Updated intervals cache.", logging.Duration, time.Since(start))
 		return elasticIntervals, nil
 	}
 
@@ -3,11 +3,12 @@ package sync
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/qubic/go-data-publisher/status-service/domain"
 	"github.com/qubic/go-data-publisher/status-service/metrics"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type LogSearchClient interface {
@@ -49,7 +50,7 @@ func (ep *LogProcessor) Synchronize() {
 		err := ep.sync()
 		if err != nil {
 			ep.incrementErrorCount()
-			log.Printf("Logs check failed: %v", err)
+			zap.S().Errorw("Logs check failed.", logging.Error, err)
 			continue
 		}
 		ep.resetErrorCount()
@@ -87,7 +88,7 @@ func (ep *LogProcessor) sync() error {
 		return fmt.Errorf("logs log mismatch. redis: %d, elastic: %d", lastIngestedTickStatus.LogCount, elasticCount)
 	}
 
-	log.Printf("Validated logs count for tick %d: %d", lastIngestedTickStatus.TickNumber, lastIngestedTickStatus.LogCount)
+	zap.S().Infow("Validated logs count.", logging.Tick, lastIngestedTickStatus.TickNumber, "count", lastIngestedTickStatus.LogCount)
 
 	err = ep.dataStore.SetLogLastProcessedTick(lastIngestedTickStatus.TickNumber)
 	if err != nil {
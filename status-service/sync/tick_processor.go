@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"log"
 	"slices"
 	"time"
 
@@ -13,6 +12,8 @@ import (
 	"github.com/qubic/go-data-publisher/status-service/elastic"
 	"github.com/qubic/go-data-publisher/status-service/metrics"
 	"github.com/qubic/go-data-publisher/status-service/util"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -85,7 +86,7 @@ func (p *TickProcessor) Synchronize() {
 			p.resetErrorCount()
 		} else {
 			p.incrementErrorCount()
-			log.Printf("Check failed: %v", err)
+			zap.S().Errorw("Check failed.", logging.Error, err)
 		}
 	}
 }
@@ -120,9 +121,9 @@ func (p *TickProcessor) sync() error {
 
 	if start <= end && start > 0 && end > 0 && epoch > 0 {
 		if start == end {
-			log.Printf("Processing tick [%d].", start)
+			zap.S().Infow("Processing tick.", logging.Epoch, epoch, logging.Tick, start)
 		} else {
-			log.Printf("Processing ticks from [%d] to [%d] for epoch [%d].", start, end, epoch)
+			zap.S().Infow("Processing ticks.", logging.Epoch, epoch, "from", start, "to", end)
 		}
 
 		// if start == end then process one tick
@@ -192,7 +193,7 @@ func (p *TickProcessor) processTicks(ctx context.Context, ticks []uint32) error
 	var errorGroup errgroup.Group
 	for _, tick := range ticks {
 		errorGroup.Go(func() error {
-			// log.Printf("Processing tick [%d]", tick)
+			// zap.S().Debugw("Processing tick.", logging.Tick, tick)
 			return p.processTick(ctx, tick)
 		})
 	}
@@ -209,7 +210,7 @@ func (p *TickProcessor) processTick(ctx context.Context, tick uint32) error {
 
 	// we have some invalid empty ticks in epoch 154. We can safely ignore them.
 	if tickData != nil && tickData.Epoch == 65535 && tick > 22175000 && tick < 22187500 {
-		log.Printf("Correcting invalid empty tick data for tick [%d] to allow further processing.", tick)
+		zap.S().Infow("Correcting invalid empty tick data to allow further processing.", logging.Tick, tick)
 		tickData = nil
 	}
 
@@ -237,7 +238,7 @@ func (p *TickProcessor) processTick(ctx context.Context, tick uint32) error {
 
 func (p *TickProcessor) handleTickMismatch(tick uint32) error {
 	if p.skipErroneousTicks {
-		log.Printf("[WARN] skipping tick [%d].", tick)
+		zap.S().Warnw("Skipping tick.", logging.Tick, tick)
 		err := p.dataStore.AddSkippedErroneousTick(tick)
 		if err != nil {
 			return fmt.Errorf("trying to store skipped tick: %w", err)
@@ -272,12 +273,12 @@ func (p *TickProcessor) verifyTickData(ctx context.Context, tick uint32, archive
 
 	if !match {
 		if elasticTd == nil { // typical case
-			log.Printf("Tick data mismatch for tick [%d]. Missing in Elastic.", tick)
+			zap.S().Warnw("Tick data mismatch. Missing in Elastic.", logging.Tick, tick)
 		} else if archiveTd == nil { // typical case
-			log.Printf("Tick data mismatch for tick [%d]. Missing in Archiver.", tick)
+			zap.S().Warnw("Tick data mismatch. Missing in Archiver.", logging.Tick, tick)
 		} else { // complicated data mismatch
-			log.Printf("Tick data mismatch for tick [%d]. Elastic: [%v]. Archiver: [%v]",
-				tick, elasticDebugString(elasticTd), archiveDebugString(archiveTd))
+			zap.S().Warnw("Tick data mismatch.", logging.Tick, tick, "elastic", elasticDebugString(elasticTd),
+				"archiver", archiveDebugString(archiveTd))
 		}
 	}
 
@@ -320,12 +321,10 @@ func (p *TickProcessor) verifyTickTransactions(ctx context.Context, tick uint32,
 	difference := util.Difference(util.ToSet(archiverTransactions), util.ToSet(elasticTransactions))
 	if len(difference) > 0 { // transactions do not match
 		if p.skipErroneousTicks { // verbose log only if we skip ticks
-			log.Printf("Transaction mismatch for tick [%d].", tick)
-			log.Printf("Archiver: %v", tickData)
-			log.Printf("Elastic: %v", elasticTransactions)
+			zap.S().Warnw("Transaction mismatch.", logging.Tick, tick, "archiver", tickData, "elastic", elasticTransactions)
 		} else {
-			log.Printf("Transaction mismatch for tick [%d]. Count: archiver [%d], elastic [%d].",
-				tick, len(archiverTransactions), len(elasticTransactions))
+			zap.S().Warnw("Transaction mismatch.", logging.Tick, tick, "archiverCount", len(archiverTransactions),
+				"elasticCount", len(elasticTransactions))
 		}
 		return false, nil // return mismatch
 	}
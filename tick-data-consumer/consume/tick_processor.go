@@ -3,14 +3,15 @@ package consume
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-data-consumer/domain"
 	"github.com/qubic/tick-data-consumer/elastic"
 	"github.com/qubic/tick-data-consumer/metrics"
+	"go.uber.org/zap"
 )
 
 type KafkaClient interface {
@@ -42,16 +43,32 @@ func (p *TickProcessor) Consume() error {
 		count, err := p.consumeBatch(context.Background())
 		if err != nil {
 			// if there is an error consuming we abort. We need to fix the error before trying again.
-			log.Printf("Error consuming batch: %v", err)
+			zap.S().Errorw("Consuming batch failed.", logging.Error, err)
 			return errors.Wrap(err, "consuming batch")
 		} else {
 			p.consumeMetrics.AddProcessedTicks(count)
-			log.Printf("Processed [%d] ticks.", count)
+			zap.S().Infow("Processed ticks.", "count", count)
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
 }
 
+// Replay consumes until done returns true. Returns the number of processed ticks.
+func (p *TickProcessor) Replay(ctx context.Context, done func() bool) (int, error) {
+	total := 0
+	for !done() {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		count, err := p.consumeBatch(ctx)
+		if err != nil {
+			return total, errors.Wrap(err, "consuming batch")
+		}
+		total += count
+	}
+	return total, nil
+}
+
 func (p *TickProcessor) consumeBatch(ctx context.Context) (int, error) {
 	// get messages
 	defer p.kafkaClient.AllowRebalance()
@@ -76,6 +76,26 @@ func TestTickProcessor_consumeBatch_thenFetchSendCommit(t *testing.T) {
 	assert.Equal(t, 4, elasticClient.bulkIndexCount)
 }
 
+func TestTickProcessor_Replay(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		tickDataList: []*domain.TickData{
+			{Epoch: 1, TickNumber: 1}, {Epoch: 1, TickNumber: 2},
+		},
+	}
+	elasticClient := &FakeElasticClient{}
+	processor := NewTickProcessor(kafkaClient, elasticClient, m)
+
+	count, err := processor.Replay(context.Background(), func() bool { return kafkaClient.commitCount == 3 })
+	require.NoError(t, err)
+	assert.Equal(t, 6, count)
+	assert.Equal(t, 6, elasticClient.bulkIndexCount)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = processor.Replay(ctx, func() bool { return false })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestTickProcessor_consumeBatch_givenEmptyTick_thenError(t *testing.T) {
 	kafkaClient := &FakeKafkaClient{
 		tickDataList: []*domain.TickData{
@@ -1,40 +1,53 @@
 package elastic
 
 import (
+	"io"
 	"net/http"
 	"time"
 
-	"github.com/elastic/elastic-transport-go/v8/elastictransport"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
+// maxLoggedBodySize limits the logged part of the response body.
+const maxLoggedBodySize = 4096
+
+// Logger logs failed elasticsearch requests as structured entries.
 type Logger struct {
-	elasticLogger elastictransport.Logger
 }
 
-func NewLogger(elasticLogger elastictransport.Logger) *Logger {
-	return &Logger{
-		elasticLogger: elasticLogger,
-	}
+func NewLogger() *Logger {
+	return &Logger{}
 }
 
-func (l *Logger) LogRoundTrip(request *http.Request, response *http.Response, err error, time time.Time, duration time.Duration) error {
+func (l *Logger) LogRoundTrip(request *http.Request, response *http.Response, err error, _ time.Time, duration time.Duration) error {
 
 	// err is present only for transport errors such as network failure, timeout, DNS
-	if err != nil {
-		return l.elasticLogger.LogRoundTrip(request, response, err, time, duration)
+	// this filters for retriable errors
+	if err == nil && (response == nil || response.StatusCode < 400) {
+		return nil
 	}
 
-	// this filters for retriable errors
-	if response != nil && response.StatusCode >= 400 {
-		return l.elasticLogger.LogRoundTrip(request, response, err, time, duration)
+	fields := []any{"method", request.Method, "url", request.URL.Redacted(), logging.Duration, duration}
+	if response != nil {
+		fields = append(fields, "status", response.StatusCode)
+		if response.Body != nil {
+			body, _ := io.ReadAll(io.LimitReader(response.Body, maxLoggedBodySize))
+			fields = append(fields, "response", string(body))
+		}
+	}
+	if err != nil {
+		fields = append(fields, logging.Error, err)
 	}
+	zap.S().Warnw("Elasticsearch request failed.", fields...)
 	return nil
 }
 
 func (l *Logger) RequestBodyEnabled() bool {
-	return l.elasticLogger.RequestBodyEnabled()
+	return false
 }
 
+// ResponseBodyEnabled makes the response body available in the logger.
 func (l *Logger) ResponseBodyEnabled() bool {
-	return l.elasticLogger.ResponseBodyEnabled()
+	return true
 }
@@ -12,11 +12,13 @@ import (
 	"time"
 
 	"github.com/ardanlabs/conf"
-	"github.com/elastic/elastic-transport-go/v8/elastictransport"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/logging"
+	"github.com/qubic/replay"
+	"github.com/qubic/sink"
 	"github.com/qubic/tick-data-consumer/consume"
 	"github.com/qubic/tick-data-consumer/elastic"
 	"github.com/qubic/tick-data-consumer/kafka"
@@ -24,19 +26,23 @@ import (
 	"github.com/qubic/tick-data-consumer/status"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_TICK_DATA_CONSUMER"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("tick-data-consumer", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-	log.SetOutput(os.Stdout) // default is stderr
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Elastic struct {
 			Addresses   []string `conf:"default:https://localhost:9200"`
@@ -45,7 +51,9 @@ func run() error {
 			IndexName   string   `conf:"default:qubic-tick-data-alias"`
 			Certificate string   `conf:"default:http_ca.crt"`
 			MaxRetries  int      `conf:"default:15"`
-			Stub        bool     `conf:"optional"` // only for testing
+			Sink        string   `conf:"default:elasticsearch"` // elasticsearch, opensearch or ndjson
+			BulkUrl     string   `conf:"optional"`              // bulk endpoint of the ndjson sink. Defaults to <address>/_bulk.
+			Stub        bool     `conf:"optional"`              // only for testing
 		}
 		Broker struct {
 			BootstrapServers []string `conf:"default:localhost:9092"`
@@ -57,6 +65,14 @@ func run() error {
 			MetricsNamespace string `conf:"default:qubic_kafka"`
 			Enabled          bool   `conf:"default:true"` // only for testing
 		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
+		Replay struct {
+			replay.Options
+			replay.TickRangeOptions
+		}
+		Args conf.Args
 	}
 
 	// load config
@@ -84,33 +100,20 @@ func run() error {
 	if err != nil {
 		return errors.Wrap(err, "generating config for output")
 	}
-	log.Printf("main: Config :\n%v\n", out)
-
-	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
-		kprom.Registerer(prometheus.DefaultRegisterer),
-		kprom.Gatherer(prometheus.DefaultGatherer))
-	kcl, err := kgo.NewClient(
-		kgo.WithHooks(m),
-		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
-		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
-		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
-		kgo.BlockRebalanceOnPoll(),
-		kgo.DisableAutoCommit(),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
-	)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
 	if err != nil {
-		log.Fatal(err)
+		return errors.Wrap(err, "setting log level")
 	}
-	defer kcl.Close()
 
 	cert, err := os.ReadFile(cfg.Elastic.Certificate)
 	if err != nil {
-		log.Printf("[WARN] main: could not read elastic certificate: %v", err)
+		zap.S().Warnw("Could not read elastic certificate.", logging.Error, err)
 	}
 
 	var elasticClient consume.ElasticClient
 	if cfg.Elastic.Stub {
-		log.Printf("[WARN] main: stubbing elastic client")
+		zap.S().Warn("Stubbing elastic client.")
 		elasticClient = &ElasticStubClient{}
 	} else {
 		esClient, err := elasticsearch.NewClient(elasticsearch.Config{
@@ -121,18 +124,60 @@ func run() error {
 			RetryOnStatus: []int{502, 503, 504, 429},
 			MaxRetries:    cfg.Elastic.MaxRetries,
 			RetryBackoff:  calculateBackoff(),
-			Logger: elastic.NewLogger(&elastictransport.TextLogger{
-				Output:             os.Stdout,
-				EnableRequestBody:  false,
-				EnableResponseBody: true, // Make response body available in the logger so we can log response
-			}),
+			Logger:        elastic.NewLogger(),
 		})
 		if err != nil {
 			return errors.Wrap(err, "creating elastic client")
 		}
-		elasticClient = elastic.NewClient(esClient, cfg.Elastic.IndexName)
+		sinkClient, err := sink.NewClient(context.Background(), esClient, sink.HttpBulkConfig{
+			Sink:         cfg.Elastic.Sink,
+			Addresses:    cfg.Elastic.Addresses,
+			BulkUrl:      cfg.Elastic.BulkUrl,
+			Username:     cfg.Elastic.Username,
+			Password:     cfg.Elastic.Password,
+			CACert:       cert,
+			MaxRetries:   cfg.Elastic.MaxRetries,
+			RetryBackoff: calculateBackoff(),
+		})
+		if err != nil {
+			return err
+		}
+		elasticClient = elastic.NewClient(sinkClient, cfg.Elastic.IndexName)
 	}
 	consumeMetrics := metrics.NewMetrics(cfg.Sync.MetricsNamespace)
+
+	if len(cfg.Args) > 0 {
+		replayConfig, err := replay.NewConfig(cfg.Broker.ConsumeTopic, cfg.Replay.Options, cfg.Replay.TickRangeOptions)
+		if err != nil {
+			return errors.Wrap(err, "parsing replay config")
+		}
+		return runReplayCommand(context.Background(), cfg.Args, replayCommand{
+			config:        replayConfig,
+			brokers:       cfg.Broker.BootstrapServers,
+			dryRun:        cfg.Replay.DryRun,
+			elasticClient: elasticClient,
+			indexName:     cfg.Elastic.IndexName,
+			metrics:       consumeMetrics,
+		})
+	}
+
+	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
+		kprom.Registerer(prometheus.DefaultRegisterer),
+		kprom.Gatherer(prometheus.DefaultGatherer))
+	kcl, err := kgo.NewClient(
+		kgo.WithHooks(m),
+		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
+		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
+		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.DisableAutoCommit(),
+		kgo.WithLogger(logging.NewKafkaLogger()),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating kafka client")
+	}
+	defer kcl.Close()
+
 	consumer := kafka.NewClient(kcl, consumeMetrics)
 	processor := consume.NewTickProcessor(consumer, elasticClient, consumeMetrics)
 
@@ -142,7 +187,7 @@ func run() error {
 			procError <- processor.Consume()
 		}()
 	} else {
-		log.Println("[WARN] main: Message consuming disabled")
+		zap.S().Warn("Message consuming disabled.")
 	}
 
 	shutdown := make(chan os.Signal, 1)
@@ -151,27 +196,26 @@ func run() error {
 	// status and metrics endpoint
 	serverError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting health and metrics endpoint on port [%d].", cfg.Sync.MetricsPort)
+		zap.S().Infow("Starting health, metrics and log level endpoint.", "port", cfg.Sync.MetricsPort)
 		http.HandleFunc("/health", status.Health)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		serverError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-procError:
-			return fmt.Errorf("[ERROR] processing error: %v", err)
+			return errors.Wrap(err, "processing")
 		case err := <-serverError:
-			return fmt.Errorf("[ERROR] starting server: %v", err)
+			return errors.Wrap(err, "starting server")
 		}
 	}
-
-	return nil
 }
 
 // calculateBackoff needs retry number because of multi threading
@@ -183,7 +227,7 @@ func calculateBackoff() func(i int) time.Duration {
 		} else {
 			d = time.Second*30 + randomMillis()
 		}
-		log.Printf("[WARN] elasticsearch client retry [%d] in %v.", i, d)
+		zap.S().Warnw("Retrying elasticsearch request.", "attempt", i, logging.Duration, d)
 		return d
 	}
 }
@@ -195,6 +239,6 @@ func randomMillis() time.Duration {
 type ElasticStubClient struct{}
 
 func (e ElasticStubClient) BulkIndex(_ context.Context, data []*elastic.EsDocument) error {
-	log.Printf("[WARN] main: elastic client stubbed! Skipping [%d] documents.", len(data))
+	zap.S().Warnw("Elastic client stubbed. Skipping documents.", "documents", len(data))
 	return nil
 }
@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
@@ -23,7 +25,7 @@ func (h *Handler) GetHealth(w http.ResponseWriter, _ *http.Request) {
 		Status: "UP",
 	})
 	if err != nil {
-		log.Printf("Error encoding response: %v", err)
+		zap.S().Errorw("Encoding response failed.", logging.Error, err)
 		http.Error(w, "Error encoding response", 500)
 		return
 	}
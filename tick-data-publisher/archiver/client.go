@@ -6,10 +6,11 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 
 	archiverproto "github.com/qubic/go-archiver-v2/protobuf"
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-data-publisher/domain"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -66,7 +67,7 @@ func (c *Client) GetTickData(ctx context.Context, tickNumber uint32) (*domain.Ti
 		return nil, errors.New("nil tick data response")
 	}
 	if response.GetTickData() == nil {
-		log.Printf("[INFO] Tick [%d] is empty.", tickNumber)
+		zap.S().Infow("Tick is empty.", logging.Tick, tickNumber)
 		return nil, nil
 	}
 	tickData, err := convertTickData(response.GetTickData())
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"path/filepath"
 
 	"github.com/cockroachdb/pebble/v2"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 var ErrNotFound = errors.New("store resource not found")
@@ -46,7 +47,7 @@ func (ps *PebbleStore) GetLastProcessedTick() (tick uint32, err error) {
 
 	value, closer, err := ps.db.Get(key)
 	if errors.Is(err, pebble.ErrNotFound) {
-		log.Printf("[WARN] key [%s] not found.", lastProcessedTickKey)
+		zap.S().Warnw("Key not found.", "key", lastProcessedTickKey)
 		return 0, ErrNotFound
 	}
 	if err != nil {
@@ -55,7 +56,7 @@ func (ps *PebbleStore) GetLastProcessedTick() (tick uint32, err error) {
 	defer func(closer io.Closer) {
 		err := closer.Close()
 		if err != nil {
-			log.Printf("[ERROR] closing db: %v", err)
+			zap.S().Errorw("Closing db failed.", logging.Error, err)
 		}
 	}(closer)
 
@@ -13,6 +13,7 @@ import (
 	"github.com/ardanlabs/conf"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-data-publisher/api"
 	"github.com/qubic/tick-data-publisher/archiver"
 	"github.com/qubic/tick-data-publisher/db"
@@ -21,19 +22,23 @@ import (
 	"github.com/qubic/tick-data-publisher/sync"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_TICK_DATA_PUBLISHER"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("tick-data-publisher", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-	log.SetOutput(os.Stdout) // default is stderr
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Client struct {
 			ArchiverGrpcHost string `conf:"default:localhost:8010"`
@@ -52,6 +57,9 @@ func run() error {
 			StartTick           uint32   `conf:"optional"`     // overrides last processed tick
 			Enabled             bool     `conf:"default:true"` // only for testing
 		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
 	}
 
 	// load config
@@ -79,7 +87,11 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("generating config for output: %w", err)
 	}
-	log.Printf("main: Config :\n%v\n", out)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
+	if err != nil {
+		return fmt.Errorf("setting log level: %w", err)
+	}
 
 	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
 		kprom.Registerer(prometheus.DefaultRegisterer),
@@ -89,10 +101,10 @@ func run() error {
 		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
 		kgo.DefaultProduceTopic(cfg.Broker.ProduceTopic),
 		kgo.ProducerBatchCompression(kgo.ZstdCompression()),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
+		kgo.WithLogger(logging.NewKafkaLogger()),
 	)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("creating kafka client: %w", err)
 	}
 	defer kcl.Close()
 
@@ -103,7 +115,7 @@ func run() error {
 
 	lastProcessedTick, err := store.GetLastProcessedTick()
 	if cfg.Sync.StartTick > 0 || errors.Is(err, db.ErrNotFound) {
-		log.Printf("Setting last processed tick to [%d]", cfg.Sync.StartTick)
+		zap.S().Infow("Setting last processed tick.", logging.Tick, cfg.Sync.StartTick)
 		setErr := store.SetLastProcessedTick(cfg.Sync.StartTick)
 		if setErr != nil {
 			return fmt.Errorf("setting last processed tick: %w", err)
@@ -111,7 +123,7 @@ func run() error {
 	} else if err != nil {
 		return fmt.Errorf("getting last processed tick: %w", err)
 	} else {
-		log.Printf("Resuming from tick: [%d].", lastProcessedTick)
+		zap.S().Infow("Resuming from tick.", logging.Tick, lastProcessedTick)
 	}
 
 	cl, err := archiver.NewClient(cfg.Client.ArchiverGrpcHost)
@@ -124,7 +136,7 @@ func run() error {
 	procErr := make(chan error, 1)
 	processor := sync.NewTickDataProcessor(store, cl, producer, cfg.Sync.NumWorkers, procMetrics)
 	if !cfg.Sync.Enabled {
-		log.Println("[WARN] main: Message consuming disabled")
+		zap.S().Warn("Message consuming disabled.")
 	} else if len(cfg.Sync.PublishCustomTicks) > 0 {
 		go func() { procErr <- processor.PublishCustomTicks(cfg.Sync.PublishCustomTicks) }()
 	} else {
@@ -139,35 +151,36 @@ func run() error {
 		mux := http.NewServeMux()
 		server := api.NewHandler()
 		mux.HandleFunc("/health", server.GetHealth)
-		log.Printf("main: Starting server on port [%d].", cfg.Sync.ServerPort)
+		zap.S().Infow("Starting server.", "port", cfg.Sync.ServerPort)
 		apiError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.ServerPort), mux)
 	}()
 
 	metricsError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting metrics server on port [%d].", cfg.Sync.MetricsPort)
+		zap.S().Infow("Starting metrics and log level server.", "port", cfg.Sync.MetricsPort)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		metricsError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-procErr:
 			if err != nil {
-				return fmt.Errorf("[ERROR] processing: %v", err)
+				return fmt.Errorf("processing: %w", err)
 			} else {
-				log.Printf("main: Finished proessing.")
+				zap.S().Info("Finished processing.")
 				return nil
 			}
 		case err := <-metricsError:
-			return fmt.Errorf("[ERROR] starting server: %v", err)
+			return fmt.Errorf("starting server: %w", err)
 		case err := <-apiError:
-			return fmt.Errorf("[ERROR] starting server: %v", err)
+			return fmt.Errorf("starting server: %w", err)
 		}
 	}
 }
@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-data-publisher/domain"
 	"github.com/qubic/tick-data-publisher/metrics"
 	"github.com/twmb/franz-go/pkg/kerr"
+	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -45,7 +46,7 @@ func NewTickDataProcessor(db DataStore, client ArchiveClient, producer Producer,
 		numWorkers:        numWorkers,
 		processingMetrics: m,
 	}
-	log.Printf("[INFO] using up to [%d] workers", numWorkers)
+	zap.S().Infow("Configured parallel processing.", "maxWorkers", numWorkers)
 	return &tdp
 }
 
@@ -60,21 +61,21 @@ func (p *TickDataProcessor) StartProcessing() error {
 				}
 			}
 			// only exit, if non-retriable kafka error
-			log.Printf("Error processing tick data: %v", err)
+			zap.S().Errorw("Processing tick data failed.", logging.Error, err)
 		}
 	}
 	return nil
 }
 
 func (p *TickDataProcessor) PublishCustomTicks(ticks []uint32) error {
-	log.Printf("[INFO] publishing custom ticks")
+	zap.S().Info("Publishing custom ticks.")
 	ctx := context.Background()
 	for _, tick := range ticks {
 		err := p.processTick(ctx, tick)
 		if err != nil {
 			return fmt.Errorf("processing tick [%d]: %w", tick, err)
 		}
-		log.Printf("Published tick [%d].", tick)
+		zap.S().Infow("Published tick.", logging.Tick, tick)
 	}
 	return nil
 }
@@ -100,9 +101,9 @@ func (p *TickDataProcessor) process() error {
 
 	if start <= end && start > 0 && end > 0 && epoch > 0 {
 		if start == end {
-			log.Printf("Processing tick [%d] for epoch [%d].", end, epoch)
+			zap.S().Infow("Processing tick.", logging.Epoch, epoch, logging.Tick, end)
 		} else {
-			log.Printf("Processing ticks from [%d] to [%d] for epoch [%d].", start, end, epoch)
+			zap.S().Infow("Processing ticks.", logging.Epoch, epoch, "from", start, "to", end)
 		}
 		err = p.processTickRange(ctx, epoch, start, end)
 		if err != nil {
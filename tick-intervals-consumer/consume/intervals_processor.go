@@ -4,11 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-intervals-consumer/domain"
 	"github.com/qubic/tick-intervals-consumer/elastic"
+	"go.uber.org/zap"
 )
 
 type KafkaClient interface {
@@ -35,20 +36,35 @@ func NewProcessor(kafkaClient KafkaClient, elasticClient ElasticClient) *Process
 }
 
 func (p *Processor) Consume() error {
-	log.Println("Starting consume loop")
+	zap.S().Info("Starting consume loop.")
 	for {
 		count, err := p.consumeBatch(context.Background())
 		if err != nil {
 			// abort on error and fix issue
-			log.Println("Error consuming batch.")
 			return fmt.Errorf("consuming batch: %w", err)
 		} else {
-			log.Printf("Processed [%d] intervals.", count)
+			zap.S().Infow("Processed intervals.", "count", count)
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
 }
 
+// Replay consumes until done returns true. Returns the number of consumed intervals.
+func (p *Processor) Replay(ctx context.Context, done func() bool) (int, error) {
+	total := 0
+	for !done() {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		count, err := p.consumeBatch(ctx)
+		if err != nil {
+			return total, fmt.Errorf("consuming batch: %w", err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
 func (p *Processor) consumeBatch(ctx context.Context) (int, error) {
 	// get messages
 	defer p.kafkaClient.AllowRebalance()
@@ -86,7 +102,8 @@ func (p *Processor) sendToElastic(ctx context.Context, intervals []*domain.TickI
 			interval.From == 0 || interval.To == 0 || interval.From > interval.To {
 			return fmt.Errorf("invalid tick interval: %+v", interval)
 		}
-		log.Printf("Indexing tick interval for epoch [%d]: [%d] - [%d].", interval.Epoch, interval.From, interval.To)
+		zap.S().Infow("Indexing tick interval.", logging.Epoch, interval.Epoch, "from", interval.From, "to", interval.To,
+			"final", interval.IsFinal())
 		document, err := convertToDocument(interval)
 		if err != nil {
 			return err
@@ -109,17 +126,17 @@ func (p *Processor) filterDuplicates(ctx context.Context, intervals []*domain.Ti
 	for _, interval := range intervals {
 		stored, err := p.elasticClient.FindOverlappingInterval(ctx, interval.Epoch, interval.From, interval.To)
 		if err != nil {
-			log.Printf("Error checking interval: %v", err)
+			zap.S().Errorw("Checking interval failed.", logging.Epoch, interval.Epoch, "from", interval.From, "to", interval.To, logging.Error, err)
 			return nil, err
 		}
 
-		// check if there is already a tick interval (same epoch, same start tick, higher end tick) to be
-		// ingested and replace, if the new one is larger or ignore the new one
+		// check if there is already a tick interval (same epoch, same start tick, higher end tick or final) to be
+		// ingested and replace, if the new one is larger or final or ignore the new one
 		intervalKey := fmt.Sprintf(key, interval.Epoch, interval.From)
 		previous, found := temporaryIntervals[intervalKey]
-		newIntervalIsLarger := !found || interval.To > previous.To
+		newIntervalReplaces := !found || interval.Replaces(previous.To, previous.IsFinal())
 
-		if newIntervalIsLarger {
+		if newIntervalReplaces {
 
 			if stored == nil {
 				temporaryIntervals[intervalKey] = *interval
@@ -127,17 +144,20 @@ func (p *Processor) filterDuplicates(ctx context.Context, intervals []*domain.Ti
 				if interval.Epoch != stored.Epoch || interval.From != stored.From { // illegal state
 					// we assume that epoch and start tick always match (they are used as document id in elastic)
 					return nil, fmt.Errorf("new interval %v conflicts with stored data", interval)
-				} else if interval.To > stored.To {
+				} else if interval.Replaces(stored.To, stored.IsFinal()) {
 					// replace if the end tick is larger than in the current interval
 					// this can happen at epoch end if an instance doesn't catch the latest tick(s)
+					// or if a provisional interval grew or got final
 					temporaryIntervals[intervalKey] = *interval
-				} else { // else ignore because they are equal or smaller
-					log.Printf("Ignoring new interval %v because of stored interval %v.", interval, stored)
+				} else { // else ignore because they are equal or smaller or provisional
+					zap.S().Infow("Ignoring new interval because of stored interval.", logging.Epoch, interval.Epoch, "from", interval.From,
+						"to", interval.To, "final", interval.IsFinal(), "storedTo", stored.To, "storedFinal", stored.IsFinal())
 				}
 			}
 
-		} else { // else ignore smaller interval
-			log.Printf("Ignoring interval %v because of other new interval %v.", interval, previous)
+		} else { // else ignore smaller or provisional interval
+			zap.S().Infow("Ignoring interval because of other new interval.", logging.Epoch, interval.Epoch, "from", interval.From,
+				"to", interval.To, "final", interval.IsFinal(), "otherTo", previous.To, "otherFinal", previous.IsFinal())
 		}
 	}
 
@@ -56,6 +56,24 @@ func (f *FakeElasticClient) BulkIndex(_ context.Context, data []*elastic.EsDocum
 	}
 }
 
+func TestProcessor_Replay(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		tickIntervals: []*domain.TickInterval{{Epoch: 100, From: 1000, To: 1999}},
+	}
+	elasticClient := &FakeElasticClient{}
+	processor := NewProcessor(kafkaClient, elasticClient)
+
+	count, err := processor.Replay(context.Background(), func() bool { return kafkaClient.commitCount == 2 })
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, elasticClient.bulkIndexCount)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = processor.Replay(ctx, func() bool { return false })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestProcessor_Consume_GivenError_ThenReturnError(t *testing.T) {
 	kafkaClient := &FakeKafkaClient{
 		tickIntervals: []*domain.TickInterval{
@@ -250,3 +268,58 @@ func TestTickProcessor_consumeBatch_GivenInvalidOverlappingInterval_ThenError(t
 	require.ErrorContains(t, err, "conflicts")
 	require.Equal(t, 0, count)
 }
+
+func TestTickProcessor_consumeBatch_GivenProvisionalIntervals_ThenUpsert(t *testing.T) {
+	final, provisional := true, false
+	stored := func(to uint32, final *bool) *elastic.Interval {
+		return &elastic.Interval{Epoch: 1, From: 2, To: to, Final: final}
+	}
+	tests := []struct {
+		name     string
+		interval *domain.TickInterval
+		stored   *elastic.Interval
+		expected string // empty, if ignored
+	}{
+		{"new provisional", &domain.TickInterval{Epoch: 1, From: 2, To: 10, Final: &provisional}, nil, `{"epoch":1, "from":2, "to":10, "final":false}`},
+		{"grown provisional", &domain.TickInterval{Epoch: 1, From: 2, To: 10, Final: &provisional}, stored(8, &provisional), `{"epoch":1, "from":2, "to":10, "final":false}`},
+		{"same provisional", &domain.TickInterval{Epoch: 1, From: 2, To: 10, Final: &provisional}, stored(10, &provisional), ""},
+		{"finalized with same end", &domain.TickInterval{Epoch: 1, From: 2, To: 10, Final: &final}, stored(10, &provisional), `{"epoch":1, "from":2, "to":10, "final":true}`},
+		{"finalized with smaller end", &domain.TickInterval{Epoch: 1, From: 2, To: 9, Final: &final}, stored(10, &provisional), `{"epoch":1, "from":2, "to":9, "final":true}`},
+		{"late provisional", &domain.TickInterval{Epoch: 1, From: 2, To: 12, Final: &provisional}, stored(10, &final), ""},
+		{"late provisional after legacy", &domain.TickInterval{Epoch: 1, From: 2, To: 12, Final: &provisional}, stored(10, nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kafkaClient := &FakeKafkaClient{tickIntervals: []*domain.TickInterval{tt.interval}}
+			esClient := &FakeElasticClient{overlappingInterval: tt.stored}
+			processor := NewProcessor(kafkaClient, esClient)
+			_, err := processor.consumeBatch(context.Background())
+			require.NoError(t, err)
+			if tt.expected == "" {
+				assert.Empty(t, esClient.sentDocuments)
+			} else {
+				require.Len(t, esClient.sentDocuments, 1)
+				assert.Equal(t, "1-2", esClient.sentDocuments[0].Id)
+				assert.JSONEq(t, tt.expected, string(esClient.sentDocuments[0].Payload))
+			}
+		})
+	}
+}
+
+func TestTickProcessor_consumeBatch_GivenProvisionalAndFinalInBatch_ThenIndexFinal(t *testing.T) {
+	final, provisional := true, false
+	kafkaClient := &FakeKafkaClient{
+		tickIntervals: []*domain.TickInterval{
+			{Epoch: 1, From: 2, To: 10, Final: &provisional},
+			{Epoch: 1, From: 2, To: 10, Final: &final},
+			{Epoch: 1, From: 2, To: 11, Final: &provisional}, // late
+		},
+	}
+	esClient := &FakeElasticClient{}
+	processor := NewProcessor(kafkaClient, esClient)
+	count, err := processor.consumeBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Len(t, esClient.sentDocuments, 1)
+	assert.JSONEq(t, `{"epoch":1, "from":2, "to":10, "final":true}`, string(esClient.sentDocuments[0].Payload))
+}
@@ -4,4 +4,19 @@ type TickInterval struct {
 	Epoch uint32 `json:"epoch"`
 	From  uint32 `json:"from"`
 	To    uint32 `json:"to"`
+	Final *bool  `json:"final,omitempty"` // false for provisional intervals of the current epoch. Missing means final.
+}
+
+// IsFinal returns false for provisional intervals. Intervals without final flag are final.
+func (t *TickInterval) IsFinal() bool {
+	return t.Final == nil || *t.Final
+}
+
+// Replaces returns true, if the interval replaces the other interval with the same start tick. A final interval
+// replaces a provisional one, a provisional interval never replaces a final one. Otherwise, the larger one wins.
+func (t *TickInterval) Replaces(otherTo uint32, otherFinal bool) bool {
+	if t.IsFinal() != otherFinal {
+		return t.IsFinal()
+	}
+	return t.To > otherTo
 }
@@ -32,3 +32,31 @@ func TestTickInterval_convertToJson(t *testing.T) {
 
 	require.JSONEq(t, `{"epoch":42, "from":123, "to":456}`, string(val))
 }
+
+func TestTickInterval_convertProvisionalFromJson(t *testing.T) {
+	interval := TickInterval{}
+	err := json.Unmarshal([]byte(`{ "epoch":42, "from":123, "to":456, "final":false }`), &interval)
+	require.NoError(t, err)
+	assert.False(t, interval.IsFinal())
+
+	val, err := json.Marshal(interval)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"epoch":42, "from":123, "to":456, "final":false}`, string(val))
+}
+
+func TestTickInterval_Replaces(t *testing.T) {
+	final, provisional := true, false
+	legacy := &TickInterval{Epoch: 42, From: 100, To: 200}
+	assert.True(t, legacy.IsFinal())
+	assert.True(t, legacy.Replaces(199, true))
+	assert.False(t, legacy.Replaces(200, true))
+	assert.True(t, legacy.Replaces(300, false)) // final replaces provisional
+
+	grown := &TickInterval{Epoch: 42, From: 100, To: 200, Final: &provisional}
+	assert.True(t, grown.Replaces(199, false))
+	assert.False(t, grown.Replaces(200, false))
+	assert.False(t, grown.Replaces(100, true)) // provisional never replaces final
+
+	finalized := &TickInterval{Epoch: 42, From: 100, To: 200, Final: &final}
+	assert.True(t, finalized.Replaces(200, false))
+}
@@ -1,29 +1,20 @@
 package elastic
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"runtime"
-	"strings"
-	"time"
 
-	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/elastic/go-elasticsearch/v8/esutil"
-	"github.com/pkg/errors"
+	"github.com/qubic/sink"
 )
 
 type Client struct {
-	esClient  *elasticsearch.Client
+	sink      sink.Client
 	indexName string
 }
 
-func NewClient(esClient *elasticsearch.Client, indexName string) *Client {
+func NewClient(sinkClient sink.Client, indexName string) *Client {
 	return &Client{
-		esClient:  esClient,
+		sink:      sinkClient,
 		indexName: indexName,
 	}
 }
@@ -50,6 +41,12 @@ type Interval struct {
 	Epoch uint32 `json:"epoch"`
 	From  uint32 `json:"from"`
 	To    uint32 `json:"to"`
+	Final *bool  `json:"final,omitempty"`
+}
+
+// IsFinal returns false for provisional intervals. Intervals without final flag are final.
+func (i *Interval) IsFinal() bool {
+	return i.Final == nil || *i.Final
 }
 
 func (c *Client) FindOverlappingInterval(ctx context.Context, epoch, from, to uint32) (*Interval, error) {
@@ -57,30 +54,12 @@ func (c *Client) FindOverlappingInterval(ctx context.Context, epoch, from, to ui
 	// (theoretically we could also have intervals that overlap and start before the start tick but that
 	// should not happen and would be a severe data inconsistency)
 	query := fmt.Sprintf(
-		`{ "query": { "bool": { "must": [ { "term": { "epoch": %d } }, { "range": { "from": { "gte": %d, "lte": %d } } } ] } } }`, epoch, from, to)
-
-	res, err := c.esClient.Search(
-		c.esClient.Search.WithContext(ctx),
-		c.esClient.Search.WithIndex(c.indexName),
-		c.esClient.Search.WithBody(strings.NewReader(query)),
-		c.esClient.Search.WithTrackTotalHits(2),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("performing search: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Printf("Error closing response body: %v", err)
-		}
-	}(res.Body)
+		`{ "track_total_hits": 2, "query": { "bool": { "must": [ { "term": { "epoch": %d } }, { "range": { "from": { "gte": %d, "lte": %d } } } ] } } }`, epoch, from, to)
 
-	if res.IsError() {
-		return nil, fmt.Errorf("got error response from elastic: %s", res.String())
-	}
 	var result searchResponse
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
+	err := c.sink.Search(ctx, c.indexName, query, &result)
+	if err != nil {
+		return nil, err
 	}
 
 	if result.Hits.Total.Value == 1 {
@@ -94,55 +73,15 @@ func (c *Client) FindOverlappingInterval(ctx context.Context, epoch, from, to ui
 	}
 }
 
+// BulkIndex waits for the refresh so that the consumer can check before the next update.
 func (c *Client) BulkIndex(ctx context.Context, data []*EsDocument) error {
-	start := time.Now().UnixMilli()
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Refresh:    "wait_for",               // wait for refresh so that the consumer can check before the next update
-		Index:      c.indexName,              // The default index name
-		Client:     c.esClient,               // The Elasticsearch client
-		NumWorkers: min(runtime.NumCPU(), 8), // 8 parallel connections are enough
-	})
-	if err != nil {
-		return errors.Wrap(err, "Error creating bulk indexer")
-	}
+	return c.sink.BulkIndexAndWait(ctx, documents(data), c.indexName)
+}
 
+func documents(data []*EsDocument) []sink.Document {
+	result := make([]sink.Document, 0, len(data))
 	for _, d := range data {
-		item := esutil.BulkIndexerItem{
-			Action:       "index", // creates or replaces
-			DocumentID:   d.Id,
-			RequireAlias: true,
-			Body:         bytes.NewReader(d.Payload),
-			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
-				msg := "Error indexing document"
-				if err != nil {
-					log.Printf("%s [%s]: %s: [%s]", msg, d.Id, string(d.Payload), err)
-				} else {
-					log.Printf("%s [%s]: %s: [%s: %s]", msg, d.Id, string(d.Payload), res.Error.Type, res.Error.Reason)
-				}
-			},
-		}
-		err = bi.Add(ctx, item)
-	}
-
-	err = bi.Close(ctx)
-	if err != nil {
-		return errors.Wrap(err, "Error closing bulk indexer")
-	}
-
-	biStats := bi.Stats()
-	end := time.Now().UnixMilli()
-	if biStats.NumFailed > 0 {
-		return errors.Errorf("%d errors indexing [%d] documents",
-			biStats.NumFailed,
-			biStats.NumFlushed,
-		)
-	} else {
-		log.Printf("Indexed %d documents (%d bytes, %d requests) in %dms.",
-			biStats.NumFlushed,
-			biStats.FlushedBytes,
-			biStats.NumRequests,
-			end-start,
-		)
+		result = append(result, sink.Document{Id: d.Id, Payload: d.Payload})
 	}
-	return nil
+	return result
 }
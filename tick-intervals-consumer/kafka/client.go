@@ -5,21 +5,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-intervals-consumer/domain"
 	"github.com/qubic/tick-intervals-consumer/metrics"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
 )
 
+// RecordClient polls the records. It is the kgo client of the consumer group or a replay client.
+type RecordClient interface {
+	PollRecords(ctx context.Context, maxPollRecords int) kgo.Fetches
+	CommitUncommittedOffsets(ctx context.Context) error
+	AllowRebalance()
+}
+
 type Client struct {
-	kcl                *kgo.Client
+	kcl                RecordClient
 	consumeMetrics     *metrics.Metrics
 	lastProcessedEpoch uint32
 	lastProcessedTick  uint32
 }
 
-func NewClient(kafkaClient *kgo.Client, metrics *metrics.Metrics) *Client {
+func NewClient(kafkaClient RecordClient, metrics *metrics.Metrics) *Client {
 	return &Client{
 		kcl:            kafkaClient,
 		consumeMetrics: metrics,
@@ -31,7 +39,7 @@ func (c *Client) PollMessages(ctx context.Context) ([]*domain.TickInterval, erro
 	if errs := fetches.Errors(); len(errs) > 0 {
 		// only non-retryable errors are returned.
 		for _, err := range errs {
-			log.Printf("Error: %v", err)
+			zap.S().Errorw("Fetching records failed.", "topic", err.Topic, logging.Partition, err.Partition, logging.Error, err.Err)
 		}
 		return nil, errors.New("fetching records")
 	}
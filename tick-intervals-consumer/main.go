@@ -13,10 +13,12 @@ import (
 	"time"
 
 	"github.com/ardanlabs/conf"
-	"github.com/elastic/elastic-transport-go/v8/elastictransport"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/logging"
+	"github.com/qubic/replay"
+	"github.com/qubic/sink"
 	"github.com/qubic/tick-intervals-consumer/consume"
 	"github.com/qubic/tick-intervals-consumer/elastic"
 	"github.com/qubic/tick-intervals-consumer/kafka"
@@ -24,20 +26,24 @@ import (
 	"github.com/qubic/tick-intervals-consumer/status"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_TICK_INTERVALS_CONSUMER"
 
 func main() {
-	log.SetOutput(os.Stdout)
-	log.Println("Starting tick-intervals-consumer")
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("tick-intervals-consumer", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	logger.Info("Starting tick-intervals-consumer.")
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Elastic struct {
 			Addresses   []string `conf:"default:https://localhost:9200"`
@@ -46,7 +52,9 @@ func run() error {
 			IndexName   string   `conf:"default:qubic-tick-intervals-alias"`
 			Certificate string   `conf:"default:http_ca.crt"`
 			MaxRetries  int      `conf:"default:25"`
-			Stub        bool     `conf:"optional"` // only for testing
+			Sink        string   `conf:"default:elasticsearch"` // elasticsearch, opensearch or ndjson
+			BulkUrl     string   `conf:"optional"`              // bulk endpoint of the ndjson sink. Defaults to <address>/_bulk.
+			Stub        bool     `conf:"optional"`              // only for testing
 		}
 		Broker struct {
 			BootstrapServers []string `conf:"default:localhost:9092"`
@@ -58,6 +66,13 @@ func run() error {
 			MetricsNamespace string `conf:"default:qubic_kafka"`
 			Enabled          bool   `conf:"default:true"` // only for testing
 		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
+		Replay struct {
+			replay.Options
+		}
+		Args conf.Args
 	}
 
 	// read config
@@ -78,33 +93,20 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("generating config for output: %w", err)
 	}
-	log.Printf("main: Config :\n%v\n", out)
-
-	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
-		kprom.Registerer(prometheus.DefaultRegisterer),
-		kprom.Gatherer(prometheus.DefaultGatherer))
-	kcl, err := kgo.NewClient(
-		kgo.WithHooks(m),
-		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
-		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
-		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
-		kgo.BlockRebalanceOnPoll(),
-		kgo.DisableAutoCommit(),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
-	)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("setting log level: %w", err)
 	}
-	defer kcl.Close()
 
 	cert, err := os.ReadFile(cfg.Elastic.Certificate)
 	if err != nil {
-		log.Printf("[WARN] main: could not read elastic certificate: %v", err)
+		zap.S().Warnw("Could not read elastic certificate.", logging.Error, err)
 	}
 
 	var elasticClient consume.ElasticClient
 	if cfg.Elastic.Stub {
-		log.Printf("[WARN] main: stubbing elastic client") // only for testing kafka consumer
+		zap.S().Warn("Stubbing elastic client.") // only for testing kafka consumer
 		elasticClient = &ElasticStubClient{}
 	} else {
 		esClient, err := elasticsearch.NewClient(elasticsearch.Config{
@@ -115,19 +117,62 @@ func run() error {
 			RetryOnStatus: []int{502, 503, 504, 429},
 			MaxRetries:    cfg.Elastic.MaxRetries,
 			RetryBackoff:  calculateBackoff(),
-			Logger: elastic.NewLogger(&elastictransport.TextLogger{
-				Output:             os.Stdout,
-				EnableRequestBody:  false,
-				EnableResponseBody: true, // Make response body available in the logger so we can log response
-			}),
+			Logger:        elastic.NewLogger(),
 		})
 		if err != nil {
 			return fmt.Errorf("creating elastic client: %w", err)
 		}
-		elasticClient = elastic.NewClient(esClient, cfg.Elastic.IndexName)
+		sinkClient, err := sink.NewClient(context.Background(), esClient, sink.HttpBulkConfig{
+			Sink:         cfg.Elastic.Sink,
+			Addresses:    cfg.Elastic.Addresses,
+			BulkUrl:      cfg.Elastic.BulkUrl,
+			Username:     cfg.Elastic.Username,
+			Password:     cfg.Elastic.Password,
+			CACert:       cert,
+			MaxRetries:   cfg.Elastic.MaxRetries,
+			RetryBackoff: calculateBackoff(),
+		})
+		if err != nil {
+			return err
+		}
+		elasticClient = elastic.NewClient(sinkClient, cfg.Elastic.IndexName)
 	}
 
 	consumeMetrics := metrics.NewMetrics(cfg.Sync.MetricsNamespace)
+
+	if len(cfg.Args) > 0 {
+		// the records are keyed by epoch, so there is no tick range
+		replayConfig, err := replay.NewConfig(cfg.Broker.ConsumeTopic, cfg.Replay.Options, replay.TickRangeOptions{})
+		if err != nil {
+			return fmt.Errorf("parsing replay config: %w", err)
+		}
+		return runReplayCommand(context.Background(), cfg.Args, replayCommand{
+			config:        replayConfig,
+			brokers:       cfg.Broker.BootstrapServers,
+			dryRun:        cfg.Replay.DryRun,
+			elasticClient: elasticClient,
+			indexName:     cfg.Elastic.IndexName,
+			metrics:       consumeMetrics,
+		})
+	}
+
+	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
+		kprom.Registerer(prometheus.DefaultRegisterer),
+		kprom.Gatherer(prometheus.DefaultGatherer))
+	kcl, err := kgo.NewClient(
+		kgo.WithHooks(m),
+		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
+		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
+		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.DisableAutoCommit(),
+		kgo.WithLogger(logging.NewKafkaLogger()),
+	)
+	if err != nil {
+		return fmt.Errorf("creating kafka client: %w", err)
+	}
+	defer kcl.Close()
+
 	consumer := kafka.NewClient(kcl, consumeMetrics)
 	processor := consume.NewProcessor(consumer, elasticClient)
 
@@ -137,7 +182,7 @@ func run() error {
 			procError <- processor.Consume()
 		}()
 	} else {
-		log.Println("[WARN] main: Message consuming disabled") // only for testing startup
+		zap.S().Warn("Message consuming disabled.") // only for testing startup
 	}
 
 	shutdown := make(chan os.Signal, 1)
@@ -146,27 +191,26 @@ func run() error {
 	// status and metrics endpoint
 	serverError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting health and metrics endpoint on port [%d].", cfg.Sync.MetricsPort)
+		zap.S().Infow("Starting health, metrics and log level endpoint.", "port", cfg.Sync.MetricsPort)
 		http.HandleFunc("/health", status.Health)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		serverError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-procError:
-			return fmt.Errorf("[ERROR] processing error: %v", err)
+			return fmt.Errorf("processing: %w", err)
 		case err := <-serverError:
-			return fmt.Errorf("[ERROR] starting server: %v", err)
+			return fmt.Errorf("starting server: %w", err)
 		}
 	}
-
-	return nil
 }
 
 // calculateBackoff needs retry number because of multi threading
@@ -178,7 +222,7 @@ func calculateBackoff() func(i int) time.Duration {
 		} else {
 			d = time.Second*30 + randomMillis()
 		}
-		log.Printf("[WARN] elasticsearch client retry [%d] in %v.", i, d)
+		zap.S().Warnw("Retrying elasticsearch request.", "attempt", i, logging.Duration, d)
 		return d
 	}
 }
@@ -190,11 +234,11 @@ func randomMillis() time.Duration {
 type ElasticStubClient struct{}
 
 func (e ElasticStubClient) FindOverlappingInterval(_ context.Context, _, _, _ uint32) (*elastic.Interval, error) {
-	log.Println("[WARN] main: elastic client stubbed! Returning no data.")
+	zap.S().Warn("Elastic client stubbed. Returning no data.")
 	return nil, nil
 }
 
 func (e ElasticStubClient) BulkIndex(_ context.Context, data []*elastic.EsDocument) error {
-	log.Printf("[WARN] main: elastic client stubbed! Skipping [%d] documents.", len(data))
+	zap.S().Warnw("Elastic client stubbed. Skipping documents.", "documents", len(data))
 	return nil
 }
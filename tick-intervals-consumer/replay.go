@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ardanlabs/conf"
+	"github.com/qubic/replay"
+	"github.com/qubic/tick-intervals-consumer/consume"
+	"github.com/qubic/tick-intervals-consumer/elastic"
+	"github.com/qubic/tick-intervals-consumer/kafka"
+	"github.com/qubic/tick-intervals-consumer/metrics"
+	"go.uber.org/zap"
+)
+
+type replayCommand struct {
+	config        replay.Config
+	brokers       []string
+	dryRun        bool
+	elasticClient consume.ElasticClient
+	indexName     string
+	metrics       *metrics.Metrics
+}
+
+// runReplayCommand re-indexes a range of the topic into the configured index. The live consumer group is not used, so
+// the service can keep running during the replay.
+func runReplayCommand(ctx context.Context, args conf.Args, cmd replayCommand) error {
+	if args.Num(0) != "replay" {
+		return fmt.Errorf("unknown command [%s]\n%s", args.Num(0), replay.Usage)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := replay.NewClient(ctx, cmd.brokers, cmd.config)
+	if err != nil {
+		return fmt.Errorf("creating replay client: %w", err)
+	}
+	defer client.Close()
+
+	elasticClient := cmd.elasticClient
+	var counter *countingClient
+	if cmd.dryRun {
+		counter = &countingClient{ElasticClient: elasticClient, counter: replay.NewCounter(), indexName: cmd.indexName}
+		elasticClient = counter
+	}
+
+	processor := consume.NewProcessor(kafka.NewClient(client, cmd.metrics), elasticClient)
+	count, err := processor.Replay(ctx, client.Done)
+	if err != nil {
+		return fmt.Errorf("replaying after [%d] intervals: %w", count, err)
+	}
+	zap.S().Infow("Replay finished.", "intervals", count)
+	if counter != nil {
+		counter.counter.Print(os.Stdout, "documents")
+	}
+	return nil
+}
+
+// countingClient replaces the indexing of the elastic client for dry runs. It only counts the documents. Stored
+// intervals are still read from the index.
+type countingClient struct {
+	consume.ElasticClient
+	counter   *replay.Counter
+	indexName string
+}
+
+func (c *countingClient) BulkIndex(_ context.Context, data []*elastic.EsDocument) error {
+	c.counter.Add(c.indexName, len(data))
+	return nil
+}
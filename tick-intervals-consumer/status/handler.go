@@ -1,8 +1,10 @@
 package status
 
 import (
-	"log"
 	"net/http"
+
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
@@ -12,7 +14,7 @@ func Health(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 	_, err := w.Write([]byte("{\"status\":\"UP\"}"))
 	if err != nil {
-		log.Printf("Error writing status response: %v", err)
+		zap.S().Errorw("Writing status response failed.", logging.Error, err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 type HealthResponse struct {
@@ -16,7 +18,7 @@ func Health(w http.ResponseWriter, _ *http.Request) {
 		Status: "UP",
 	})
 	if err != nil {
-		log.Printf("Error encoding response: %v", err)
+		zap.S().Errorw("Encoding response failed.", logging.Error, err)
 		http.Error(w, "Error encoding response", 500)
 		return
 	}
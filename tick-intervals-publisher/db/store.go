@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"path/filepath"
 
 	"github.com/cockroachdb/pebble/v2"
+	"github.com/qubic/logging"
+	"go.uber.org/zap"
 )
 
 var ErrNotFound = errors.New("store resource not found")
@@ -46,7 +47,7 @@ func (ps *PebbleStore) GetLastProcessedEpoch() (uint32, error) {
 
 	value, closer, err := ps.db.Get(key)
 	if errors.Is(err, pebble.ErrNotFound) {
-		log.Printf("[WARN]: No last processed epoch has been found.")
+		zap.S().Warn("No last processed epoch has been found.")
 		return 0, ErrNotFound
 	}
 	if err != nil {
@@ -55,7 +56,7 @@ func (ps *PebbleStore) GetLastProcessedEpoch() (uint32, error) {
 	defer func(closer io.Closer) {
 		err := closer.Close()
 		if err != nil {
-			log.Printf("[ERROR]: Failed to close database get request.")
+			zap.S().Errorw("Closing database get request failed.", logging.Error, err)
 		}
 	}(closer)
 
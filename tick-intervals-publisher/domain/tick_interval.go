@@ -4,4 +4,5 @@ type TickInterval struct {
 	Epoch uint32 `json:"epoch"`
 	From  uint32 `json:"from"`
 	To    uint32 `json:"to"`
+	Final *bool  `json:"final,omitempty"` // only set, if provisional intervals are published. False, if the interval might still grow.
 }
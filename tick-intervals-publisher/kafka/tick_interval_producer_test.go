@@ -21,3 +21,10 @@ func TestTickIntervalProducer_createRecord(t *testing.T) {
 	require.Equal(t, 1, int(binary.LittleEndian.Uint32(record.Key)))
 	require.JSONEq(t, `{"epoch": 1, "from": 2, "to": 3}`, string(record.Value))
 }
+
+func TestTickIntervalProducer_createRecord_Provisional(t *testing.T) {
+	final := false
+	record, err := createRecord(&domain.TickInterval{Epoch: 1, From: 2, To: 3, Final: &final})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"epoch": 1, "from": 2, "to": 3, "final": false}`, string(record.Value))
+}
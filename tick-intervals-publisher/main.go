@@ -12,6 +12,7 @@ import (
 	"github.com/ardanlabs/conf"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-intervals-publisher/api"
 	"github.com/qubic/tick-intervals-publisher/archiverv1"
 	"github.com/qubic/tick-intervals-publisher/archiverv2"
@@ -21,20 +22,24 @@ import (
 	"github.com/qubic/tick-intervals-publisher/processing"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_TICK_INTERVALS_PUBLISHER"
 
 func main() {
-	log.SetOutput(os.Stdout)
-	log.Println("Starting tick-intervals-publisher")
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("tick-intervals-publisher", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	logger.Info("Starting tick-intervals-publisher.")
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-
+func run(logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		Client struct {
 			ArchiverGrpcHost string `conf:"default:localhost:8010"`
@@ -49,8 +54,12 @@ func run() error {
 			MetricsPort         int      `conf:"default:9999"`
 			MetricsNamespace    string   `conf:"default:qubic_kafka"`
 			PublishCustomEpochs []uint32 `conf:"optional"`
-			StartEpoch          uint32   `conf:"optional"`     // overrides last processed epoch
-			Enabled             bool     `conf:"default:true"` // only for testing
+			StartEpoch          uint32   `conf:"optional"`      // overrides last processed epoch
+			Enabled             bool     `conf:"default:true"`  // only for testing
+			PublishProvisional  bool     `conf:"default:false"` // publish the growing intervals of the current epoch
+		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
 		}
 	}
 
@@ -72,7 +81,11 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("generating config for output: %w", err)
 	}
-	log.Printf("main: Config :\n%v\n", out)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
+	if err != nil {
+		return fmt.Errorf("setting log level: %w", err)
+	}
 
 	m := kprom.NewMetrics(cfg.Sync.MetricsNamespace,
 		kprom.Registerer(prometheus.DefaultRegisterer),
@@ -82,10 +95,10 @@ func run() error {
 		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
 		kgo.DefaultProduceTopic(cfg.Broker.ProduceTopic),
 		kgo.ProducerBatchCompression(kgo.ZstdCompression()),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
+		kgo.WithLogger(logging.NewKafkaLogger()),
 	)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("creating kafka client: %w", err)
 	}
 	defer kcl.Close()
 
@@ -97,7 +110,7 @@ func run() error {
 	_, err = store.GetLastProcessedEpoch()
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
-			log.Println("[INFO]: Initializing database with last processed epoch 0.")
+			zap.S().Info("Initializing database with last processed epoch 0.")
 			err = store.SetLastProcessedEpoch(0)
 			if err != nil {
 				return fmt.Errorf("initializing last processed epoch: %w", err)
@@ -107,7 +120,7 @@ func run() error {
 		}
 	}
 	if cfg.Sync.StartEpoch > 0 {
-		log.Printf("[INFO]: Overriding last processed epoch with [%d]", cfg.Sync.StartEpoch-1)
+		zap.S().Infow("Overriding last processed epoch.", logging.Epoch, cfg.Sync.StartEpoch-1)
 		err = store.SetLastProcessedEpoch(cfg.Sync.StartEpoch - 1)
 		if err != nil {
 			return fmt.Errorf("setting last processed epoch: %w", err)
@@ -116,7 +129,7 @@ func run() error {
 
 	var cl processing.ArchiveClient
 	if cfg.Client.Legacy {
-		log.Println("[INFO] Connecting to legacy archiver...")
+		zap.S().Info("Connecting to legacy archiver.")
 		cl, err = archiverv1.NewClient(cfg.Client.ArchiverGrpcHost)
 	} else {
 		cl, err = archiverv2.NewClient(cfg.Client.ArchiverGrpcHost)
@@ -127,11 +140,11 @@ func run() error {
 
 	producer := kafka.NewTickIntervalProducer(kcl)
 	procMetrics := metrics.NewProcessingMetrics(cfg.Sync.MetricsNamespace)
-	processor := processing.NewTickIntervalProcessor(store, cl, producer, procMetrics)
+	processor := processing.NewTickIntervalProcessor(store, cl, producer, procMetrics, cfg.Sync.PublishProvisional)
 
 	procErr := make(chan error, 1)
 	if !cfg.Sync.Enabled {
-		log.Println("[WARN] main: producing messages disabled")
+		zap.S().Warn("Producing messages disabled.")
 	} else if len(cfg.Sync.PublishCustomEpochs) > 0 {
 		go func() { procErr <- processor.PublishCustomEpochs(cfg.Sync.PublishCustomEpochs) }()
 	} else {
@@ -144,28 +157,29 @@ func run() error {
 	// health and metrics endpoint
 	apiError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting http server on port [%d].", cfg.Sync.MetricsPort)
+		zap.S().Infow("Starting http server.", "port", cfg.Sync.MetricsPort)
 		http.HandleFunc("/health", api.Health)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		apiError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Sync.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			return nil
 		case err := <-procErr:
 			if err != nil {
-				return fmt.Errorf("[ERROR] processing: %v", err)
+				return fmt.Errorf("processing: %w", err)
 			} else {
-				log.Printf("main: Finished processing.")
+				zap.S().Info("Finished processing.")
 				return nil
 			}
 		case err := <-apiError:
-			return fmt.Errorf("[ERROR] starting http server: %v", err)
+			return fmt.Errorf("starting http server: %w", err)
 		}
 	}
 
@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"slices"
 	"time"
 
+	"github.com/qubic/logging"
 	"github.com/qubic/tick-intervals-publisher/domain"
 	"github.com/qubic/tick-intervals-publisher/metrics"
 	"github.com/twmb/franz-go/pkg/kerr"
+	"go.uber.org/zap"
 )
 
 type ArchiveClient interface {
@@ -31,16 +32,22 @@ type TickIntervalProcessor struct {
 	dataStore         DataStore
 	producer          Producer
 	processingMetrics *metrics.ProcessingMetrics
+	provisional       bool              // publish the intervals of the current epoch
+	publishedTo       map[uint32]uint32 // end tick of the published provisional intervals by start tick
 }
 
+// NewTickIntervalProcessor creates the processor. If provisional is set, the intervals of the current epoch are
+// published, whenever they grow, and all intervals carry the final flag.
 func NewTickIntervalProcessor(db DataStore, client ArchiveClient, producer Producer,
-	m *metrics.ProcessingMetrics) *TickIntervalProcessor {
+	m *metrics.ProcessingMetrics, provisional bool) *TickIntervalProcessor {
 
 	tdp := TickIntervalProcessor{
 		dataStore:         db,
 		archiveClient:     client,
 		producer:          producer,
 		processingMetrics: m,
+		provisional:       provisional,
+		publishedTo:       make(map[uint32]uint32),
 	}
 	return &tdp
 }
@@ -51,7 +58,7 @@ func (p *TickIntervalProcessor) StartProcessing() error {
 	if err != nil {
 		return err
 	}
-	log.Println("Initial processing completed. Starting loop...")
+	zap.S().Info("Initial processing completed. Starting loop.")
 	ticker := time.Tick(15 * time.Second)
 	for range ticker {
 		err = p.process()
@@ -71,13 +78,13 @@ func (p *TickIntervalProcessor) process() error {
 			}
 		}
 		// only exit, if non-retriable kafka error
-		log.Printf("Error processing tick intervals: %v", err)
+		zap.S().Errorw("Processing tick intervals failed.", logging.Error, err)
 	}
 	return nil
 }
 
 func (p *TickIntervalProcessor) PublishCustomEpochs(epochs []uint32) error {
-	log.Printf("[INFO] publishing custom epochs: %v", epochs)
+	zap.S().Infow("Publishing custom epochs.", "epochs", epochs)
 	ctx := context.Background()
 	status, err := p.archiveClient.GetStatus(ctx)
 	if err != nil {
@@ -90,7 +97,7 @@ func (p *TickIntervalProcessor) PublishCustomEpochs(epochs []uint32) error {
 			if err != nil {
 				return fmt.Errorf("sending tick interval: %w", err)
 			}
-			log.Printf("[INFO] Published interval for epoch [%d].", interval.Epoch)
+			zap.S().Infow("Published interval.", logging.Epoch, interval.Epoch)
 		}
 	}
 	return nil
@@ -121,8 +128,18 @@ func (p *TickIntervalProcessor) processIntervals() error {
 
 	for _, interval := range intervals {
 
-		if interval.Epoch < status.LatestEpoch { // ignore current epoch
+		if interval.Epoch >= status.LatestEpoch { // current epoch
+			if p.provisional {
+				err = p.sendProvisionalTickInterval(ctx, interval)
+				if err != nil {
+					return fmt.Errorf("sending provisional tick interval [%v]: %w", interval, err)
+				}
+			}
+		} else {
 
+			if p.provisional {
+				interval.Final = new(true)
+			}
 			err = p.sendTickInterval(ctx, interval)
 			if err != nil {
 				return fmt.Errorf("sending tick interval [%v]: %w", interval, err)
@@ -135,6 +152,7 @@ func (p *TickIntervalProcessor) processIntervals() error {
 				}
 				processedEpoch = interval.Epoch
 			}
+			delete(p.publishedTo, interval.From)
 
 		}
 
@@ -144,8 +162,23 @@ func (p *TickIntervalProcessor) processIntervals() error {
 
 }
 
+// sendProvisionalTickInterval sends the interval, if it grew since it was sent last.
+func (p *TickIntervalProcessor) sendProvisionalTickInterval(ctx context.Context, interval *domain.TickInterval) error {
+	if to, ok := p.publishedTo[interval.From]; ok && to >= interval.To {
+		return nil // unchanged
+	}
+	interval.Final = new(false)
+	err := p.sendTickInterval(ctx, interval)
+	if err != nil {
+		return err
+	}
+	p.publishedTo[interval.From] = interval.To
+	return nil
+}
+
 func (p *TickIntervalProcessor) sendTickInterval(ctx context.Context, interval *domain.TickInterval) error {
-	log.Printf("[INFO] processing interval for epoch [%d] from tick [%d] to [%d].", interval.Epoch, interval.From, interval.To)
+	zap.S().Infow("Processing interval.", logging.Epoch, interval.Epoch, "from", interval.From, "to", interval.To,
+		"final", interval.Final)
 	err := p.producer.SendMessage(ctx, interval)
 	if err != nil {
 		return fmt.Errorf("sending message: %w", err)
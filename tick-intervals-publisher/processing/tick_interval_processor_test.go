@@ -72,7 +72,7 @@ func TestTickIntervalProcessor_process(t *testing.T) {
 		intervals:    intervals,
 	}
 	producer := &FakeProducer{}
-	proc := NewTickIntervalProcessor(db, client, producer, m)
+	proc := NewTickIntervalProcessor(db, client, producer, m, false)
 
 	err := proc.processIntervals()
 	require.NoError(t, err)
@@ -109,7 +109,7 @@ func TestTickIntervalProcessor_StartProcessing_NonRetriableKafkaError(t *testing
 	// non-retriable Kafka error
 	nonRetriableErr := kerr.MessageTooLarge
 	producer := &FakeProducerWithError{err: nonRetriableErr}
-	proc := NewTickIntervalProcessor(db, client, producer, m)
+	proc := NewTickIntervalProcessor(db, client, producer, m, false)
 
 	// run with a timeout
 	errChan := make(chan error, 1)
@@ -144,7 +144,7 @@ func TestTickIntervalProcessor_StartProcessing_RetriableKafkaError(t *testing.T)
 	// retriable Kafka error
 	retriableErr := kerr.LeaderNotAvailable
 	producer := &FakeProducerWithError{err: retriableErr}
-	proc := NewTickIntervalProcessor(db, client, producer, m)
+	proc := NewTickIntervalProcessor(db, client, producer, m, false)
 
 	// run with a short timeout
 	errChan := make(chan error, 1)
@@ -161,3 +161,51 @@ func TestTickIntervalProcessor_StartProcessing_RetriableKafkaError(t *testing.T)
 		// This is expected - StartProcessing continues running with retriable errors
 	}
 }
+
+func TestTickIntervalProcessor_process_Provisional(t *testing.T) {
+	db := &FakeDataStore{epoch: 99}
+	client := &FakeArchiveClient{
+		currentEpoch: 101,
+		currentTick:  6500,
+		intervals: []*domain.TickInterval{
+			{Epoch: 100, From: 1000, To: 1999},
+			{Epoch: 101, From: 6001, To: 6500},
+		},
+	}
+	producer := &FakeProducer{}
+	proc := NewTickIntervalProcessor(db, client, producer, m, true)
+
+	err := proc.processIntervals()
+	require.NoError(t, err)
+	require.Len(t, producer.sent, 2)
+	assert.Equal(t, &domain.TickInterval{Epoch: 100, From: 1000, To: 1999, Final: new(true)}, producer.sent[0])
+	assert.Equal(t, &domain.TickInterval{Epoch: 101, From: 6001, To: 6500, Final: new(false)}, producer.sent[1])
+	assert.Equal(t, uint32(100), db.epoch)
+
+	// unchanged
+	client.intervals = []*domain.TickInterval{{Epoch: 101, From: 6001, To: 6500}}
+	err = proc.processIntervals()
+	require.NoError(t, err)
+	require.Len(t, producer.sent, 2)
+
+	// grown
+	client.intervals = []*domain.TickInterval{{Epoch: 101, From: 6001, To: 6600}}
+	err = proc.processIntervals()
+	require.NoError(t, err)
+	require.Len(t, producer.sent, 3)
+	assert.Equal(t, &domain.TickInterval{Epoch: 101, From: 6001, To: 6600, Final: new(false)}, producer.sent[2])
+
+	// epoch ended. finalize.
+	client.currentEpoch = 102
+	client.intervals = []*domain.TickInterval{
+		{Epoch: 101, From: 6001, To: 6699},
+		{Epoch: 102, From: 6700, To: 6700},
+	}
+	err = proc.processIntervals()
+	require.NoError(t, err)
+	require.Len(t, producer.sent, 5)
+	assert.Equal(t, &domain.TickInterval{Epoch: 101, From: 6001, To: 6699, Final: new(true)}, producer.sent[3])
+	assert.Equal(t, &domain.TickInterval{Epoch: 102, From: 6700, To: 6700, Final: new(false)}, producer.sent[4])
+	assert.Equal(t, uint32(101), db.epoch)
+	assert.NotContains(t, proc.publishedTo, uint32(6001))
+}
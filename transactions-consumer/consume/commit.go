@@ -0,0 +1,133 @@
+package consume
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qubic/logging"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+// Revocations collects the partitions, that the consumer group revoked or lost. Register Revoked with
+// kgo.OnPartitionsRevoked and kgo.OnPartitionsLost. The kafka client calls it from its own goroutine. The consumer
+// removes the state of the partitions after the next poll, so that it neither commits stale offsets nor indexes
+// buffered ticks of partitions, that are now consumed by another member of the group.
+type Revocations struct {
+	mu         sync.Mutex
+	partitions []topicPartition
+}
+
+// Revoked adds the revoked or lost partitions.
+func (r *Revocations) Revoked(_ context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for topic, partitions := range revoked {
+		for _, partition := range partitions {
+			r.partitions = append(r.partitions, topicPartition{topic: topic, partition: partition})
+		}
+	}
+}
+
+func (r *Revocations) take() []topicPartition {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	partitions := r.partitions
+	r.partitions = nil
+	return partitions
+}
+
+// removeRevokedPartitions removes the polled records, commit positions and buffered ticks and fragments of the
+// revoked partitions. Their records are consumed again by the new owner from the last committed offset.
+func (c *TransactionConsumer) removeRevokedPartitions() {
+	if c.revocations == nil {
+		return
+	}
+	for _, partition := range c.revocations.take() {
+		delete(c.polled, partition)
+		delete(c.commitPositions, partition)
+		delete(c.commitBlocked, partition)
+		delete(c.highWatermarks, partition)
+		c.ticks.removePartition(partition)
+		c.reassembler.removePartition(partition)
+		c.consumerMetrics.SetCommitBlocked(partition.topic, partition.partition, 0)
+		zap.S().Infow("Partition revoked.", "topic", partition.topic, logging.Partition, partition.partition)
+	}
+	c.consumerMetrics.SetPendingTicks(c.ticks.incomplete())
+	c.consumerMetrics.SetPendingFragments(c.reassembler.fragments())
+}
+
+// trackPolled remembers the latest polled record per partition.
+func (c *TransactionConsumer) trackPolled(record *kgo.Record) {
+	if c.polled == nil {
+		c.polled = make(map[topicPartition]*kgo.Record)
+	}
+	c.polled[topicPartition{topic: record.Topic, partition: record.Partition}] = record
+}
+
+// commit commits the polled records per partition. Records that are still buffered (incomplete ticks or payloads)
+// and all records after them are not committed, so that they are consumed again after a restart. The other partitions
+// are not affected.
+func (c *TransactionConsumer) commit(ctx context.Context) error {
+	pending := c.reassembler.pendingOffsets()
+	for partition, offset := range c.ticks.pendingOffsets() {
+		if fragmentOffset, ok := pending[partition]; !ok || offset < fragmentOffset {
+			pending[partition] = offset
+		}
+	}
+
+	var records []*kgo.Record
+	for partition, polled := range c.polled {
+		next := polled.Offset + 1
+		if offset, ok := pending[partition]; ok {
+			next = min(next, offset)
+		}
+		c.observeCommitBlocked(partition, next <= c.commitPositions[partition] && polled.Offset >= c.commitPositions[partition])
+		if next <= c.commitPositions[partition] {
+			continue // nothing new to commit
+		}
+		// the record only carries the commit position
+		records = append(records, &kgo.Record{
+			Topic:       partition.topic,
+			Partition:   partition.partition,
+			Offset:      next - 1,
+			LeaderEpoch: polled.LeaderEpoch,
+		})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	err := c.kafkaClient.CommitRecords(ctx, records...)
+	if err != nil {
+		return errors.Wrap(err, "committing offsets")
+	}
+	if c.commitPositions == nil {
+		c.commitPositions = make(map[topicPartition]int64)
+	}
+	for _, record := range records {
+		c.commitPositions[topicPartition{topic: record.Topic, partition: record.Partition}] = record.Offset + 1
+	}
+	return nil
+}
+
+// observeCommitBlocked updates the time since buffered records prevent the commit position of the partition from
+// advancing.
+func (c *TransactionConsumer) observeCommitBlocked(partition topicPartition, blocked bool) {
+	if c.commitBlocked == nil {
+		c.commitBlocked = make(map[topicPartition]time.Time)
+	}
+	if !blocked {
+		delete(c.commitBlocked, partition)
+		c.consumerMetrics.SetCommitBlocked(partition.topic, partition.partition, 0)
+		return
+	}
+	since, ok := c.commitBlocked[partition]
+	if !ok {
+		since = time.Now()
+		c.commitBlocked[partition] = since
+	}
+	c.consumerMetrics.SetCommitBlocked(partition.topic, partition.partition, time.Since(since))
+}
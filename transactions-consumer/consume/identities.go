@@ -0,0 +1,135 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/qubic/sink"
+)
+
+// identityUpdateScript applies the transactions of one identity in one tick to the delta document of the identity and
+// tick. The document keeps the hashes of the applied transactions, so re-consuming a transaction does not count it
+// twice, regardless of the order in which ticks or transactions arrive.
+const identityUpdateScript = `
+def s = ctx._source;
+if (s.hashes == null) {
+  s.identity = params.identity;
+  s.tick = params.tick;
+  s.sentAmount = 0L;
+  s.receivedAmount = 0L;
+  s.sentCount = 0L;
+  s.receivedCount = 0L;
+  s.transactionCount = 0L;
+  s.hashes = [];
+}
+boolean applied = false;
+for (def tx : params.transactions) {
+  if (s.hashes.contains(tx.hash)) {
+    continue;
+  }
+  s.hashes.add(tx.hash);
+  s.sentAmount += tx.sentAmount;
+  s.receivedAmount += tx.receivedAmount;
+  s.sentCount += tx.sentCount;
+  s.receivedCount += tx.receivedCount;
+  s.transactionCount += tx.transactionCount;
+  applied = true;
+}
+if (!applied) {
+  ctx.op = 'noop';
+}`
+
+type IdentityUpdateClient interface {
+	BulkUpdate(ctx context.Context, updates []sink.Document, indexName string) error
+}
+
+// identityTransaction contains the changes of one transaction for one identity.
+type identityTransaction struct {
+	Hash             string `json:"hash"`
+	SentAmount       int64  `json:"sentAmount"`
+	ReceivedAmount   int64  `json:"receivedAmount"`
+	SentCount        int    `json:"sentCount"`
+	ReceivedCount    int    `json:"receivedCount"`
+	TransactionCount int    `json:"transactionCount"`
+}
+
+// identityDelta contains the changes of one identity in one tick.
+type identityDelta struct {
+	Identity     string
+	Tick         uint32
+	Transactions []identityTransaction
+}
+
+// identityDeltas groups the transactions of the indexed documents per identity and tick. Duplicate transactions
+// within the documents are only counted once. Amounts are only counted, if money flew.
+func identityDeltas(documents []tickDocument) []*identityDelta {
+	type key struct {
+		identity string
+		tick     uint32
+	}
+	var deltas []*identityDelta
+	deltasByKey := make(map[key]*identityDelta)
+	add := func(identity string, tick uint32, tx identityTransaction) {
+		k := key{identity: identity, tick: tick}
+		d, ok := deltasByKey[k]
+		if !ok {
+			d = &identityDelta{Identity: identity, Tick: tick}
+			deltasByKey[k] = d
+			deltas = append(deltas, d)
+		}
+		d.Transactions = append(d.Transactions, tx)
+	}
+
+	seen := make(map[string]struct{})
+	for _, document := range documents {
+		tx := document.transaction
+		if _, ok := seen[tx.Hash]; ok {
+			continue
+		}
+		seen[tx.Hash] = struct{}{}
+
+		var amount int64
+		if tx.MoneyFlew {
+			amount = tx.Amount
+		}
+		if tx.Destination == tx.Source { // self transfers count as one transaction
+			add(tx.Source, tx.TickNumber, identityTransaction{Hash: tx.Hash, SentAmount: amount, ReceivedAmount: amount,
+				SentCount: 1, ReceivedCount: 1, TransactionCount: 1})
+			continue
+		}
+		add(tx.Source, tx.TickNumber, identityTransaction{Hash: tx.Hash, SentAmount: amount, SentCount: 1, TransactionCount: 1})
+		add(tx.Destination, tx.TickNumber, identityTransaction{Hash: tx.Hash, ReceivedAmount: amount, ReceivedCount: 1,
+			TransactionCount: 1})
+	}
+	return deltas
+}
+
+// identityUpdates creates one scripted upsert per delta. The document id is derived from identity and tick.
+func identityUpdates(documents []tickDocument) ([]sink.Document, error) {
+	deltas := identityDeltas(documents)
+	updates := make([]sink.Document, 0, len(deltas))
+	for _, delta := range deltas {
+		params := map[string]any{
+			"identity":     delta.Identity,
+			"tick":         delta.Tick,
+			"transactions": delta.Transactions,
+		}
+		payload, err := json.Marshal(map[string]any{
+			"scripted_upsert": true,
+			"script":          map[string]any{"lang": "painless", "source": identityUpdateScript, "params": params},
+			"upsert":          map[string]any{},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshalling update of identity [%s] in tick [%d]", delta.Identity, delta.Tick)
+		}
+		updates = append(updates, sink.Document{Id: identityDocumentId(delta.Identity, delta.Tick), Payload: payload})
+	}
+	return updates, nil
+}
+
+// identityDocumentId returns the id of the delta document of the identity in the tick.
+func identityDocumentId(identity string, tick uint32) string {
+	return fmt.Sprintf("%s-%d", identity, tick)
+}
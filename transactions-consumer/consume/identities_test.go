@@ -0,0 +1,186 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/qubic/sink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type FakeIdentityClient struct {
+	updates []sink.Document
+	index   string
+}
+
+func (c *FakeIdentityClient) BulkUpdate(_ context.Context, updates []sink.Document, indexName string) error {
+	c.updates = append(c.updates, updates...)
+	c.index = indexName
+	return nil
+}
+
+// tickDocuments returns the documents of the transactions.
+func tickDocuments(transactions ...Transaction) []tickDocument {
+	var documents []tickDocument
+	for _, tx := range transactions {
+		documents = append(documents, tickDocument{tick: tx.TickNumber, transaction: tx})
+	}
+	return documents
+}
+
+func TestIdentityDeltas(t *testing.T) {
+	documents := tickDocuments(
+		Transaction{Hash: "tx-3", Source: "A", Destination: "A", Amount: 3, TickNumber: 2, MoneyFlew: true},
+		Transaction{Hash: "tx-1", Source: "A", Destination: "B", Amount: 10, TickNumber: 1, MoneyFlew: true},
+		Transaction{Hash: "tx-2", Source: "B", Destination: "C", Amount: 5, TickNumber: 1, MoneyFlew: false},
+		Transaction{Hash: "tx-1", Source: "A", Destination: "B", Amount: 10, TickNumber: 1, MoneyFlew: true}, // duplicate
+	)
+	deltas := identityDeltas(documents)
+	require.Len(t, deltas, 4)
+
+	// in order of first occurrence
+	assert.Equal(t, identityDelta{Identity: "A", Tick: 2, Transactions: []identityTransaction{
+		{Hash: "tx-3", SentAmount: 3, ReceivedAmount: 3, SentCount: 1, ReceivedCount: 1, TransactionCount: 1},
+	}}, *deltas[0])
+	assert.Equal(t, identityDelta{Identity: "A", Tick: 1, Transactions: []identityTransaction{
+		{Hash: "tx-1", SentAmount: 10, SentCount: 1, TransactionCount: 1},
+	}}, *deltas[1])
+	assert.Equal(t, identityDelta{Identity: "B", Tick: 1, Transactions: []identityTransaction{
+		{Hash: "tx-1", ReceivedAmount: 10, ReceivedCount: 1, TransactionCount: 1},
+		{Hash: "tx-2", SentCount: 1, TransactionCount: 1},
+	}}, *deltas[2])
+	assert.Equal(t, identityDelta{Identity: "C", Tick: 1, Transactions: []identityTransaction{
+		{Hash: "tx-2", ReceivedCount: 1, TransactionCount: 1},
+	}}, *deltas[3])
+}
+
+func TestIdentityUpdates(t *testing.T) {
+	updates, err := identityUpdates(tickDocuments(Transaction{Hash: "tx-1", Source: "A", Destination: "B", Amount: 10, TickNumber: 1, MoneyFlew: true}))
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	assert.Equal(t, "A-1", updates[0].Id)
+	assert.Equal(t, "B-1", updates[1].Id)
+
+	var update struct {
+		ScriptedUpsert bool `json:"scripted_upsert"`
+		Script         struct {
+			Source string `json:"source"`
+			Params struct {
+				Identity     string                `json:"identity"`
+				Tick         uint32                `json:"tick"`
+				Transactions []identityTransaction `json:"transactions"`
+			} `json:"params"`
+		} `json:"script"`
+		Upsert map[string]any `json:"upsert"`
+	}
+	require.NoError(t, json.Unmarshal(updates[1].Payload, &update))
+	assert.True(t, update.ScriptedUpsert)
+	assert.NotNil(t, update.Upsert)
+	assert.Equal(t, identityUpdateScript, update.Script.Source)
+	assert.Equal(t, "B", update.Script.Params.Identity)
+	assert.EqualValues(t, 1, update.Script.Params.Tick)
+	assert.Equal(t, []identityTransaction{{Hash: "tx-1", ReceivedAmount: 10, ReceivedCount: 1, TransactionCount: 1}},
+		update.Script.Params.Transactions)
+}
+
+// transferRecord returns a record of a transaction from source to destination in a tick with one transaction.
+func transferRecord(hash, source, destination string, tick uint32) *kgo.Record {
+	record := tickRecord(hash, tick, 1)
+	record.Value = []byte(`{"hash":"` + hash + `","source":"` + source + `","destination":"` + destination +
+		`","amount":10,"moneyFlew":true,"tickNumber":` + strconv.Itoa(int(tick)) + `}`)
+	return record
+}
+
+func TestTransactionConsumer_UpdatesIdentitiesOfCompleteTicks(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 2)},
+			{tickRecord("tx-2", 1, 2)},
+		},
+	}
+	identityClient := &FakeIdentityClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   &FakeElasticClient{},
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		identityClient:  identityClient,
+		identityIndex:   "identities",
+	}
+
+	_, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, identityClient.updates)
+
+	_, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "identities", identityClient.index)
+	require.Len(t, identityClient.updates, 1) // source and destination are empty in the test records
+	assert.Equal(t, "-1", identityClient.updates[0].Id)
+	assert.Contains(t, string(identityClient.updates[0].Payload), `"hash":"tx-1"`)
+	assert.Contains(t, string(identityClient.updates[0].Payload), `"hash":"tx-2"`)
+}
+
+func TestTransactionConsumer_UpdatesIdentitiesOfTicksOutOfOrder(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{transferRecord("tx-5", "A", "B", 5)},
+			{transferRecord("tx-3", "A", "C", 3)}, // published in parallel, arrives late
+			{transferRecord("tx-5", "A", "B", 5)}, // redelivered
+		},
+	}
+	identityClient := &FakeIdentityClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   &FakeElasticClient{},
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		identityClient:  identityClient,
+		identityIndex:   "identities",
+	}
+
+	for range kafkaClient.polls {
+		_, err := consumer.consumeBatch(t.Context())
+		require.NoError(t, err)
+	}
+
+	// the older tick gets its own delta documents. The redelivered transaction updates the same documents again, the
+	// script skips it by hash.
+	assert.Equal(t, []string{"A-5", "B-5", "A-3", "C-3", "A-5", "B-5"}, documentIds(identityClient.updates))
+	assert.Contains(t, string(identityClient.updates[2].Payload), `{"hash":"tx-3","sentAmount":10,"receivedAmount":0,"sentCount":1,"receivedCount":0,"transactionCount":1}`)
+	assert.Equal(t, identityClient.updates[0].Payload, identityClient.updates[4].Payload)
+}
+
+func TestTransactionConsumer_SkipsIdentitiesOfIncompleteTicks(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 2)},
+			{},
+		},
+	}
+	identityClient := &FakeIdentityClient{}
+	now := time.Now()
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   &FakeElasticClient{},
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		ticks:           tickBuffer{timeout: time.Minute, clock: func() time.Time { return now }},
+		identityClient:  identityClient,
+		identityIndex:   "identities",
+	}
+
+	_, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	count, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, count, "the incomplete tick is not indexed")
+	assert.Empty(t, identityClient.updates, "and not counted")
+}
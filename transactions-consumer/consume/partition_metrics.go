@@ -0,0 +1,55 @@
+package consume
+
+import (
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// observeFetches updates the batch and partition metrics. The high watermarks are kept to calculate the lag.
+func (c *TransactionConsumer) observeFetches(fetches kgo.Fetches) {
+	if c.highWatermarks == nil {
+		c.highWatermarks = make(map[topicPartition]int64)
+	}
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if p.Err != nil {
+			return
+		}
+		c.highWatermarks[topicPartition{topic: p.Topic, partition: p.Partition}] = p.HighWatermark
+		c.consumerMetrics.SetHighWatermark(p.Topic, p.Partition, p.HighWatermark)
+		if len(p.Records) > 0 {
+			c.consumerMetrics.SetLastRecordTimestamp(p.Topic, p.Partition, p.Records[len(p.Records)-1].Timestamp)
+		}
+	})
+	if count := fetches.NumRecords(); count > 0 {
+		c.consumerMetrics.ObserveBatchSize(count)
+	}
+}
+
+// observeCommittedOffsets updates the committed offset and lag metrics of the assigned partitions.
+func (c *TransactionConsumer) observeCommittedOffsets() {
+	for topic, partitions := range c.kafkaClient.CommittedOffsets() {
+		for partition, offset := range partitions {
+			c.consumerMetrics.SetCommittedOffset(topic, partition, offset.Offset)
+			if highWatermark, ok := c.highWatermarks[topicPartition{topic: topic, partition: partition}]; ok {
+				c.consumerMetrics.SetLag(topic, partition, max(highWatermark-offset.Offset, 0))
+			}
+		}
+	}
+}
+
+// observeSecondsBehind updates the time between now and the latest transaction timestamp (milliseconds).
+func (c *TransactionConsumer) observeSecondsBehind(documents []tickDocument) {
+	var latest uint64
+	for _, document := range documents {
+		latest = max(latest, document.transaction.Timestamp)
+	}
+	if latest > 0 {
+		c.consumerMetrics.SetSecondsBehind(time.Since(time.UnixMilli(int64(latest))))
+	}
+}
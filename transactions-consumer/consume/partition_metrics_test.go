@@ -0,0 +1,71 @@
+package consume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// gaugeValue reads the value of the gauge with the given name and label values from the default registry.
+func gaugeValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matches := true
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value != label.GetValue() {
+					matches = false
+				}
+			}
+			if matches {
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+	t.Fatalf("gauge [%s] with labels %v not found", name, labels)
+	return 0
+}
+
+func TestTransactionConsumer_PartitionMetrics(t *testing.T) {
+	recordTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	txTime := time.Now().Add(-2 * time.Minute)
+	fetches := kgo.Fetches{{Topics: []kgo.FetchTopic{{
+		Topic: "lag-topic",
+		Partitions: []kgo.FetchPartition{
+			{
+				Partition:     3,
+				HighWatermark: 120,
+				Records:       []*kgo.Record{{Timestamp: recordTime}},
+			},
+		},
+	}}}}
+	kafkaClient := &FakeKafkaClient{
+		committed: map[string]map[int32]kgo.EpochOffset{"lag-topic": {3: {Offset: 100}}},
+	}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   &FakeElasticClient{},
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+	}
+
+	consumer.observeFetches(fetches)
+	consumer.observeCommittedOffsets()
+
+	partition := map[string]string{"topic": "lag-topic", "partition": "3"}
+	assert.Equal(t, float64(120), gaugeValue(t, "foo_partition_high_watermark", partition))
+	assert.Equal(t, float64(100), gaugeValue(t, "foo_partition_committed_offset", partition))
+	assert.Equal(t, float64(20), gaugeValue(t, "foo_partition_lag", partition))
+	assert.Equal(t, float64(recordTime.Unix()), gaugeValue(t, "foo_partition_last_record_timestamp_seconds", partition))
+
+	consumer.observeSecondsBehind([]tickDocument{{transaction: Transaction{Timestamp: uint64(txTime.UnixMilli())}}})
+	assert.InDelta(t, 120, gaugeValue(t, "foo_seconds_behind", nil), 5)
+}
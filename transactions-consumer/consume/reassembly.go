@@ -0,0 +1,249 @@
+package consume
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Record headers for oversized payloads. IMPORTANT: they need to match the producer code.
+const (
+	fragmentIdHeader    = "qubic-fragment-id"
+	fragmentIndexHeader = "qubic-fragment-index"
+	fragmentCountHeader = "qubic-fragment-count"
+	blobRefHeader       = "qubic-blob-ref"
+)
+
+// BlobReader reads payloads that the producer offloaded because they were too large for the message broker.
+type BlobReader interface {
+	Get(ref string) ([]byte, error)
+}
+
+const (
+	defaultFragmentTimeout    = 5 * time.Minute
+	defaultMaxFragmentedBytes = 256 * 1024 * 1024
+)
+
+type fragmentSet struct {
+	fragments [][]byte
+	received  int
+	size      int
+	partition topicPartition
+	offset    int64 // offset of the first received fragment
+	firstSeen time.Time
+	expired   bool // reported as incomplete after the timeout
+}
+
+// reassembler restores oversized payloads. Fragments of one payload are published in order into the same partition
+// but can be spread over several polls. Incomplete payloads are never dropped silently: they block the commit
+// position of their partition, so that they are consumed again after a restart. Payloads that are not complete within
+// the timeout are reported and kept. If the buffered fragments exceed the maximum size, the fragments of the oldest
+// payloads are released, but their offsets keep blocking the commit position. The zero value is usable with the
+// defaults and without blob support.
+type reassembler struct {
+	blobReader BlobReader
+	timeout    time.Duration
+	maxBytes   int
+	pending    map[string]*fragmentSet
+	released   map[topicPartition]int64 // offset of the oldest released payload per partition
+	bytes      int
+	clock      func() time.Time // for testing
+}
+
+// add returns the complete payload of the record. If the record is a fragment of an incomplete payload, it returns
+// false.
+func (r *reassembler) add(record *kgo.Record) ([]byte, bool, error) {
+	if ref := recordHeader(record, blobRefHeader); ref != "" {
+		if r.blobReader == nil {
+			return nil, false, fmt.Errorf("reading blob [%s]: no blob store configured", ref)
+		}
+		payload, err := r.blobReader.Get(ref)
+		if err != nil {
+			return nil, false, fmt.Errorf("reading blob [%s]: %w", ref, err)
+		}
+		return payload, true, nil
+	}
+
+	id := recordHeader(record, fragmentIdHeader)
+	if id == "" {
+		return bytes.Clone(record.Value), true, nil // to be safe (we don't want kafka and elastic use the same bytes)
+	}
+
+	index, err := strconv.Atoi(recordHeader(record, fragmentIndexHeader))
+	if err != nil {
+		return nil, false, fmt.Errorf("parsing fragment index of [%s]: %w", id, err)
+	}
+	count, err := strconv.Atoi(recordHeader(record, fragmentCountHeader))
+	if err != nil {
+		return nil, false, fmt.Errorf("parsing fragment count of [%s]: %w", id, err)
+	}
+	if count <= 0 || index < 0 || index >= count {
+		return nil, false, fmt.Errorf("invalid fragment [%d/%d] of [%s]", index, count, id)
+	}
+
+	if r.pending == nil {
+		r.pending = make(map[string]*fragmentSet)
+	}
+	set, ok := r.pending[id]
+	if !ok || len(set.fragments) != count {
+		r.remove(id)
+		set = &fragmentSet{
+			fragments: make([][]byte, count),
+			partition: topicPartition{topic: record.Topic, partition: record.Partition},
+			offset:    record.Offset,
+			firstSeen: r.now(),
+		}
+		r.pending[id] = set
+	}
+	if set.fragments[index] == nil {
+		set.received++
+	}
+	r.bytes += len(record.Value) - len(set.fragments[index])
+	set.size += len(record.Value) - len(set.fragments[index])
+	set.fragments[index] = bytes.Clone(record.Value)
+
+	if set.received < count {
+		return nil, false, nil
+	}
+	r.remove(id)
+	return bytes.Join(set.fragments, nil), true, nil
+}
+
+// expire marks the payloads, that were not completed within the timeout, as expired and returns their ids. They stay
+// buffered, because the missing fragments might still arrive. If the buffered fragments exceed the maximum size, the
+// fragments of the oldest payloads are released. Their offsets keep blocking the commit position, so that they are
+// consumed again after a restart. Returns the ids of the released payloads, too.
+func (r *reassembler) expire() (expired []string, released []string) {
+	for id, set := range r.pending {
+		if !set.expired && r.now().Sub(set.firstSeen) >= r.fragmentTimeout() {
+			set.expired = true
+			expired = append(expired, id)
+		}
+	}
+	for r.bytes > r.maxFragmentedBytes() {
+		oldest := ""
+		for id, set := range r.pending {
+			if oldest == "" || set.firstSeen.Before(r.pending[oldest].firstSeen) {
+				oldest = id
+			}
+		}
+		if oldest == "" {
+			break
+		}
+		set := r.pending[oldest]
+		if r.released == nil {
+			r.released = make(map[topicPartition]int64)
+		}
+		if offset, ok := r.released[set.partition]; !ok || set.offset < offset {
+			r.released[set.partition] = set.offset
+		}
+		r.remove(oldest)
+		released = append(released, oldest)
+	}
+	return expired, released
+}
+
+// nextExpiry returns the duration until the next incomplete payload times out. Expired payloads are not considered.
+func (r *reassembler) nextExpiry() (time.Duration, bool) {
+	var next time.Duration
+	found := false
+	for _, set := range r.pending {
+		if set.expired {
+			continue
+		}
+		remaining := r.fragmentTimeout() - r.now().Sub(set.firstSeen)
+		if !found || remaining < next {
+			next = remaining
+			found = true
+		}
+	}
+	return max(next, 0), found
+}
+
+func (r *reassembler) remove(id string) {
+	if set, ok := r.pending[id]; ok {
+		r.bytes -= set.size
+		delete(r.pending, id)
+	}
+}
+
+// pendingOffsets returns the offset of the oldest incomplete or released payload per partition.
+func (r *reassembler) pendingOffsets() map[topicPartition]int64 {
+	offsets := maps.Clone(r.released)
+	if offsets == nil {
+		offsets = make(map[topicPartition]int64)
+	}
+	for _, set := range r.pending {
+		if offset, ok := offsets[set.partition]; !ok || set.offset < offset {
+			offsets[set.partition] = set.offset
+		}
+	}
+	return offsets
+}
+
+// firstOffset returns the offset of the first received fragment of the record's payload. Committing beyond it would
+// skip the fragments on restart. Needs to be called before the record is added.
+func (r *reassembler) firstOffset(record *kgo.Record) int64 {
+	if set, ok := r.pending[recordHeader(record, fragmentIdHeader)]; ok {
+		return min(set.offset, record.Offset)
+	}
+	return record.Offset
+}
+
+// removePartition removes the payloads of the partition, including released ones.
+func (r *reassembler) removePartition(partition topicPartition) {
+	for id, set := range r.pending {
+		if set.partition == partition {
+			r.remove(id)
+		}
+	}
+	delete(r.released, partition)
+}
+
+// incomplete returns the number of payloads that are waiting for further fragments.
+func (r *reassembler) incomplete() int {
+	return len(r.pending)
+}
+
+// fragments returns the number of buffered fragments.
+func (r *reassembler) fragments() int {
+	count := 0
+	for _, set := range r.pending {
+		count += set.received
+	}
+	return count
+}
+
+func (r *reassembler) fragmentTimeout() time.Duration {
+	if r.timeout <= 0 {
+		return defaultFragmentTimeout
+	}
+	return r.timeout
+}
+
+func (r *reassembler) maxFragmentedBytes() int {
+	if r.maxBytes <= 0 {
+		return defaultMaxFragmentedBytes
+	}
+	return r.maxBytes
+}
+
+func (r *reassembler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock()
+}
+
+func recordHeader(record *kgo.Record, key string) string {
+	for _, header := range record.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
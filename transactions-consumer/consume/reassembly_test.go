@@ -0,0 +1,236 @@
+package consume
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type FakeBlobReader struct {
+	blobs map[string][]byte
+}
+
+func (r *FakeBlobReader) Get(ref string) ([]byte, error) {
+	blob, ok := r.blobs[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return blob, nil
+}
+
+func fragmentRecords(id string, payload string, fragmentSize int) []*kgo.Record {
+	count := (len(payload) + fragmentSize - 1) / fragmentSize
+	var records []*kgo.Record
+	for i := 0; i < count; i++ {
+		records = append(records, &kgo.Record{
+			Value: []byte(payload[i*fragmentSize : min((i+1)*fragmentSize, len(payload))]),
+			Headers: []kgo.RecordHeader{
+				{Key: fragmentIdHeader, Value: []byte(id)},
+				{Key: fragmentIndexHeader, Value: []byte(strconv.Itoa(i))},
+				{Key: fragmentCountHeader, Value: []byte(strconv.Itoa(count))},
+			},
+		})
+	}
+	return records
+}
+
+func TestReassembler_PlainRecord(t *testing.T) {
+	var r reassembler
+	payload, complete, err := r.add(&kgo.Record{Value: []byte("plain")})
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, "plain", string(payload))
+	assert.Zero(t, r.incomplete())
+}
+
+func TestReassembler_Fragments(t *testing.T) {
+	var r reassembler
+	records := fragmentRecords("tx-1", "0123456789abcdefghij", 8)
+	require.Len(t, records, 3)
+
+	for _, record := range records[:2] {
+		_, complete, err := r.add(record)
+		require.NoError(t, err)
+		assert.False(t, complete)
+	}
+	assert.Equal(t, 1, r.incomplete())
+
+	// duplicates (redelivery) do not count twice
+	_, complete, err := r.add(records[0])
+	require.NoError(t, err)
+	assert.False(t, complete)
+
+	payload, complete, err := r.add(records[2])
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, "0123456789abcdefghij", string(payload))
+	assert.Zero(t, r.incomplete())
+}
+
+func TestReassembler_FirstOffset(t *testing.T) {
+	var r reassembler
+	records := fragmentRecords("tx-1", "0123456789", 4)
+	for i, record := range records {
+		record.Offset = int64(10 + i)
+	}
+	assert.Equal(t, int64(10), r.firstOffset(records[0]))
+	_, _, err := r.add(records[0])
+	require.NoError(t, err)
+	_, _, err = r.add(records[1])
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(10), r.firstOffset(records[2]), "the payload starts at the first fragment")
+	assert.Equal(t, int64(20), r.firstOffset(&kgo.Record{Offset: 20, Value: []byte("plain")}))
+}
+
+func TestReassembler_Expire(t *testing.T) {
+	now := time.Now()
+	r := reassembler{timeout: time.Minute, clock: func() time.Time { return now }}
+	records := fragmentRecords("tx-1", "0123456789", 4)
+	_, _, err := r.add(records[0])
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, _, err = r.add(fragmentRecords("tx-2", "0123456789", 4)[0])
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.fragments())
+
+	next, ok := r.nextExpiry()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, next)
+	expired, released := r.expire()
+	assert.Empty(t, expired)
+	assert.Empty(t, released)
+
+	now = now.Add(30 * time.Second)
+	expired, released = r.expire()
+	assert.Equal(t, []string{"tx-1"}, expired)
+	assert.Empty(t, released)
+	assert.Equal(t, 2, r.incomplete(), "expired payloads stay buffered")
+	assert.Equal(t, map[topicPartition]int64{{}: 0}, r.pendingOffsets())
+
+	expired, _ = r.expire()
+	assert.Empty(t, expired, "reported once")
+	next, ok = r.nextExpiry()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, next, "only the other payload can expire")
+
+	// late fragments still complete the payload
+	for _, record := range records[1:] {
+		_, _, err = r.add(record)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, r.incomplete())
+}
+
+func TestReassembler_Expire_MaxBytes(t *testing.T) {
+	now := time.Now()
+	r := reassembler{maxBytes: 10, clock: func() time.Time { return now }}
+	for _, id := range []string{"tx-1", "tx-2", "tx-3"} {
+		record := fragmentRecords(id, "0123456789", 4)[0]
+		record.Offset = int64(len(r.pending)) + 5
+		_, _, err := r.add(record)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+
+	expired, released := r.expire()
+	assert.Empty(t, expired)
+	assert.Equal(t, []string{"tx-1"}, released)
+	assert.Equal(t, 2, r.incomplete())
+	assert.Equal(t, 8, r.bytes)
+	assert.Equal(t, map[topicPartition]int64{{}: 5}, r.pendingOffsets(), "the released payload blocks the commit")
+}
+
+func TestReassembler_InvalidFragment_ThenError(t *testing.T) {
+	var r reassembler
+	record := fragmentRecords("tx-1", "0123456789", 8)[0]
+	record.Headers[1].Value = []byte("5")
+
+	_, _, err := r.add(record)
+	require.ErrorContains(t, err, "invalid fragment")
+}
+
+func TestReassembler_BlobReference(t *testing.T) {
+	r := reassembler{blobReader: &FakeBlobReader{blobs: map[string][]byte{"tx-1.json": []byte("offloaded")}}}
+	record := &kgo.Record{Headers: []kgo.RecordHeader{{Key: blobRefHeader, Value: []byte("tx-1.json")}}}
+
+	payload, complete, err := r.add(record)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, "offloaded", string(payload))
+
+	var noBlobs reassembler
+	_, _, err = noBlobs.add(record)
+	require.ErrorContains(t, err, "no blob store")
+}
+
+func TestTransactionConsumer_ConsumeBatch_FragmentsAcrossPolls(t *testing.T) {
+	largeJson := `{"hash":"large-tx","source":"src","destination":"dest","amount":1,"tickNumber":456,"inputType":3,"inputSize":4,"inputData":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","signature":"signature","timestamp":5,"moneyFlew":true}`
+	fragments := fragmentRecords("large-tx", largeJson, 100)
+	require.Len(t, fragments, 3)
+
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{&kgo.Record{Value: []byte(`{"hash":"small-tx","tickNumber":455}`)}, fragments[0], fragments[1]},
+			{fragments[2]},
+		},
+	}
+	localElastic := &FakeElasticClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+	}
+
+	count, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(0), "must not commit pending fragments")
+
+	count, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(4), kafkaClient.committedOffset(0))
+
+	docs := localElastic.BatchesByIndex["default"]
+	require.Len(t, docs, 1)
+	assert.Equal(t, "large-tx", docs[0].Id)
+	assert.JSONEq(t, largeJson, string(docs[0].Payload))
+}
+
+func TestTransactionConsumer_ConsumeBatch_PendingFragmentsOnlyBlockTheirPartition(t *testing.T) {
+	fragments := fragmentRecords("large-tx", `{"hash":"large-tx","tickNumber":456}`, 10)
+	other := &kgo.Record{Partition: 1, Value: []byte(`{"hash":"other-tx","tickNumber":456}`)}
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{&kgo.Record{Value: []byte(`{"hash":"small-tx","tickNumber":455}`)}, fragments[0], other},
+		},
+	}
+	now := time.Now()
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   &FakeElasticClient{},
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		reassembler:     reassembler{timeout: time.Minute, clock: func() time.Time { return now }},
+	}
+
+	_, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(0))
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(1))
+
+	// the incomplete payload is reported after the timeout, but still blocks the commit
+	now = now.Add(time.Minute)
+	kafkaClient.polls = [][]*kgo.Record{{{Offset: 3, Value: []byte(`{"hash":"later-tx","tickNumber":457}`)}}}
+	_, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, consumer.reassembler.incomplete())
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(0))
+}
@@ -0,0 +1,297 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qubic/logging"
+	"github.com/qubic/transactions-consumer/metrics"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const zeroAddress = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB"
+
+// RoutingConfig maps transactions to target indices. Rules are evaluated in order. The first matching rule wins.
+// Transactions that do not match any rule are routed to the default target.
+type RoutingConfig struct {
+	Rules      []RoutingRule         `yaml:"rules"`
+	Default    RoutingTarget         `yaml:"default"`
+	Transforms map[string]*Transform `yaml:"transforms"` // optional document transformation per target index
+}
+
+type RoutingRule struct {
+	Name          string       `yaml:"name"`
+	Match         RoutingMatch `yaml:"match"`
+	RoutingTarget `yaml:",inline"`
+}
+
+type RoutingTarget struct {
+	Index string        `yaml:"index"` // should be an alias
+	TTL   time.Duration `yaml:"ttl"`   // optional. Adds an expiresAt field to the documents.
+}
+
+// RoutingMatch contains the conditions of a rule. All set conditions need to match. List conditions match, if any
+// of the values matches.
+type RoutingMatch struct {
+	Destinations []string     `yaml:"destinations"`
+	Sources      []string     `yaml:"sources"`
+	InputTypes   []uint32     `yaml:"inputTypes"`
+	Amount       *AmountRange `yaml:"amount"`
+	Contracts    []uint64     `yaml:"contracts"` // contract indices decoded from the destination
+}
+
+// AmountRange is inclusive. Missing bounds are unlimited.
+type AmountRange struct {
+	Min *int64 `yaml:"min"`
+	Max *int64 `yaml:"max"`
+}
+
+type Route struct {
+	Rule      string // name of the matching rule or default
+	Index     string
+	TTL       time.Duration
+	Transform *Transform // nil, if the document is indexed as published
+}
+
+// DefaultRoutingConfig creates the routing, that was used before the routing was configurable: transactions with
+// one of the ephemeral input types, zero amount and zero address destination are ephemeral. The ephemeral documents
+// expire after the ttl, if it is set.
+func DefaultRoutingConfig(permanentIndexName, ephemeralIndexName string, ephemeralInputTypes []uint32, ephemeralTTL time.Duration) RoutingConfig {
+	config := RoutingConfig{Default: RoutingTarget{Index: permanentIndexName}}
+	if len(ephemeralInputTypes) > 0 {
+		zero := int64(0)
+		config.Rules = append(config.Rules, RoutingRule{
+			Name: "ephemeral",
+			Match: RoutingMatch{
+				Destinations: []string{zeroAddress},
+				InputTypes:   ephemeralInputTypes,
+				Amount:       &AmountRange{Min: &zero, Max: &zero},
+			},
+			RoutingTarget: RoutingTarget{Index: ephemeralIndexName, TTL: ephemeralTTL},
+		})
+	}
+	return config
+}
+
+// LoadRoutingConfig reads the routing config from a yaml or json file.
+func LoadRoutingConfig(path string) (RoutingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RoutingConfig{}, errors.Wrap(err, "reading routing config")
+	}
+	var config RoutingConfig
+	err = yaml.Unmarshal(data, &config) // json is valid yaml
+	if err != nil {
+		return RoutingConfig{}, errors.Wrap(err, "parsing routing config")
+	}
+	return config, config.validate()
+}
+
+func (rc *RoutingConfig) validate() error {
+	if rc.Default.Index == "" {
+		return errors.New("missing default index")
+	}
+	for i := range rc.Rules {
+		rule := &rc.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Index == "" {
+			return errors.Errorf("missing index in rule [%s]", rule.Name)
+		}
+		if rule.TTL < 0 {
+			return errors.Errorf("negative ttl in rule [%s]", rule.Name)
+		}
+		amount := rule.Match.Amount
+		if amount != nil && amount.Min != nil && amount.Max != nil && *amount.Min > *amount.Max {
+			return errors.Errorf("invalid amount range in rule [%s]", rule.Name)
+		}
+	}
+	for index, transform := range rc.Transforms {
+		if index != rc.Default.Index && !slices.ContainsFunc(rc.Rules, func(rule RoutingRule) bool { return rule.Index == index }) {
+			return errors.Errorf("transform for unknown index [%s]", index)
+		}
+		if transform == nil {
+			return errors.Errorf("empty transform for index [%s]", index)
+		}
+		err := transform.validate(index)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Router routes transactions to indices. The rules can be replaced at runtime.
+type Router struct {
+	config  atomic.Pointer[RoutingConfig]
+	path    string
+	modTime time.Time
+	metrics *metrics.Metrics
+}
+
+// NewRouter creates a router with static rules.
+func NewRouter(config RoutingConfig, m *metrics.Metrics) (*Router, error) {
+	err := config.validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "validating routing config")
+	}
+	router := &Router{metrics: m}
+	router.config.Store(&config)
+	return router, nil
+}
+
+// NewFileRouter creates a router with the rules from the given file. The file can be reloaded with Reload or Watch.
+func NewFileRouter(path string, m *metrics.Metrics) (*Router, error) {
+	router := &Router{path: path, metrics: m}
+	err := router.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+// Reload reloads the rules from the file, if it changed. Invalid files are rejected and the current rules are kept.
+func (r *Router) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return errors.Wrap(err, "reading routing config file info")
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+
+	config, err := LoadRoutingConfig(r.path)
+	if err != nil {
+		r.metrics.IncRoutingReloads("error")
+		return err
+	}
+	r.modTime = info.ModTime()
+	r.config.Store(&config)
+	r.metrics.IncRoutingReloads("success")
+	zap.S().Infow("Loaded routing rules.", "rules", len(config.Rules), "path", r.path)
+	return nil
+}
+
+// Watch reloads the rules on change until the context is canceled.
+func (r *Router) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := r.Reload()
+			if err != nil {
+				zap.S().Warnw("Reloading routing config failed. Keeping current rules.", logging.Error, err)
+			}
+		}
+	}
+}
+
+// Route returns the target of the first matching rule or the default target.
+func (r *Router) Route(tx Transaction) Route {
+	config := r.config.Load()
+	route := Route{Rule: "default", Index: config.Default.Index, TTL: config.Default.TTL}
+	for _, rule := range config.Rules {
+		if rule.Match.matches(tx) {
+			route = Route{Rule: rule.Name, Index: rule.Index, TTL: rule.TTL}
+			break
+		}
+	}
+	route.Transform = config.Transforms[route.Index]
+	r.metrics.IncRoutingRuleHits(route.Rule, route.Index)
+	return route
+}
+
+// Indices returns the target indices of the current rules. Documents routed to an expiring index get an expiry
+// timestamp. The other indices are permanent. An index can be both, if targets with and without ttl share it.
+func (r *Router) Indices() (expiring []string, permanent []string) {
+	config := r.config.Load()
+	add := func(target RoutingTarget) {
+		if target.TTL > 0 {
+			if !slices.Contains(expiring, target.Index) {
+				expiring = append(expiring, target.Index)
+			}
+		} else if !slices.Contains(permanent, target.Index) {
+			permanent = append(permanent, target.Index)
+		}
+	}
+	add(config.Default)
+	for _, rule := range config.Rules {
+		add(rule.RoutingTarget)
+	}
+	return expiring, permanent
+}
+
+func (m *RoutingMatch) matches(tx Transaction) bool {
+	if len(m.Destinations) > 0 && !slices.Contains(m.Destinations, tx.Destination) {
+		return false
+	}
+	if len(m.Sources) > 0 && !slices.Contains(m.Sources, tx.Source) {
+		return false
+	}
+	if len(m.InputTypes) > 0 && !slices.Contains(m.InputTypes, tx.InputType) {
+		return false
+	}
+	if m.Amount != nil {
+		if m.Amount.Min != nil && tx.Amount < *m.Amount.Min {
+			return false
+		}
+		if m.Amount.Max != nil && tx.Amount > *m.Amount.Max {
+			return false
+		}
+	}
+	if len(m.Contracts) > 0 {
+		contract, ok := contractIndex(tx.Destination)
+		if !ok || !slices.Contains(m.Contracts, contract) {
+			return false
+		}
+	}
+	return true
+}
+
+// contractIndex decodes the contract index from a contract address. The public key of a contract address contains
+// the contract index in the first 8 bytes (little endian) and is zero otherwise.
+func contractIndex(identity string) (uint64, bool) {
+	if len(identity) != 60 {
+		return 0, false
+	}
+	var fragments [4]uint64
+	for i := range fragments {
+		for j := 13; j >= 0; j-- {
+			char := identity[i*14+j]
+			if char < 'A' || char > 'Z' {
+				return 0, false
+			}
+			fragments[i] = fragments[i]*26 + uint64(char-'A')
+		}
+	}
+	if fragments[0] == 0 || fragments[1] != 0 || fragments[2] != 0 || fragments[3] != 0 {
+		return 0, false
+	}
+	return fragments[0], true
+}
+
+// withExpiry adds the expiry timestamp (transaction timestamp plus ttl) to the document.
+func withExpiry(data []byte, tx Transaction, ttl time.Duration) ([]byte, error) {
+	var document map[string]json.RawMessage
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling document")
+	}
+	expiresAt, err := json.Marshal(tx.Timestamp + uint64(ttl.Milliseconds()))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling expiry")
+	}
+	document["expiresAt"] = expiresAt
+	return json.Marshal(document)
+}
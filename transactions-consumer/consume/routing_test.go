@@ -0,0 +1,225 @@
+package consume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const qxAddress = "BAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAARMID"
+
+func newTestRouter(t *testing.T, config RoutingConfig) *Router {
+	router, err := NewRouter(config, m)
+	require.NoError(t, err)
+	return router
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func TestRouter_Route(t *testing.T) {
+	router := newTestRouter(t, RoutingConfig{
+		Rules: []RoutingRule{
+			{
+				Name:          "qx-orders",
+				Match:         RoutingMatch{Contracts: []uint64{1}, InputTypes: []uint32{5, 6}},
+				RoutingTarget: RoutingTarget{Index: "qx"},
+			},
+			{
+				Name:          "whale",
+				Match:         RoutingMatch{Amount: &AmountRange{Min: int64Ptr(1_000_000_000)}},
+				RoutingTarget: RoutingTarget{Index: "whales"},
+			},
+			{
+				Name:          "dust",
+				Match:         RoutingMatch{Amount: &AmountRange{Min: int64Ptr(1), Max: int64Ptr(10)}, Sources: []string{"SOURCE"}},
+				RoutingTarget: RoutingTarget{Index: "dust", TTL: time.Hour},
+			},
+			{
+				Name:          "burn",
+				Match:         RoutingMatch{Destinations: []string{zeroAddress}},
+				RoutingTarget: RoutingTarget{Index: "burns"},
+			},
+		},
+		Default: RoutingTarget{Index: "transactions"},
+	})
+
+	tests := []struct {
+		name string
+		tx   Transaction
+		want Route
+	}{
+		{
+			name: "contract and input type",
+			tx:   Transaction{Destination: qxAddress, InputType: 6, Amount: 2_000_000_000},
+			want: Route{Rule: "qx-orders", Index: "qx"},
+		},
+		{
+			name: "contract with other input type, first matching rule wins",
+			tx:   Transaction{Destination: qxAddress, InputType: 1, Amount: 2_000_000_000},
+			want: Route{Rule: "whale", Index: "whales"},
+		},
+		{
+			name: "amount range and source",
+			tx:   Transaction{Source: "SOURCE", Destination: "DEST", Amount: 10},
+			want: Route{Rule: "dust", Index: "dust", TTL: time.Hour},
+		},
+		{
+			name: "amount range with other source",
+			tx:   Transaction{Source: "OTHER", Destination: "DEST", Amount: 10},
+			want: Route{Rule: "default", Index: "transactions"},
+		},
+		{
+			name: "amount out of range",
+			tx:   Transaction{Source: "SOURCE", Destination: "DEST", Amount: 11},
+			want: Route{Rule: "default", Index: "transactions"},
+		},
+		{
+			name: "destination",
+			tx:   Transaction{Destination: zeroAddress, Amount: 5},
+			want: Route{Rule: "burn", Index: "burns"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, router.Route(tt.tx))
+		})
+	}
+}
+
+func TestNewRouter_InvalidConfig(t *testing.T) {
+	_, err := NewRouter(RoutingConfig{}, m)
+	require.ErrorContains(t, err, "missing default index")
+
+	_, err = NewRouter(RoutingConfig{Default: RoutingTarget{Index: "default"}, Rules: []RoutingRule{{Name: "foo"}}}, m)
+	require.ErrorContains(t, err, "missing index in rule [foo]")
+
+	_, err = NewRouter(RoutingConfig{
+		Default: RoutingTarget{Index: "default"},
+		Rules: []RoutingRule{{
+			Match:         RoutingMatch{Amount: &AmountRange{Min: int64Ptr(2), Max: int64Ptr(1)}},
+			RoutingTarget: RoutingTarget{Index: "index"},
+		}},
+	}, m)
+	require.ErrorContains(t, err, "invalid amount range in rule [rule-1]")
+}
+
+func TestContractIndex(t *testing.T) {
+	index, ok := contractIndex(qxAddress)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), index)
+
+	index, ok = contractIndex("EAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAVWRF")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), index)
+
+	_, ok = contractIndex(zeroAddress)
+	assert.False(t, ok)
+	_, ok = contractIndex("FZTXBUWQTOWAHBODSZKVMUQRRPDDASKDOQLSDGLIUCVWDSYWIBAKAXRBKEJJ")
+	assert.False(t, ok)
+	_, ok = contractIndex("invalid")
+	assert.False(t, ok)
+}
+
+const yamlRoutingConfig = `
+rules:
+  - name: ephemeral
+    match:
+      destinations: [AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB]
+      inputTypes: [6]
+      amount: {min: 0, max: 0}
+    index: eph-transactions
+    ttl: 24h
+default:
+  index: transactions
+`
+
+const jsonRoutingConfig = `{
+  "rules": [{"name": "qutil", "match": {"contracts": [4]}, "index": "qutil-transactions"}],
+  "default": {"index": "transactions"}
+}`
+
+func TestNewFileRouter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "routing.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yamlRoutingConfig), 0644))
+
+	router, err := NewFileRouter(path, m)
+	require.NoError(t, err)
+	ephemeral := Transaction{Destination: zeroAddress, InputType: 6}
+	assert.Equal(t, Route{Rule: "ephemeral", Index: "eph-transactions", TTL: 24 * time.Hour}, router.Route(ephemeral))
+
+	// json config
+	require.NoError(t, os.WriteFile(path, []byte(jsonRoutingConfig), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	require.NoError(t, router.Reload())
+	assert.Equal(t, Route{Rule: "default", Index: "transactions"}, router.Route(ephemeral))
+	qutil := Transaction{Destination: "EAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAVWRF"}
+	assert.Equal(t, Route{Rule: "qutil", Index: "qutil-transactions"}, router.Route(qutil))
+
+	// invalid config keeps the current rules
+	require.NoError(t, os.WriteFile(path, []byte(`default: {}`), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	require.ErrorContains(t, router.Reload(), "missing default index")
+	assert.Equal(t, Route{Rule: "qutil", Index: "qutil-transactions"}, router.Route(qutil))
+}
+
+func TestRouter_Indices(t *testing.T) {
+	router := newTestRouter(t, RoutingConfig{
+		Rules: []RoutingRule{
+			{Name: "ephemeral", RoutingTarget: RoutingTarget{Index: "ephemeral", TTL: time.Hour}},
+			{Name: "contracts", RoutingTarget: RoutingTarget{Index: "contracts"}},
+			{Name: "ephemeral-contracts", RoutingTarget: RoutingTarget{Index: "ephemeral", TTL: 2 * time.Hour}},
+		},
+		Default: RoutingTarget{Index: "permanent"},
+	})
+	expiring, permanent := router.Indices()
+	assert.Equal(t, []string{"ephemeral"}, expiring)
+	assert.Equal(t, []string{"permanent", "contracts"}, permanent)
+
+	expiring, permanent = newTestRouter(t, DefaultRoutingConfig("permanent", "ephemeral", []uint32{6}, 24*time.Hour)).Indices()
+	assert.Equal(t, []string{"ephemeral"}, expiring)
+	assert.Equal(t, []string{"permanent"}, permanent)
+}
+
+func TestWithExpiry(t *testing.T) {
+	data := []byte(`{"hash":"tx-hash","timestamp":1744649165000}`)
+	withTtl, err := withExpiry(data, Transaction{Hash: "tx-hash", Timestamp: 1744649165000}, time.Hour)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hash":"tx-hash","timestamp":1744649165000,"expiresAt":1744652765000}`, string(withTtl))
+}
+
+func TestTransactionConsumer_RoutesWithTtl(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		values: [][]byte{
+			[]byte(`{"hash":"ephemeral-tx","destination":"` + zeroAddress + `","amount":0,"tickNumber":1,"inputType":6,"timestamp":1000}`),
+			[]byte(`{"hash":"permanent-tx","destination":"` + qxAddress + `","amount":0,"tickNumber":1,"inputType":6,"timestamp":1000}`),
+		},
+	}
+	config := DefaultRoutingConfig("permanent-index", "ephemeral-index", []uint32{6}, 0)
+	config.Rules[0].TTL = time.Minute
+	localElastic := &FakeElasticClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, config),
+	}
+
+	count, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	ephemeralDocs := localElastic.BatchesByIndex["ephemeral-index"]
+	require.Len(t, ephemeralDocs, 1)
+	assert.JSONEq(t, `{"hash":"ephemeral-tx","destination":"`+zeroAddress+`","amount":0,"tickNumber":1,"inputType":6,"timestamp":1000,"expiresAt":61000}`, string(ephemeralDocs[0].Payload))
+
+	permanentDocs := localElastic.BatchesByIndex["permanent-index"]
+	require.Len(t, permanentDocs, 1)
+	assert.NotContains(t, string(permanentDocs[0].Payload), "expiresAt")
+}
@@ -0,0 +1,157 @@
+package consume
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qubic/sink"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// tickTransactionCountHeader contains the number of transactions the producer published for the tick of the record.
+// IMPORTANT: it needs to match the producer code.
+const tickTransactionCountHeader = "qubic-tick-transaction-count"
+
+const defaultTickCompletionTimeout = time.Minute
+
+type tickDocument struct {
+	tick        uint32
+	index       string
+	document    sink.Document
+	transaction Transaction
+	partition   topicPartition
+	offset      int64 // offset of the (first) record of the document
+}
+
+type pendingTick struct {
+	expected  int
+	documents []tickDocument
+	hashes    map[string]struct{} // redelivered transactions are only counted once
+	firstSeen time.Time
+	expired   bool // reported as incomplete after the timeout
+}
+
+// tickBuffer holds back the transactions of a tick until all transactions of the tick were received. The
+// transactions of one tick are published into the same partition but can be spread over several polls. Incomplete
+// ticks are never indexed. They stay buffered after the timeout and block the commit position of their partition. The
+// zero value is usable with the default timeout.
+type tickBuffer struct {
+	timeout time.Duration
+	pending map[uint32]*pendingTick
+	clock   func() time.Time // for testing
+}
+
+// add buffers the document. If the tick is complete, it returns all documents of the tick.
+func (b *tickBuffer) add(document tickDocument, expected int) []tickDocument {
+	if b.pending == nil {
+		b.pending = make(map[uint32]*pendingTick)
+	}
+	tick, ok := b.pending[document.tick]
+	if !ok {
+		tick = &pendingTick{expected: expected, hashes: make(map[string]struct{}), firstSeen: b.now()}
+		b.pending[document.tick] = tick
+	}
+	if _, seen := tick.hashes[document.document.Id]; !seen {
+		tick.hashes[document.document.Id] = struct{}{}
+		tick.documents = append(tick.documents, document)
+	}
+
+	if len(tick.documents) < tick.expected {
+		return nil
+	}
+	delete(b.pending, document.tick)
+	return tick.documents
+}
+
+// expire marks the ticks, that were not completed within the timeout, as expired and returns them. They stay
+// buffered, because the missing transactions might still arrive.
+func (b *tickBuffer) expire() map[uint32]*pendingTick {
+	expired := make(map[uint32]*pendingTick)
+	for tickNumber, tick := range b.pending {
+		if !tick.expired && b.now().Sub(tick.firstSeen) >= b.completionTimeout() {
+			tick.expired = true
+			expired[tickNumber] = tick
+		}
+	}
+	return expired
+}
+
+// drain removes all pending ticks and returns them.
+func (b *tickBuffer) drain() map[uint32]*pendingTick {
+	pending := b.pending
+	b.pending = nil
+	return pending
+}
+
+// nextExpiry returns the duration until the next pending tick times out. Expired ticks are not considered.
+func (b *tickBuffer) nextExpiry() (time.Duration, bool) {
+	var next time.Duration
+	found := false
+	for _, tick := range b.pending {
+		if tick.expired {
+			continue
+		}
+		remaining := b.completionTimeout() - b.now().Sub(tick.firstSeen)
+		if !found || remaining < next {
+			next = remaining
+			found = true
+		}
+	}
+	return max(next, 0), found
+}
+
+func (b *tickBuffer) completionTimeout() time.Duration {
+	if b.timeout <= 0 {
+		return defaultTickCompletionTimeout
+	}
+	return b.timeout
+}
+
+func (b *tickBuffer) now() time.Time {
+	if b.clock == nil {
+		return time.Now()
+	}
+	return b.clock()
+}
+
+// pendingOffsets returns the offset of the oldest buffered document per partition.
+func (b *tickBuffer) pendingOffsets() map[topicPartition]int64 {
+	offsets := make(map[topicPartition]int64)
+	for _, tick := range b.pending {
+		for _, document := range tick.documents {
+			if offset, ok := offsets[document.partition]; !ok || document.offset < offset {
+				offsets[document.partition] = document.offset
+			}
+		}
+	}
+	return offsets
+}
+
+// removePartition removes the ticks of the partition. The transactions of a tick are published into one partition.
+func (b *tickBuffer) removePartition(partition topicPartition) {
+	for tickNumber, tick := range b.pending {
+		if len(tick.documents) > 0 && tick.documents[0].partition == partition {
+			delete(b.pending, tickNumber)
+		}
+	}
+}
+
+// incomplete returns the number of ticks that are waiting for further transactions.
+func (b *tickBuffer) incomplete() int {
+	return len(b.pending)
+}
+
+// tickTransactionCount returns the expected number of transactions of the record's tick. Records of older producers
+// do not contain the count.
+func tickTransactionCount(record *kgo.Record) (int, bool, error) {
+	value := recordHeader(record, tickTransactionCountHeader)
+	if value == "" {
+		return 0, false, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count <= 0 {
+		return 0, false, errors.Errorf("invalid tick transaction count [%s]", value)
+	}
+	return count, true, nil
+}
@@ -0,0 +1,248 @@
+package consume
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/qubic/sink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func tickRecord(hash string, tick uint32, tickTransactionCount int) *kgo.Record {
+	return &kgo.Record{
+		Value:   []byte(`{"hash":"` + hash + `","tickNumber":` + strconv.Itoa(int(tick)) + `}`),
+		Headers: []kgo.RecordHeader{{Key: tickTransactionCountHeader, Value: []byte(strconv.Itoa(tickTransactionCount))}},
+	}
+}
+
+func documentIds(documents []sink.Document) []string {
+	var ids []string
+	for _, document := range documents {
+		ids = append(ids, document.Id)
+	}
+	return ids
+}
+
+func TestTickBuffer_Add(t *testing.T) {
+	var buffer tickBuffer
+	assert.Empty(t, buffer.add(tickDocument{tick: 1, document: sink.Document{Id: "tx-1"}}, 2))
+	assert.Empty(t, buffer.add(tickDocument{tick: 1, document: sink.Document{Id: "tx-1"}}, 2)) // redelivered
+	assert.Equal(t, 1, buffer.incomplete())
+
+	complete := buffer.add(tickDocument{tick: 1, document: sink.Document{Id: "tx-2"}}, 2)
+	require.Len(t, complete, 2)
+	assert.Equal(t, "tx-1", complete[0].document.Id)
+	assert.Equal(t, "tx-2", complete[1].document.Id)
+	assert.Zero(t, buffer.incomplete())
+}
+
+func TestTickBuffer_Expire(t *testing.T) {
+	now := time.Now()
+	buffer := tickBuffer{timeout: time.Minute, clock: func() time.Time { return now }}
+	buffer.add(tickDocument{tick: 1, document: sink.Document{Id: "tx-1"}}, 2)
+	now = now.Add(30 * time.Second)
+	buffer.add(tickDocument{tick: 2, document: sink.Document{Id: "tx-2"}}, 2)
+
+	next, ok := buffer.nextExpiry()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, next)
+	assert.Empty(t, buffer.expire())
+
+	now = now.Add(30 * time.Second)
+	expired := buffer.expire()
+	require.Len(t, expired, 1)
+	assert.Len(t, expired[1].documents, 1)
+	assert.Equal(t, 2, expired[1].expected)
+	assert.Equal(t, 2, buffer.incomplete(), "expired ticks stay buffered")
+	assert.Empty(t, buffer.expire(), "reported once")
+
+	next, ok = buffer.nextExpiry()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, next, "only the other tick can expire")
+
+	complete := buffer.add(tickDocument{tick: 1, document: sink.Document{Id: "tx-3"}}, 2)
+	assert.Len(t, complete, 2, "late transactions complete the tick")
+}
+
+func TestTickTransactionCount(t *testing.T) {
+	count, ok, err := tickTransactionCount(tickRecord("tx", 1, 3))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, count)
+
+	_, ok, err = tickTransactionCount(&kgo.Record{})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = tickTransactionCount(&kgo.Record{Headers: []kgo.RecordHeader{{Key: tickTransactionCountHeader, Value: []byte("foo")}}})
+	require.ErrorContains(t, err, "invalid tick transaction count")
+}
+
+func TestTransactionConsumer_ConsumeBatch_TickAcrossPolls(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 1), tickRecord("tx-2", 2, 3), tickRecord("tx-3", 2, 3)},
+			{tickRecord("tx-4", 2, 3), tickRecord("tx-5", 3, 1)},
+		},
+	}
+	localElastic := &FakeElasticClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+	}
+
+	count, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"tx-1"}, documentIds(localElastic.BatchesByIndex["default"]))
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(0), "must not commit incomplete ticks")
+	assert.Equal(t, uint32(1), consumer.currentTick)
+
+	count, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, []string{"tx-2", "tx-3", "tx-4", "tx-5"}, documentIds(localElastic.BatchesByIndex["default"]))
+	assert.Equal(t, int64(5), kafkaClient.committedOffset(0))
+	assert.Equal(t, uint32(3), consumer.currentTick)
+}
+
+func TestTransactionConsumer_ConsumeBatch_CommitsBelowTheOldestIncompleteTick(t *testing.T) {
+	otherPartition := func(record *kgo.Record) *kgo.Record {
+		record.Partition = 1
+		return record
+	}
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 2), tickRecord("tx-2", 2, 2), otherPartition(tickRecord("tx-3", 3, 1))},
+			{tickRecord("tx-4", 1, 2), tickRecord("tx-5", 4, 2)}, // tick 1 completes, ticks 2 and 4 are pending
+			{tickRecord("tx-6", 2, 2), tickRecord("tx-7", 4, 2)},
+		},
+	}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   &FakeElasticClient{},
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+	}
+
+	_, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, kafkaClient.committedOffset(0), "must not commit incomplete ticks")
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(1), "other partitions are not blocked")
+
+	_, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(0), "commit below the oldest incomplete tick")
+
+	_, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), kafkaClient.committedOffset(0))
+	assert.Zero(t, consumer.ticks.incomplete())
+}
+
+func TestTransactionConsumer_ConsumeBatch_IncompleteTickTimesOut(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 2)},
+			{},
+			{tickRecord("tx-2", 1, 2)},
+		},
+	}
+	localElastic := &FakeElasticClient{}
+	now := time.Now()
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		ticks:           tickBuffer{timeout: time.Minute, clock: func() time.Time { return now }},
+	}
+
+	count, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Zero(t, kafkaClient.commitCount)
+
+	now = now.Add(time.Minute)
+	count, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, count, "incomplete ticks are not indexed")
+	assert.Empty(t, localElastic.BatchesByIndex["default"])
+	assert.Zero(t, kafkaClient.commitCount, "and not committed")
+	assert.Equal(t, 1, consumer.ticks.incomplete())
+
+	count, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "late transactions complete the tick")
+	assert.Equal(t, []string{"tx-1", "tx-2"}, documentIds(localElastic.BatchesByIndex["default"]))
+	assert.Equal(t, int64(2), kafkaClient.committedOffset(0))
+}
+
+func TestTransactionConsumer_ConsumeBatch_RemovesRevokedPartitions(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 1), tickRecord("tx-2", 2, 2)},
+			{},
+		},
+	}
+	localElastic := &FakeElasticClient{}
+	revocations := &Revocations{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		revocations:     revocations,
+	}
+
+	_, err := consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), kafkaClient.committedOffset(0))
+	assert.Equal(t, 1, consumer.ticks.incomplete())
+
+	revocations.Revoked(t.Context(), nil, map[string][]int32{"": {0}})
+	kafkaClient.commitCount = 0
+	_, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, consumer.ticks.incomplete(), "buffered ticks of the partition are removed")
+	assert.Empty(t, consumer.polled)
+	assert.Empty(t, consumer.commitPositions)
+	assert.Zero(t, kafkaClient.commitCount, "nothing is committed for the revoked partition")
+}
+
+func TestIsPollTimeout(t *testing.T) {
+	timeout := []kgo.FetchError{{Partition: -1, Err: context.DeadlineExceeded}}
+	assert.True(t, isPollTimeout(t.Context(), timeout))
+	assert.False(t, isPollTimeout(t.Context(), []kgo.FetchError{{Err: context.Canceled}}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	assert.False(t, isPollTimeout(ctx, timeout))
+}
+
+func TestTransactionConsumer_Replay_SkipsIncompleteTicksAtTheEnd(t *testing.T) {
+	kafkaClient := &FakeKafkaClient{
+		polls: [][]*kgo.Record{
+			{tickRecord("tx-1", 1, 1), tickRecord("tx-2", 2, 2)},
+		},
+	}
+	localElastic := &FakeElasticClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+	}
+
+	count, err := consumer.Replay(t.Context(), func() bool { return len(kafkaClient.polls) == 0 })
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"tx-1"}, documentIds(localElastic.BatchesByIndex["default"]))
+	assert.Zero(t, consumer.ticks.incomplete())
+}
@@ -1,46 +1,58 @@
 package consume
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"slices"
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/qubic/transactions-consumer/extern"
+	"github.com/qubic/logging"
+	"github.com/qubic/sink"
 	"github.com/qubic/transactions-consumer/metrics"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
 )
 
 type KafkaClient interface {
 	PollRecords(ctx context.Context, maxPollRecords int) kgo.Fetches
-	CommitUncommittedOffsets(ctx context.Context) error
+	CommitRecords(ctx context.Context, records ...*kgo.Record) error
+	CommittedOffsets() map[string]map[int32]kgo.EpochOffset
 	AllowRebalance()
 }
 
 type ElasticDocumentClient interface {
-	BulkIndex(ctx context.Context, data []extern.EsDocument, indexName string) error
+	BulkIndex(ctx context.Context, data []sink.Document, indexName string) error
 }
 
 type ConsumerConfig struct {
-	MaxPollRecords      int
-	PermanentIndexName  string
-	EphemeralIndexName  string
-	EphemeralInputTypes []uint32
+	MaxPollRecords        int
+	Router                *Router
+	BlobReader            BlobReader           // optional, for offloaded payloads
+	FragmentTimeout       time.Duration        // time to wait for missing fragments of a payload. Defaults to five minutes.
+	MaxFragmentedBytes    int                  // maximum size of buffered fragments. Defaults to 256MB.
+	TickCompletionTimeout time.Duration        // time to wait for missing transactions of a tick. Defaults to one minute.
+	IdentityClient        IdentityUpdateClient // optional, maintains the identities index
+	IdentityIndexName     string
+	Revocations           *Revocations // optional, partitions revoked by the consumer group
 }
 
 type TransactionConsumer struct {
-	kafkaClient         KafkaClient
-	elasticClient       ElasticDocumentClient
-	maxPollRecords      int
-	permanentIndexName  string
-	ephemeralIndexName  string
-	ephemeralInputTypes []uint32
-	consumerMetrics     *metrics.Metrics
-	currentTick         uint32
+	kafkaClient     KafkaClient
+	elasticClient   ElasticDocumentClient
+	maxPollRecords  int
+	router          *Router
+	consumerMetrics *metrics.Metrics
+	currentTick     uint32
+	reassembler     reassembler
+	ticks           tickBuffer
+	identityClient  IdentityUpdateClient
+	identityIndex   string
+	highWatermarks  map[topicPartition]int64
+	polled          map[topicPartition]*kgo.Record // latest polled record per partition
+	commitPositions map[topicPartition]int64       // latest committed offset per partition
+	commitBlocked   map[topicPartition]time.Time   // since when buffered records prevent committing per partition
+	revocations     *Revocations
 }
 
 type Transaction struct {
@@ -59,13 +71,20 @@ type Transaction struct {
 
 func NewTransactionConsumer(client KafkaClient, elasticClient ElasticDocumentClient, m *metrics.Metrics, config *ConsumerConfig) *TransactionConsumer {
 	return &TransactionConsumer{
-		kafkaClient:         client,
-		consumerMetrics:     m,
-		elasticClient:       elasticClient,
-		permanentIndexName:  config.PermanentIndexName,
-		ephemeralIndexName:  config.EphemeralIndexName,
-		ephemeralInputTypes: config.EphemeralInputTypes,
-		maxPollRecords:      config.MaxPollRecords,
+		kafkaClient:     client,
+		consumerMetrics: m,
+		elasticClient:   elasticClient,
+		router:          config.Router,
+		maxPollRecords:  config.MaxPollRecords,
+		reassembler: reassembler{
+			blobReader: config.BlobReader,
+			timeout:    config.FragmentTimeout,
+			maxBytes:   config.MaxFragmentedBytes,
+		},
+		ticks:          tickBuffer{timeout: config.TickCompletionTimeout},
+		identityClient: config.IdentityClient,
+		identityIndex:  config.IdentityIndexName,
+		revocations:    config.Revocations,
 	}
 }
 
@@ -74,7 +93,7 @@ func (c *TransactionConsumer) Consume(ctx context.Context) error {
 	for range ticker {
 		select {
 		case <-ctx.Done():
-			log.Println("Shutdown signal received, stopping consumer.")
+			zap.S().Info("Shutdown signal received, stopping consumer.")
 			return nil
 
 		default:
@@ -83,7 +102,7 @@ func (c *TransactionConsumer) Consume(ctx context.Context) error {
 				return fmt.Errorf("consuming batch: %w", err)
 			}
 			if count > 0 {
-				log.Printf("Processed [%d] transactions. Latest tick: [%d].", count, c.currentTick)
+				zap.S().Infow("Processed transactions.", "count", count, logging.Tick, c.currentTick)
 			}
 		}
 	}
@@ -91,73 +110,219 @@ func (c *TransactionConsumer) Consume(ctx context.Context) error {
 }
 
 func (c *TransactionConsumer) consumeBatch(ctx context.Context) (int, error) {
-	defer c.kafkaClient.AllowRebalance()                        // because of the configured kgo.BlockRebalanceOnPoll() option
-	fetches := c.kafkaClient.PollRecords(ctx, c.maxPollRecords) // batch process max x messages in one run
-	if errs := fetches.Errors(); len(errs) > 0 {
+	defer c.kafkaClient.AllowRebalance() // because of the configured kgo.BlockRebalanceOnPoll() option
+	fetches := c.poll(ctx)
+	c.removeRevokedPartitions() // rebalancing is blocked until the end of the batch
+	if errs := fetches.Errors(); len(errs) > 0 && !isPollTimeout(ctx, errs) {
 		// Only non-retryable errors are returned.
 		// Errors are typically per partition.
 		for _, err := range errs {
-			log.Printf("Error: %v", err)
+			zap.S().Errorw("Fetching records failed.", "topic", err.Topic, logging.Partition, err.Partition, logging.Error, err.Err)
 		}
 		return -1, errors.New("fetching records")
 	}
+	c.observeFetches(fetches)
 
-	var permanentDocuments []extern.EsDocument
-	var ephemeralDocuments []extern.EsDocument
+	var documents []tickDocument // complete ticks and transactions without tick information
 	iter := fetches.RecordIter()
 	for !iter.Done() {
 		record := iter.Next()
-		data := bytes.Clone(record.Value) // to be safe (we don't want kafka and elastic use the same bytes)
+		c.trackPolled(record)
+		offset := c.reassembler.firstOffset(record)
+		data, complete, err := c.reassembler.add(record)
+		if err != nil {
+			return -1, errors.Wrap(err, "reassembling record")
+		}
+		if !complete {
+			continue // wait for further fragments
+		}
 
 		var transaction Transaction
-		err := json.Unmarshal(data, &transaction)
+		err = json.Unmarshal(data, &transaction)
 		if err != nil {
-			return -1, errors.Wrapf(err, "unmarshalling record value %s", string(record.Value))
+			return -1, errors.Wrapf(err, "unmarshalling record value %s", string(data))
 		}
 
-		document := extern.EsDocument{Id: transaction.Hash, Payload: data}
-		if c.isEphemeral(transaction.InputType, transaction.Destination, transaction.Amount) {
-			ephemeralDocuments = append(ephemeralDocuments, document)
-		} else {
-			permanentDocuments = append(permanentDocuments, document)
+		route := c.router.Route(transaction)
+		if route.Transform != nil {
+			data, err = route.Transform.apply(transaction)
+			if err != nil {
+				return -1, errors.Wrapf(err, "transforming transaction [%s] for [%s]", transaction.Hash, route.Index)
+			}
+		}
+		if route.TTL > 0 {
+			data, err = withExpiry(data, transaction, route.TTL)
+			if err != nil {
+				return -1, errors.Wrapf(err, "adding expiry to transaction [%s]", transaction.Hash)
+			}
 		}
+		c.consumerMetrics.IncProcessedMessages()
 
-		if transaction.TickNumber > c.currentTick {
-			// inaccurate, especially with parallel epoch/tick publishers
-			c.currentTick = transaction.TickNumber
-			c.consumerMetrics.IncProcessedTicks()
+		document := tickDocument{
+			tick:        transaction.TickNumber,
+			index:       route.Index,
+			document:    sink.Document{Id: transaction.Hash, Payload: data},
+			transaction: transaction,
+			partition:   topicPartition{topic: record.Topic, partition: record.Partition},
+			offset:      offset,
+		}
+		expected, ok, err := tickTransactionCount(record)
+		if err != nil {
+			return -1, errors.Wrapf(err, "reading tick transaction count of transaction [%s]", transaction.Hash)
+		}
+		if !ok {
+			documents = append(documents, document) // cannot verify completeness
+			continue
 		}
+		documents = append(documents, c.ticks.add(document, expected)...)
+	}
 
-		c.consumerMetrics.IncProcessedMessages()
+	c.reportIncompleteTicks(c.ticks.expire())
+	c.consumerMetrics.SetPendingTicks(c.ticks.incomplete())
+	c.reportIncompletePayloads()
+
+	err := c.indexDocuments(ctx, documents)
+	if err != nil {
+		return -1, err
+	}
+	c.consumerMetrics.SetProcessedTick(c.currentTick)
+
+	// buffered transactions and fragments are not committed
+	defer c.observeCommittedOffsets()
+	err = c.commit(ctx)
+	if err != nil {
+		return -1, err
 	}
+	return len(documents), nil
+}
 
-	if len(ephemeralDocuments) != 0 {
-		err := c.elasticClient.BulkIndex(ctx, ephemeralDocuments, c.ephemeralIndexName)
+// reportIncompletePayloads reports the payloads that are still missing fragments after the timeout or that were
+// released because of the fragment buffer size. They keep blocking the commit position of their partition.
+func (c *TransactionConsumer) reportIncompletePayloads() {
+	expired, released := c.reassembler.expire()
+	for _, id := range expired {
+		zap.S().Errorw("Payload incomplete. Fragments are missing. Holding back the commit.", "fragmentId", id,
+			logging.Duration, c.reassembler.fragmentTimeout())
+		c.consumerMetrics.IncExpiredPayloads()
+	}
+	for _, id := range released {
+		zap.S().Errorw("Fragment buffer full. Releasing incomplete payload and holding back the commit until restart.",
+			"fragmentId", id)
+		c.consumerMetrics.IncExpiredPayloads()
+	}
+	c.consumerMetrics.SetPendingFragments(c.reassembler.fragments())
+}
+
+// Replay consumes until done returns true. The ticks, that are still incomplete then, are reported and not indexed.
+// Returns the number of indexed documents.
+func (c *TransactionConsumer) Replay(ctx context.Context, done func() bool) (int, error) {
+	total := 0
+	for !done() {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		count, err := c.consumeBatch(ctx)
 		if err != nil {
-			return -1, errors.Wrapf(err, "indexing [%d] documents (eph).", len(ephemeralDocuments))
+			return total, errors.Wrap(err, "consuming batch")
 		}
+		total += count
 	}
 
-	if len(permanentDocuments) != 0 {
-		err := c.elasticClient.BulkIndex(ctx, permanentDocuments, c.permanentIndexName)
-		if err != nil {
-			return -1, errors.Wrapf(err, "indexing [%d] documents.", len(permanentDocuments))
+	for tickNumber, tick := range c.ticks.drain() {
+		zap.S().Errorw("Tick incomplete at the end of the replay. Not indexed.", logging.Tick, tickNumber,
+			"received", len(tick.documents), "expected", tick.expected)
+		if !tick.expired {
+			c.consumerMetrics.IncIncompleteTicks()
 		}
 	}
+	c.consumerMetrics.SetPendingTicks(0)
+	return total, nil
+}
 
-	c.consumerMetrics.SetProcessedTick(c.currentTick)
+// reportIncompleteTicks reports the ticks that are still incomplete after the timeout. They are not indexed and keep
+// blocking the commit position of their partition.
+func (c *TransactionConsumer) reportIncompleteTicks(ticks map[uint32]*pendingTick) {
+	for tickNumber, tick := range ticks {
+		zap.S().Errorw("Tick incomplete. Holding back the commit.", logging.Tick, tickNumber,
+			"received", len(tick.documents), "expected", tick.expected, logging.Duration, c.ticks.completionTimeout())
+		c.consumerMetrics.IncIncompleteTicks()
+	}
+}
 
-	err := c.kafkaClient.CommitUncommittedOffsets(ctx)
-	if err != nil {
-		return -1, errors.Wrap(err, "committing offsets")
+// poll polls the next records. While ticks or fragments are pending, polling stops when the next of them times out.
+func (c *TransactionConsumer) poll(ctx context.Context) kgo.Fetches {
+	timeout, pending := c.ticks.nextExpiry()
+	if fragmentTimeout, pendingFragments := c.reassembler.nextExpiry(); pendingFragments && (!pending || fragmentTimeout < timeout) {
+		timeout, pending = fragmentTimeout, true
+	}
+	if !pending {
+		return c.kafkaClient.PollRecords(ctx, c.maxPollRecords) // batch process max x messages in one run
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return c.kafkaClient.PollRecords(pollCtx, c.maxPollRecords)
+}
+
+// isPollTimeout returns true, if polling stopped because of the tick completion or fragment timeout.
+func isPollTimeout(ctx context.Context, errs []kgo.FetchError) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	for _, err := range errs {
+		if !errors.Is(err.Err, context.DeadlineExceeded) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexDocuments indexes the documents grouped by target index.
+func (c *TransactionConsumer) indexDocuments(ctx context.Context, documents []tickDocument) error {
+	var indexNames []string // in order of first occurrence
+	documentsByIndex := make(map[string][]sink.Document)
+	for _, document := range documents {
+		if _, ok := documentsByIndex[document.index]; !ok {
+			indexNames = append(indexNames, document.index)
+		}
+		documentsByIndex[document.index] = append(documentsByIndex[document.index], document.document)
+
+		if document.tick > c.currentTick {
+			// inaccurate, especially with parallel epoch/tick publishers
+			c.currentTick = document.tick
+			c.consumerMetrics.IncProcessedTicks()
+		}
+	}
+
+	for _, indexName := range indexNames {
+		documents := documentsByIndex[indexName]
+		start := time.Now()
+		err := c.elasticClient.BulkIndex(ctx, documents, indexName)
+		if err != nil {
+			return errors.Wrapf(err, "indexing [%d] documents into [%s].", len(documents), indexName)
+		}
+		c.consumerMetrics.ObserveBulkIndexDuration(indexName, time.Since(start))
+	}
+	c.observeSecondsBehind(documents)
+
+	if c.identityClient != nil && len(documents) > 0 {
+		return c.updateIdentities(ctx, documents)
 	}
-	return len(permanentDocuments) + len(ephemeralDocuments), nil
+	return nil
 }
 
-func (c *TransactionConsumer) isEphemeral(inputType uint32, dest string, amount int64) bool {
-	const zeroAddress = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB"
-	return len(c.ephemeralInputTypes) > 0 &&
-		slices.Contains(c.ephemeralInputTypes, inputType) &&
-		dest == zeroAddress &&
-		amount == 0
+// updateIdentities applies the indexed transactions to the identities index.
+func (c *TransactionConsumer) updateIdentities(ctx context.Context, documents []tickDocument) error {
+	updates, err := identityUpdates(documents)
+	if err != nil {
+		return errors.Wrap(err, "creating identity updates")
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	err = c.identityClient.BulkUpdate(ctx, updates, c.identityIndex)
+	if err != nil {
+		return errors.Wrapf(err, "updating [%d] identities in [%s].", len(updates), c.identityIndex)
+	}
+	c.consumerMetrics.AddIdentityUpdates(len(updates))
+	return nil
 }
@@ -6,7 +6,7 @@ import (
 	"log"
 	"testing"
 
-	"github.com/qubic/transactions-consumer/extern"
+	"github.com/qubic/sink"
 	"github.com/qubic/transactions-consumer/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,26 +18,67 @@ var m = metrics.NewMetrics("foo")
 type FakeKafkaClient struct {
 	partitionErr error
 	values       [][]byte
+	polls        [][]*kgo.Record // one entry per poll, if set
+	offsets      map[int32]int64 // next offset per partition
+	commitCount  int
+	committed    map[string]map[int32]kgo.EpochOffset
 }
 
 func (fkc *FakeKafkaClient) PollRecords(_ context.Context, _ int) kgo.Fetches {
+	if fkc.polls != nil {
+		records := fkc.polls[0]
+		fkc.polls = fkc.polls[1:]
+		fkc.assignOffsets(records)
+		return createFetchesFromRecords(nil, records)
+	}
 	return createFetches(fkc.partitionErr, fkc.values...)
 }
 
-func (fkc *FakeKafkaClient) CommitUncommittedOffsets(_ context.Context) error {
+// assignOffsets assigns increasing offsets per partition. Explicit offsets are kept.
+func (fkc *FakeKafkaClient) assignOffsets(records []*kgo.Record) {
+	if fkc.offsets == nil {
+		fkc.offsets = make(map[int32]int64)
+	}
+	for _, record := range records {
+		record.Offset = max(record.Offset, fkc.offsets[record.Partition])
+		fkc.offsets[record.Partition] = record.Offset + 1
+	}
+}
+
+// CommitRecords commits the offsets after the records.
+func (fkc *FakeKafkaClient) CommitRecords(_ context.Context, records ...*kgo.Record) error {
+	fkc.commitCount++
+	if fkc.committed == nil {
+		fkc.committed = make(map[string]map[int32]kgo.EpochOffset)
+	}
+	for _, record := range records {
+		if fkc.committed[record.Topic] == nil {
+			fkc.committed[record.Topic] = make(map[int32]kgo.EpochOffset)
+		}
+		fkc.committed[record.Topic][record.Partition] = kgo.EpochOffset{Epoch: record.LeaderEpoch, Offset: record.Offset + 1}
+	}
 	return nil
 }
 
+// committedOffset returns the committed offset of the partition of the test topic.
+func (fkc *FakeKafkaClient) committedOffset(partition int32) int64 {
+	return fkc.committed[""][partition].Offset
+}
+
+func (fkc *FakeKafkaClient) CommittedOffsets() map[string]map[int32]kgo.EpochOffset {
+	return fkc.committed
+}
+
 func (fkc *FakeKafkaClient) AllowRebalance() {}
 
 type FakeElasticClient struct {
-	BatchesByIndex map[string][]extern.EsDocument
+	BatchesByIndex map[string][]sink.Document
 }
 
-func (c *FakeElasticClient) BulkIndex(_ context.Context, data []extern.EsDocument, indexName string) error {
+func (c *FakeElasticClient) BulkIndex(_ context.Context, data []sink.Document, indexName string) error {
 	log.Printf("Bulk index [%d] documents.", len(data))
 	if c.BatchesByIndex == nil {
-		c.BatchesByIndex = make(map[string][]extern.EsDocument)
+		c.BatchesByIndex = make(map[string][]sink.Document)
 	}
 	c.BatchesByIndex[indexName] = data
 	return nil
@@ -51,11 +92,11 @@ func TestTransactionConsumer_ConsumeBatch_InputMessageEqualsElasticDocument(t *t
 	}
 	localElastic := &FakeElasticClient{}
 	transactionConsumer := &TransactionConsumer{
-		kafkaClient:        kafkaClient,
-		elasticClient:      localElastic,
-		consumerMetrics:    m,
-		permanentIndexName: "default",
-		currentTick:        0,
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("default", "", nil, 0)),
+		currentTick:     0,
 	}
 
 	count, err := transactionConsumer.consumeBatch(t.Context())
@@ -102,7 +143,6 @@ func TestTransactionConsumer_GivenInvalidJson_ThenError(t *testing.T) {
 }
 
 func TestTransactionConsumer_EphemeralAndPermanentIndexedSeparately(t *testing.T) {
-	const zeroAddress = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB"
 	kafkaClient := &FakeKafkaClient{
 		values: [][]byte{
 			[]byte(`{"hash":"ephemeral-tx","source":"src","destination":"` + zeroAddress + `","amount":0,"tickNumber":1,"inputType":6,"inputSize":0,"inputData":"","signature":"","timestamp":0,"moneyFlew":false}`),
@@ -112,12 +152,10 @@ func TestTransactionConsumer_EphemeralAndPermanentIndexedSeparately(t *testing.T
 	}
 	localElastic := &FakeElasticClient{}
 	consumer := &TransactionConsumer{
-		kafkaClient:         kafkaClient,
-		elasticClient:       localElastic,
-		consumerMetrics:     m,
-		permanentIndexName:  "permanent-index",
-		ephemeralIndexName:  "ephemeral-index",
-		ephemeralInputTypes: []uint32{6},
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          newTestRouter(t, DefaultRoutingConfig("permanent-index", "ephemeral-index", []uint32{6}, 0)),
 	}
 
 	count, err := consumer.consumeBatch(t.Context())
@@ -135,8 +173,7 @@ func TestTransactionConsumer_EphemeralAndPermanentIndexedSeparately(t *testing.T
 	assert.Equal(t, "permanent-tx-2", permanentDocs[1].Id)
 }
 
-func TestTransactionConsumer_IsEphemeral(t *testing.T) {
-	const zeroAddress = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB"
+func TestDefaultRoutingConfig_IsEphemeral(t *testing.T) {
 	const otherAddress = "FOO"
 
 	tests := []struct {
@@ -199,9 +236,9 @@ func TestTransactionConsumer_IsEphemeral(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &TransactionConsumer{ephemeralInputTypes: tt.ephemeralInputTypes}
-			got := c.isEphemeral(tt.inputType, tt.dest, tt.amount)
-			assert.Equal(t, tt.want, got)
+			router := newTestRouter(t, DefaultRoutingConfig("permanent", "ephemeral", tt.ephemeralInputTypes, 0))
+			route := router.Route(Transaction{InputType: tt.inputType, Destination: tt.dest, Amount: tt.amount})
+			assert.Equal(t, tt.want, route.Index == "ephemeral")
 		})
 	}
 }
@@ -211,6 +248,10 @@ func createFetches(err error, values ...[]byte) kgo.Fetches {
 	for i, v := range values {
 		records[i] = &kgo.Record{Value: v}
 	}
+	return createFetchesFromRecords(err, records)
+}
+
+func createFetchesFromRecords(err error, records []*kgo.Record) kgo.Fetches {
 	return kgo.Fetches{
 		{
 			Topics: []kgo.FetchTopic{
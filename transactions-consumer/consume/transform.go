@@ -0,0 +1,99 @@
+package consume
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Derived fields that can be added to the documents.
+const (
+	DerivedContractIndex = "contractIndex" // contract index of the destination. Only set for contract destinations.
+	DerivedDate          = "date"          // utc date of the transaction timestamp (yyyy-mm-dd)
+)
+
+const InputDataEncodingHex = "hex"
+
+// Transform changes the shape of the documents of one target index. The steps are applied in the order of the
+// fields: derive, encode, drop, rename.
+type Transform struct {
+	Derive            []string          `yaml:"derive"`            // derived fields to add
+	InputDataEncoding string            `yaml:"inputDataEncoding"` // hex decodes the base64 input data to hex
+	Drop              []string          `yaml:"drop"`              // fields to remove
+	Rename            map[string]string `yaml:"rename"`            // old name -> new name
+}
+
+func (t *Transform) validate(index string) error {
+	for _, field := range t.Derive {
+		if field != DerivedContractIndex && field != DerivedDate {
+			return errors.Errorf("unknown derived field [%s] in transform of [%s]", field, index)
+		}
+	}
+	if t.InputDataEncoding != "" && t.InputDataEncoding != InputDataEncodingHex {
+		return errors.Errorf("unknown input data encoding [%s] in transform of [%s]", t.InputDataEncoding, index)
+	}
+	var targets []string
+	for from, to := range t.Rename {
+		if to == "" || slices.Contains(targets, to) {
+			return errors.Errorf("invalid rename of [%s] in transform of [%s]", from, index)
+		}
+		targets = append(targets, to)
+	}
+	return nil
+}
+
+// apply creates the document from the decoded transaction.
+func (t *Transform) apply(tx Transaction) ([]byte, error) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling transaction")
+	}
+	var document map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // keep the precision of large amounts
+	err = decoder.Decode(&document)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling document")
+	}
+
+	for _, field := range t.Derive {
+		switch field {
+		case DerivedContractIndex:
+			if contract, ok := contractIndex(tx.Destination); ok {
+				document[DerivedContractIndex] = contract
+			}
+		case DerivedDate:
+			document[DerivedDate] = time.UnixMilli(int64(tx.Timestamp)).UTC().Format(time.DateOnly)
+		}
+	}
+
+	if t.InputDataEncoding == InputDataEncodingHex {
+		input, err := base64.StdEncoding.DecodeString(tx.InputData)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding input data of [%s]", tx.Hash)
+		}
+		document["inputData"] = hex.EncodeToString(input)
+	}
+
+	for _, field := range t.Drop {
+		delete(document, field)
+	}
+
+	renamed := make(map[string]any, len(t.Rename))
+	for from, to := range t.Rename {
+		if value, ok := document[from]; ok {
+			renamed[to] = value
+			delete(document, from)
+		}
+	}
+	for field, value := range renamed {
+		document[field] = value
+	}
+
+	return json.Marshal(document)
+}
@@ -0,0 +1,133 @@
+package consume
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func transformTestTransaction() Transaction {
+	return Transaction{
+		Hash:        "czxyxskvzgmdpkzejnsfuwhrsxvbnqdhpiubmtqkbopmjvrjqaqgpwydtgyf",
+		Source:      "BTDXTBFYNBMVCGYBRRTNBZAFUBZNTWSRNSLGMTKGTBNJZTPXJLFHNSLVVQGY",
+		Destination: qxAddress,
+		Amount:      9007199254740993, // not representable as float64
+		TickNumber:  23582758,
+		InputType:   6,
+		InputSize:   4,
+		InputData:   "AQIDBA==",
+		Signature:   "c2lnbmF0dXJl",
+		Timestamp:   1744649165000,
+		MoneyFlew:   true,
+	}
+}
+
+// assertGolden compares the document with the golden file. Run with -update to rewrite the golden files.
+func assertGolden(t *testing.T, name string, document []byte) {
+	path := filepath.Join("testdata", "transform", name+".golden.json")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, append(document, '\n'), 0644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(document)+"\n")
+}
+
+func TestTransform_Apply(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+	}{
+		{name: "identity", transform: Transform{}},
+		{name: "derive", transform: Transform{Derive: []string{DerivedContractIndex, DerivedDate}}},
+		{name: "hex-input", transform: Transform{InputDataEncoding: InputDataEncodingHex}},
+		{name: "drop", transform: Transform{Drop: []string{"signature", "inputData"}}},
+		{name: "rename", transform: Transform{Rename: map[string]string{"tickNumber": "tick", "hash": "id"}}},
+		{name: "combined", transform: Transform{
+			Derive:            []string{DerivedContractIndex},
+			InputDataEncoding: InputDataEncodingHex,
+			Drop:              []string{"signature"},
+			Rename:            map[string]string{"inputData": "inputHex"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.transform.validate("index"))
+			document, err := tt.transform.apply(transformTestTransaction())
+			require.NoError(t, err)
+			assertGolden(t, tt.name, document)
+		})
+	}
+}
+
+func TestTransform_Apply_InvalidInputData(t *testing.T) {
+	tx := transformTestTransaction()
+	tx.InputData = "not base64!"
+	_, err := (&Transform{InputDataEncoding: InputDataEncodingHex}).apply(tx)
+	require.ErrorContains(t, err, "decoding input data")
+}
+
+func TestTransform_Validate(t *testing.T) {
+	require.ErrorContains(t, (&Transform{Derive: []string{"foo"}}).validate("index"), "unknown derived field [foo]")
+	require.ErrorContains(t, (&Transform{InputDataEncoding: "base32"}).validate("index"), "unknown input data encoding")
+	require.ErrorContains(t, (&Transform{Rename: map[string]string{"a": "c", "b": "c"}}).validate("index"), "invalid rename")
+
+	_, err := NewRouter(RoutingConfig{
+		Default:    RoutingTarget{Index: "transactions"},
+		Transforms: map[string]*Transform{"other": {}},
+	}, m)
+	require.ErrorContains(t, err, "transform for unknown index [other]")
+}
+
+const yamlTransformConfig = `
+rules:
+  - name: qx
+    match:
+      contracts: [1]
+    index: qx-transactions
+default:
+  index: transactions
+transforms:
+  qx-transactions:
+    derive: [contractIndex]
+    inputDataEncoding: hex
+    drop: [signature]
+`
+
+func TestTransactionConsumer_TransformsPerIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routing.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yamlTransformConfig), 0644))
+	router, err := NewFileRouter(path, m)
+	require.NoError(t, err)
+
+	kafkaClient := &FakeKafkaClient{
+		values: [][]byte{
+			[]byte(`{"hash":"qx-tx","destination":"` + qxAddress + `","inputData":"AQIDBA==","signature":"sig","tickNumber":1}`),
+			[]byte(`{"hash":"other-tx","destination":"DEST","inputData":"AQIDBA==","signature":"sig","tickNumber":1}`),
+		},
+	}
+	localElastic := &FakeElasticClient{}
+	consumer := &TransactionConsumer{
+		kafkaClient:     kafkaClient,
+		elasticClient:   localElastic,
+		consumerMetrics: m,
+		router:          router,
+	}
+
+	_, err = consumer.consumeBatch(t.Context())
+	require.NoError(t, err)
+
+	qx := localElastic.BatchesByIndex["qx-transactions"]
+	require.Len(t, qx, 1)
+	assert.JSONEq(t, `{"hash":"qx-tx","source":"","destination":"`+qxAddress+`","amount":0,"tickNumber":1,"inputType":0,"inputSize":0,"inputData":"01020304","timestamp":0,"moneyFlew":false,"contractIndex":1}`, string(qx[0].Payload))
+
+	other := localElastic.BatchesByIndex["transactions"]
+	require.Len(t, other, 1)
+	assert.Equal(t, `{"hash":"other-tx","destination":"DEST","inputData":"AQIDBA==","signature":"sig","tickNumber":1}`, string(other[0].Payload), "untransformed documents are indexed as published")
+}
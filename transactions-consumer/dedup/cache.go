@@ -0,0 +1,153 @@
+package dedup
+
+import (
+	"container/list"
+	"encoding/gob"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const stateVersion = 1
+
+type entry struct {
+	Key  string
+	Hash uint64
+}
+
+// Cache remembers the payload hashes of the most recently indexed documents. The number of entries is limited, the
+// least recently used entries are evicted. Documents are only reported as seen, if the cache contains the same payload
+// hash. Evicted documents are indexed again.
+type Cache struct {
+	maxEntries int
+	lru        *list.List // front is the most recently used entry
+	entries    map[string]*list.Element
+	mu         sync.Mutex
+}
+
+func NewCache(maxEntries int) *Cache {
+	maxEntries = max(maxEntries, 1)
+	return &Cache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element, maxEntries),
+	}
+}
+
+// PayloadHash hashes the document payload.
+func PayloadHash(payload []byte) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write(payload)
+	return hash.Sum64()
+}
+
+// Seen checks, if the document was added with the same payload hash.
+func (c *Cache) Seen(key string, hash uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok || element.Value.(*entry).Hash != hash {
+		return false
+	}
+	c.lru.MoveToFront(element)
+	return true
+}
+
+// Add remembers the payload hash of the document.
+func (c *Cache) Add(key string, hash uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(key, hash)
+}
+
+func (c *Cache) add(key string, hash uint64) {
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*entry).Hash = hash
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&entry{Key: key, Hash: hash})
+	if c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).Key)
+	}
+}
+
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// SizeBytes estimates the memory used by the cache.
+func (c *Cache) SizeBytes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size := 0
+	for key := range c.entries {
+		size += len(key) + 100 // key, hash, list element and map overhead
+	}
+	return size
+}
+
+type state struct {
+	Version int
+	Entries []entry // oldest first
+}
+
+// Save writes the entries to the file. The file is replaced atomically.
+func (c *Cache) Save(path string) error {
+	c.mu.Lock()
+	saved := state{Version: stateVersion, Entries: make([]entry, 0, c.lru.Len())}
+	for element := c.lru.Back(); element != nil; element = element.Prev() {
+		saved.Entries = append(saved.Entries, *element.Value.(*entry))
+	}
+	c.mu.Unlock()
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary file")
+	}
+	defer os.Remove(file.Name()) // no-op after the rename
+	err = gob.NewEncoder(file).Encode(saved)
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "encoding state")
+	}
+	err = file.Close()
+	if err != nil {
+		return errors.Wrap(err, "closing temporary file")
+	}
+	return errors.Wrap(os.Rename(file.Name(), path), "replacing state file")
+}
+
+// Load adds the entries of the file. A missing file is not an error.
+func (c *Cache) Load(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "opening state file")
+	}
+	defer file.Close()
+
+	var loaded state
+	err = gob.NewDecoder(file).Decode(&loaded)
+	if err != nil {
+		return errors.Wrap(err, "decoding state")
+	}
+	if loaded.Version != stateVersion {
+		return errors.Errorf("unsupported state version [%d]", loaded.Version)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range loaded.Entries {
+		c.add(e.Key, e.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package dedup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Seen(t *testing.T) {
+	cache := NewCache(10)
+	assert.False(t, cache.Seen("index/a", 1))
+
+	cache.Add("index/a", 1)
+	assert.True(t, cache.Seen("index/a", 1))
+	assert.False(t, cache.Seen("index/a", 2), "changed payload")
+	assert.False(t, cache.Seen("other/a", 1), "other index")
+
+	cache.Add("index/a", 2)
+	assert.True(t, cache.Seen("index/a", 2))
+	assert.False(t, cache.Seen("index/a", 1))
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(2)
+	cache.Add("a", 1)
+	cache.Add("b", 1)
+	assert.True(t, cache.Seen("a", 1)) // a is now more recent than b
+	cache.Add("c", 1)
+
+	assert.Equal(t, 2, cache.Len())
+	assert.True(t, cache.Seen("a", 1))
+	assert.False(t, cache.Seen("b", 1))
+	assert.True(t, cache.Seen("c", 1))
+}
+
+func TestCache_BoundedMemory(t *testing.T) {
+	cache := NewCache(1000)
+	for i := range 100_000 {
+		cache.Add(fmt.Sprintf("index/%d", i), uint64(i))
+	}
+	assert.Equal(t, 1000, cache.Len())
+	assert.Less(t, cache.SizeBytes(), 1000*200)
+	for i := 99_000; i < 100_000; i++ {
+		require.True(t, cache.Seen(fmt.Sprintf("index/%d", i), uint64(i)))
+	}
+}
+
+func TestCache_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.state")
+	cache := NewCache(2)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	cache.Add("c", 3)
+	require.NoError(t, cache.Save(path))
+
+	loaded := NewCache(2)
+	require.NoError(t, loaded.Load(path))
+	assert.Equal(t, 2, loaded.Len())
+	assert.False(t, loaded.Seen("a", 1))
+	assert.True(t, loaded.Seen("b", 2))
+	assert.True(t, loaded.Seen("c", 3))
+
+	// order is kept: b is the oldest entry
+	loaded.Add("d", 4)
+	assert.False(t, loaded.Seen("b", 2))
+	assert.True(t, loaded.Seen("c", 3))
+}
+
+func TestCache_LoadMissingFile(t *testing.T) {
+	cache := NewCache(2)
+	require.NoError(t, cache.Load(filepath.Join(t.TempDir(), "missing")))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_LoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.state")
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o644))
+	assert.Error(t, NewCache(2).Load(path))
+}
@@ -0,0 +1,53 @@
+package dedup
+
+import (
+	"context"
+
+	"github.com/qubic/sink"
+	"github.com/qubic/transactions-consumer/metrics"
+)
+
+type DocumentClient interface {
+	BulkIndex(ctx context.Context, data []sink.Document, indexName string) error
+}
+
+// Client skips documents, that were indexed recently with the same payload. Document ids are deterministic, so
+// re-indexing them does not change anything, but re-consumed ranges would be sent again.
+type Client struct {
+	next    DocumentClient
+	cache   *Cache
+	metrics *metrics.Metrics
+}
+
+func NewClient(next DocumentClient, cache *Cache, m *metrics.Metrics) *Client {
+	return &Client{next: next, cache: cache, metrics: m}
+}
+
+// BulkIndex sends the documents, that are not in the cache. The documents are added to the cache after they were
+// indexed successfully.
+func (c *Client) BulkIndex(ctx context.Context, data []sink.Document, indexName string) error {
+	keys := make([]string, len(data))
+	hashes := make([]uint64, len(data))
+	documents := make([]sink.Document, 0, len(data))
+	for i, document := range data {
+		keys[i] = indexName + "/" + document.Id
+		hashes[i] = PayloadHash(document.Payload)
+		if !c.cache.Seen(keys[i], hashes[i]) {
+			documents = append(documents, document)
+		}
+	}
+	c.metrics.AddDedupHits(len(data) - len(documents))
+	c.metrics.AddDedupMisses(len(documents))
+
+	if len(documents) > 0 {
+		err := c.next.BulkIndex(ctx, documents, indexName)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range data {
+		c.cache.Add(keys[i], hashes[i])
+	}
+	c.metrics.SetDedupEntries(c.cache.Len())
+	return nil
+}
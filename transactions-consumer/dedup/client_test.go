@@ -0,0 +1,59 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qubic/sink"
+	"github.com/qubic/transactions-consumer/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m = metrics.NewMetrics("dedup_test")
+
+type FakeDocumentClient struct {
+	batches [][]string
+	err     error
+}
+
+func (f *FakeDocumentClient) BulkIndex(_ context.Context, data []sink.Document, _ string) error {
+	var ids []string
+	for _, document := range data {
+		ids = append(ids, document.Id)
+	}
+	f.batches = append(f.batches, ids)
+	return f.err
+}
+
+func documents(payloads ...string) []sink.Document {
+	var result []sink.Document
+	for i, payload := range payloads {
+		result = append(result, sink.Document{Id: string(rune('a' + i)), Payload: []byte(payload)})
+	}
+	return result
+}
+
+func TestClient_BulkIndex_SkipsIndexedDocuments(t *testing.T) {
+	next := &FakeDocumentClient{}
+	client := NewClient(next, NewCache(10), m)
+
+	require.NoError(t, client.BulkIndex(context.Background(), documents(`{"x":1}`, `{"x":2}`), "index"))
+	require.NoError(t, client.BulkIndex(context.Background(), documents(`{"x":1}`, `{"x":3}`, `{"x":4}`), "index"))
+	require.NoError(t, client.BulkIndex(context.Background(), documents(`{"x":1}`), "other-index"))
+	require.NoError(t, client.BulkIndex(context.Background(), documents(`{"x":1}`), "index"))
+
+	assert.Equal(t, [][]string{{"a", "b"}, {"b", "c"}, {"a"}}, next.batches)
+}
+
+func TestClient_BulkIndex_DoesNotCacheFailedDocuments(t *testing.T) {
+	next := &FakeDocumentClient{err: errors.New("test")}
+	client := NewClient(next, NewCache(10), m)
+
+	require.Error(t, client.BulkIndex(context.Background(), documents(`{"x":1}`), "index"))
+	next.err = nil
+	require.NoError(t, client.BulkIndex(context.Background(), documents(`{"x":1}`), "index"))
+
+	assert.Equal(t, [][]string{{"a"}, {"a"}}, next.batches)
+}
@@ -0,0 +1,186 @@
+package expiry
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qubic/logging"
+	"github.com/qubic/transactions-consumer/metrics"
+	"go.uber.org/zap"
+)
+
+// Run results for the metrics.
+const (
+	resultDeleted = "deleted"
+	resultDryRun  = "dry_run"
+	resultSkipped = "skipped"
+	resultError   = "error"
+)
+
+// expiresAtField contains the expiry timestamp (milliseconds), that the consumer adds to the documents of routing
+// targets with a ttl. IMPORTANT: it needs to match the consumer code.
+const expiresAtField = "expiresAt"
+
+type Client interface {
+	ResolveIndices(ctx context.Context, name string) ([]string, error)
+	Count(ctx context.Context, indices []string, query []byte) (int64, error)
+	DeleteByQuery(ctx context.Context, indices []string, query []byte) (int64, error)
+}
+
+// Targets returns the index names of the current routing. Documents routed to an expiring index have an expiry
+// timestamp. The permanent indices must never be targeted.
+type Targets func() (expiring []string, permanent []string)
+
+type Config struct {
+	Targets          Targets
+	ProtectedIndices []string // further indices or aliases, that must never be targeted (for example the identities index)
+	Interval         time.Duration
+	DryRun           bool // only count the expired documents
+}
+
+// Job deletes the expired documents of the expiring indices. It refuses to touch indices, that belong to a permanent
+// or protected index.
+type Job struct {
+	client  Client
+	config  Config
+	metrics *metrics.Metrics
+	clock   func() time.Time // for testing
+}
+
+// NewJob validates the configuration and checks the safeguards once.
+func NewJob(ctx context.Context, client Client, config Config, m *metrics.Metrics) (*Job, error) {
+	if config.Targets == nil {
+		return nil, errors.New("missing routing targets")
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("invalid interval")
+	}
+	if expiring, _ := config.Targets(); len(expiring) == 0 {
+		return nil, errors.New("no routing target with ttl")
+	}
+	job := &Job{client: client, config: config, metrics: m, clock: time.Now}
+	_, err := job.targetIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// targetIndices resolves the expiring indices and verifies, that none of the concrete indices is permanent or
+// protected. Aliases and routing rules can change (for example on rollover or reload), so this is checked before every
+// run.
+func (j *Job) targetIndices(ctx context.Context) ([]string, error) {
+	expiring, permanent := j.config.Targets()
+	protected := slices.Concat(permanent, j.config.ProtectedIndices)
+
+	protectedIndices := make(map[string]string) // concrete index -> protected name
+	for _, name := range protected {
+		resolved, err := j.client.ResolveIndices(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving protected index [%s]", name)
+		}
+		for _, index := range resolved {
+			protectedIndices[index] = name
+		}
+	}
+
+	var targets []string
+	for _, name := range expiring {
+		if name == "" || name == "_all" || strings.ContainsAny(name, "*,") || strings.HasPrefix(name, "-") {
+			return nil, errors.Errorf("invalid expiry index [%s]", name)
+		}
+		if slices.Contains(protected, name) {
+			return nil, errors.Errorf("expiry index [%s] is protected", name)
+		}
+		resolved, err := j.client.ResolveIndices(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving [%s]", name)
+		}
+		if len(resolved) == 0 {
+			return nil, errors.Errorf("expiry index [%s] does not exist", name)
+		}
+		for _, index := range resolved {
+			if owner, ok := protectedIndices[index]; ok {
+				return nil, errors.Errorf("expiry index [%s] contains index [%s] of protected index [%s]", name, index, owner)
+			}
+			if !slices.Contains(targets, index) {
+				targets = append(targets, index)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// Run runs the job in the interval until the context is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+	for {
+		err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			zap.S().Errorw("Expiring documents failed.", logging.Error, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes (or counts in dry run mode) the expired documents. Only the verified concrete indices are targeted,
+// not the aliases, that could point to other indices in the meantime.
+func (j *Job) RunOnce(ctx context.Context) error {
+	targets, err := j.targetIndices(ctx)
+	if err != nil {
+		j.metrics.IncExpiryRuns(resultError)
+		return err
+	}
+	if len(targets) == 0 {
+		j.metrics.IncExpiryRuns(resultSkipped) // the current rules have no ttl
+		return nil
+	}
+	query, err := j.query()
+	if err != nil {
+		j.metrics.IncExpiryRuns(resultError)
+		return err
+	}
+
+	if j.config.DryRun {
+		count, err := j.client.Count(ctx, targets, query)
+		if err != nil {
+			j.metrics.IncExpiryRuns(resultError)
+			return errors.Wrap(err, "counting expired documents")
+		}
+		j.metrics.SetExpiryCandidates(count)
+		j.metrics.IncExpiryRuns(resultDryRun)
+		zap.S().Infow("Dry run: counted expired documents.", "count", count, "indices", targets, "query", string(query))
+		return nil
+	}
+
+	deleted, err := j.client.DeleteByQuery(ctx, targets, query)
+	j.metrics.AddExpiredDocuments(deleted)
+	if err != nil {
+		j.metrics.IncExpiryRuns(resultError)
+		return errors.Wrap(err, "deleting expired documents")
+	}
+	j.metrics.IncExpiryRuns(resultDeleted)
+	zap.S().Infow("Deleted expired documents.", "count", deleted, "indices", targets, "query", string(query))
+	return nil
+}
+
+// query creates the range query for the documents, that expired before now. Documents without expiry timestamp do
+// not match.
+func (j *Job) query() ([]byte, error) {
+	query, err := json.Marshal(map[string]any{
+		"query": map[string]any{"range": map[string]any{expiresAtField: map[string]any{"lt": j.clock().UnixMilli()}}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling query")
+	}
+	return query, nil
+}
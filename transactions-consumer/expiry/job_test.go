@@ -0,0 +1,152 @@
+package expiry
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/qubic/transactions-consumer/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m = metrics.NewMetrics("expiry_test")
+
+type FakeClient struct {
+	indices map[string][]string // name -> concrete indices
+	count   int64
+	counted []string
+	deleted []string
+	targets [][]string
+}
+
+func (f *FakeClient) ResolveIndices(_ context.Context, name string) ([]string, error) {
+	return f.indices[name], nil
+}
+
+func (f *FakeClient) Count(_ context.Context, indices []string, query []byte) (int64, error) {
+	f.counted = append(f.counted, string(query))
+	f.targets = append(f.targets, indices)
+	return f.count, nil
+}
+
+func (f *FakeClient) DeleteByQuery(_ context.Context, indices []string, query []byte) (int64, error) {
+	f.deleted = append(f.deleted, string(query))
+	f.targets = append(f.targets, indices)
+	return f.count, nil
+}
+
+func newFakeClient() *FakeClient {
+	return &FakeClient{
+		indices: map[string][]string{
+			"eph-write":         {"eph-000002"},
+			"transactions":      {"transactions-000001"},
+			"transactions-bad":  {"transactions-000001", "eph-000002"},
+			"identities":        {"identities-000001"},
+			"transactions-0001": {"transactions-000001"},
+		},
+		count: 42,
+	}
+}
+
+// targets returns fixed routing targets.
+func targets(expiring []string, permanent ...string) Targets {
+	return func() ([]string, []string) { return expiring, permanent }
+}
+
+func TestNewJob_Safeguards(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "no targets", config: Config{Interval: time.Hour}},
+		{name: "no target with ttl", config: Config{Targets: targets(nil, "transactions"), Interval: time.Hour}},
+		{name: "no interval", config: Config{Targets: targets([]string{"eph-write"}, "transactions")}},
+		{name: "empty index", config: Config{Targets: targets([]string{""}), Interval: time.Hour}},
+		{name: "wildcard", config: Config{Targets: targets([]string{"eph-*"}), Interval: time.Hour}},
+		{name: "several indices", config: Config{Targets: targets([]string{"eph-write,transactions"}), Interval: time.Hour}},
+		{name: "all", config: Config{Targets: targets([]string{"_all"}), Interval: time.Hour}},
+		{name: "unknown index", config: Config{Targets: targets([]string{"unknown"}), Interval: time.Hour}},
+		{name: "target with and without ttl", config: Config{Targets: targets([]string{"transactions"}, "transactions"),
+			Interval: time.Hour}},
+		{name: "protected name", config: Config{Targets: targets([]string{"identities"}), ProtectedIndices: []string{"identities"},
+			Interval: time.Hour}},
+		{name: "other name of permanent index", config: Config{Targets: targets([]string{"transactions-0001"}, "transactions"),
+			Interval: time.Hour}},
+		{name: "overlap with permanent index", config: Config{Targets: targets([]string{"eph-write"}, "transactions-bad"),
+			Interval: time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJob(context.Background(), newFakeClient(), tt.config, m)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestJob_RunOnce(t *testing.T) {
+	client := newFakeClient()
+	client.indices["other-eph-write"] = []string{"other-eph-000001", "eph-000002"}
+	job, err := NewJob(context.Background(), client, Config{
+		Targets:          targets([]string{"eph-write", "other-eph-write"}, "transactions"),
+		ProtectedIndices: []string{"identities"},
+		Interval:         time.Hour,
+	}, m)
+	require.NoError(t, err)
+	now := time.Date(2025, time.April, 14, 8, 0, 0, 0, time.UTC)
+	job.clock = func() time.Time { return now }
+
+	require.NoError(t, job.RunOnce(context.Background()))
+	assert.Equal(t, []string{`{"query":{"range":{"expiresAt":{"lt":` + strconv.FormatInt(now.UnixMilli(), 10) + `}}}}`}, client.deleted)
+	assert.Equal(t, [][]string{{"eph-000002", "other-eph-000001"}}, client.targets, "deletes from the verified indices, not the aliases")
+}
+
+func TestJob_RunOnce_DryRun(t *testing.T) {
+	client := newFakeClient()
+	job, err := NewJob(context.Background(), client, Config{
+		Targets:  targets([]string{"eph-write"}, "transactions"),
+		Interval: time.Hour,
+		DryRun:   true,
+	}, m)
+	require.NoError(t, err)
+	job.clock = func() time.Time { return time.UnixMilli(5000) }
+
+	require.NoError(t, job.RunOnce(context.Background()))
+	assert.Empty(t, client.deleted)
+	assert.Equal(t, []string{`{"query":{"range":{"expiresAt":{"lt":5000}}}}`}, client.counted)
+	assert.Equal(t, [][]string{{"eph-000002"}}, client.targets)
+}
+
+func TestJob_RunOnce_ChecksSafeguardsBeforeEveryRun(t *testing.T) {
+	client := newFakeClient()
+	job, err := NewJob(context.Background(), client, Config{
+		Targets:  targets([]string{"eph-write"}, "transactions"),
+		Interval: time.Hour,
+	}, m)
+	require.NoError(t, err)
+
+	client.indices["eph-write"] = []string{"eph-000002", "transactions-000001"} // alias changed
+	require.Error(t, job.RunOnce(context.Background()))
+	assert.Empty(t, client.deleted)
+}
+
+func TestJob_RunOnce_FollowsTheRouting(t *testing.T) {
+	client := newFakeClient()
+	expiring, permanent := []string{"eph-write"}, []string{"transactions"}
+	job, err := NewJob(context.Background(), client, Config{
+		Targets:  func() ([]string, []string) { return expiring, permanent },
+		Interval: time.Hour,
+	}, m)
+	require.NoError(t, err)
+
+	// the ttl was removed from the rules
+	expiring, permanent = nil, []string{"transactions", "eph-write"}
+	require.NoError(t, job.RunOnce(context.Background()))
+	assert.Empty(t, client.deleted, "skipped")
+
+	// the ephemeral index became permanent, but documents are still routed with ttl to an alias of it
+	expiring, permanent = []string{"eph-000002"}, []string{"transactions", "eph-write"}
+	require.Error(t, job.RunOnce(context.Background()))
+	assert.Empty(t, client.deleted)
+}
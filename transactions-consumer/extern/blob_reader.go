@@ -0,0 +1,26 @@
+package extern
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FileBlobReader reads payloads offloaded by the producer from a shared folder.
+type FileBlobReader struct {
+	dir string
+}
+
+func NewFileBlobReader(dir string) *FileBlobReader {
+	return &FileBlobReader{dir: dir}
+}
+
+func (r *FileBlobReader) Get(ref string) ([]byte, error) {
+	// references are file names relative to the folder
+	data, err := os.ReadFile(filepath.Join(r.dir, filepath.Base(ref)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading blob file [%s]", ref)
+	}
+	return data, nil
+}
@@ -0,0 +1,105 @@
+package extern
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// ResolveIndices returns the concrete indices of an index, alias or data stream name.
+func (c *ElasticClient) ResolveIndices(ctx context.Context, name string) ([]string, error) {
+	res, err := c.esClient.Indices.ResolveIndex([]string{name},
+		c.esClient.Indices.ResolveIndex.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving index")
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, errors.Errorf("got error response from elastic: %s", res.String())
+	}
+
+	var result struct {
+		Indices []struct {
+			Name string `json:"name"`
+		} `json:"indices"`
+		Aliases []struct {
+			Indices []string `json:"indices"`
+		} `json:"aliases"`
+		DataStreams []struct {
+			BackingIndices []string `json:"backing_indices"`
+		} `json:"data_streams"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "decoding response")
+	}
+	var indices []string
+	for _, index := range result.Indices {
+		indices = append(indices, index.Name)
+	}
+	for _, alias := range result.Aliases {
+		indices = append(indices, alias.Indices...)
+	}
+	for _, dataStream := range result.DataStreams {
+		indices = append(indices, dataStream.BackingIndices...)
+	}
+	slices.Sort(indices)
+	return slices.Compact(indices), nil
+}
+
+// Count returns the number of documents of the indices matching the query.
+func (c *ElasticClient) Count(ctx context.Context, indices []string, query []byte) (int64, error) {
+	res, err := c.esClient.Count(
+		c.esClient.Count.WithContext(ctx),
+		c.esClient.Count.WithIndex(indices...),
+		c.esClient.Count.WithBody(bytes.NewReader(query)),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "counting documents")
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, errors.Errorf("got error response from elastic: %s", res.String())
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, errors.Wrap(err, "decoding response")
+	}
+	return result.Count, nil
+}
+
+// DeleteByQuery deletes the documents of the indices matching the query and returns the number of deleted documents.
+// Version conflicts are ignored.
+func (c *ElasticClient) DeleteByQuery(ctx context.Context, indices []string, query []byte) (int64, error) {
+	res, err := c.esClient.DeleteByQuery(indices, bytes.NewReader(query),
+		c.esClient.DeleteByQuery.WithContext(ctx),
+		c.esClient.DeleteByQuery.WithConflicts("proceed"),
+		c.esClient.DeleteByQuery.WithSlices("auto"),
+		c.esClient.DeleteByQuery.WithWaitForCompletion(true),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting documents")
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, errors.Errorf("got error response from elastic: %s", res.String())
+	}
+
+	var result struct {
+		Deleted  int64             `json:"deleted"`
+		Failures []json.RawMessage `json:"failures"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, errors.Wrap(err, "decoding response")
+	}
+	if len(result.Failures) > 0 {
+		return result.Deleted, errors.Errorf("%d failures deleting documents: %s", len(result.Failures), result.Failures[0])
+	}
+	return result.Deleted, nil
+}
@@ -1,81 +1,20 @@
 package extern
 
 import (
-	"bytes"
-	"context"
-	"log"
-	"runtime"
-	"time"
-
 	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/elastic/go-elasticsearch/v8/esutil"
-	"github.com/pkg/errors"
+	"github.com/qubic/sink"
 )
 
+// ElasticClient writes documents with the elasticsearch sink and offers the admin functions, that are only available
+// with the official elasticsearch client.
 type ElasticClient struct {
+	*sink.ElasticClient
 	esClient *elasticsearch.Client
 }
 
 func NewElasticClient(esClient *elasticsearch.Client) *ElasticClient {
 	return &ElasticClient{
-		esClient: esClient,
-	}
-}
-
-type EsDocument struct {
-	Id      string
-	Payload []byte
-}
-
-func (c *ElasticClient) BulkIndex(ctx context.Context, data []EsDocument, indexName string) error {
-	start := time.Now().UnixMilli()
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Index:      indexName,
-		Client:     c.esClient,
-		NumWorkers: min(runtime.NumCPU(), 8), // 8 parallel connections are enough
-	})
-	if err != nil {
-		return errors.Wrap(err, "Error creating bulk indexer")
-	}
-
-	for _, d := range data {
-		item := esutil.BulkIndexerItem{
-			Action:       "index",
-			DocumentID:   d.Id,
-			RequireAlias: true,
-			Body:         bytes.NewReader(d.Payload),
-			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
-				msg := "Error indexing document"
-				if err != nil {
-					log.Printf("%s [%s]: %s: [%s]", msg, d.Id, string(d.Payload), err)
-				} else {
-					log.Printf("%s [%s]: %s: [%s: %s]", msg, d.Id, string(d.Payload), res.Error.Type, res.Error.Reason)
-				}
-			},
-		}
-		err = bi.Add(ctx, item)
-	}
-
-	err = bi.Close(ctx)
-	if err != nil {
-		return errors.Wrap(err, "Error closing bulk indexer")
-	}
-
-	biStats := bi.Stats()
-	end := time.Now().UnixMilli()
-	if biStats.NumFailed > 0 {
-		return errors.Errorf("%d errors indexing [%d] documents",
-			biStats.NumFailed,
-			biStats.NumFlushed,
-		)
-	} else {
-		log.Printf("Indexed %d documents (%d bytes, %d requests) into %s in %dms.",
-			biStats.NumFlushed,
-			biStats.FlushedBytes,
-			biStats.NumRequests,
-			indexName,
-			end-start,
-		)
+		ElasticClient: sink.NewElasticClient(esClient),
+		esClient:      esClient,
 	}
-	return nil
 }
@@ -12,29 +12,38 @@ import (
 	"time"
 
 	"github.com/ardanlabs/conf"
-	"github.com/elastic/elastic-transport-go/v8/elastictransport"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/logging"
+	"github.com/qubic/replay"
+	"github.com/qubic/sink"
 	"github.com/qubic/transactions-consumer/consume"
+	"github.com/qubic/transactions-consumer/dedup"
+	"github.com/qubic/transactions-consumer/expiry"
 	"github.com/qubic/transactions-consumer/extern"
 	"github.com/qubic/transactions-consumer/metrics"
 	"github.com/qubic/transactions-consumer/status"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
+	"go.uber.org/zap"
 )
 
 const envPrefix = "QUBIC_TRANSACTIONS_CONSUMER"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
+	logger, logLevel, err := logging.New("transactions-consumer", "info")
+	if err != nil {
+		log.Fatalf("main: creating logger: %v", err)
+	}
+	if err := run(logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
 	}
+	_ = logger.Sync()
 }
 
-func run() error {
-	log.SetOutput(os.Stdout) // default is stderr
+func run(logLevel zap.AtomicLevel) error {
 
 	var cfg struct {
 		Elastic struct {
@@ -43,9 +52,12 @@ func run() error {
 			Password           string   `conf:"optional,mask"`
 			IndexName          string   `conf:"default:qubic-transactions-write"`
 			EphemeralIndexName string   `conf:"default:qubic-eph-transactions-write"`
+			IdentityIndexName  string   `conf:"default:qubic-identities-write"`
 			Certificate        string   `conf:"default:http_ca.crt"`
 			MaxRetries         int      `conf:"default:15"`
-			Stub               bool     `conf:"optional"` // only for testing
+			Sink               string   `conf:"default:elasticsearch"` // elasticsearch, opensearch or ndjson
+			BulkUrl            string   `conf:"optional"`              // bulk endpoint of the ndjson sink. Defaults to <address>/_bulk.
+			Stub               bool     `conf:"optional"`              // only for testing
 		}
 		Broker struct {
 			BootstrapServers []string `conf:"default:localhost:9092"`
@@ -56,9 +68,30 @@ func run() error {
 			MaxPollRecords   int      `conf:"default:4096"` // default 1 tick max
 		}
 		Sync struct {
-			EphemeralInputTypes []uint32 `conf:"optional"`
-			Enabled             bool     `conf:"default:true"` // only for testing
+			EphemeralInputTypes   []uint32      `conf:"optional"`
+			EphemeralTTL          time.Duration `conf:"optional"`      // adds an expiry timestamp to ephemeral documents
+			RoutingConfigFile     string        `conf:"optional"`      // yaml or json routing rules. Replaces the ephemeral routing.
+			RoutingReloadInterval time.Duration `conf:"default:30s"`   // check interval for routing config changes
+			BlobStoreFolder       string        `conf:"optional"`      // shared folder with offloaded payloads of the producer
+			FragmentTimeout       time.Duration `conf:"default:5m"`    // time to wait for missing fragments of a payload
+			FragmentBufferMB      int           `conf:"default:256"`   // maximum size of buffered fragments
+			TickCompletionTimeout time.Duration `conf:"default:1m"`    // time to wait for missing transactions of a tick
+			Identities            bool          `conf:"default:false"` // maintain the identities aggregate index
+			DedupCacheMaxEntries  int           `conf:"default:0"`     // number of recently indexed documents to skip on re-consumption. 0 disables.
+			DedupStateFile        string        `conf:"optional"`      // file to keep the deduplication cache across restarts
+			Enabled               bool          `conf:"default:true"`  // only for testing
+		}
+		Expiry struct {
+			Enabled          bool          `conf:"default:false"` // delete the expired documents of the routing targets with ttl
+			Interval         time.Duration `conf:"default:1h"`
+			DryRun           bool          `conf:"default:false"` // only count the expired documents
+			ProtectedIndices []string      `conf:"optional"`      // further indices, that must never be targeted. Targets without ttl are always protected.
 		}
+		Log struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
+		Replay replayOptions
+		Args   conf.Args
 	}
 
 	// load config
@@ -86,28 +119,15 @@ func run() error {
 	if err != nil {
 		return errors.Wrap(err, "generating config for output")
 	}
-	log.Printf("main: Config :\n%v\n", out)
-
-	m := kprom.NewMetrics(cfg.Broker.MetricsNamespace,
-		kprom.Registerer(prometheus.DefaultRegisterer),
-		kprom.Gatherer(prometheus.DefaultGatherer))
-	kcl, err := kgo.NewClient(
-		kgo.WithHooks(m),
-		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
-		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
-		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
-		kgo.BlockRebalanceOnPoll(),
-		kgo.DisableAutoCommit(),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
-	)
+	zap.S().Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
 	if err != nil {
-		log.Fatal(err)
+		return errors.Wrap(err, "setting log level")
 	}
-	defer kcl.Close()
 
 	cert, err := os.ReadFile(cfg.Elastic.Certificate)
 	if err != nil {
-		log.Printf("[WARN] main: could not read elastic certificate: %v", err)
+		zap.S().Warnw("Could not read elastic certificate.", logging.Error, err)
 	}
 
 	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
@@ -118,37 +138,158 @@ func run() error {
 		RetryOnStatus: []int{502, 503, 504, 429},
 		MaxRetries:    cfg.Elastic.MaxRetries,
 		RetryBackoff:  calculateBackoff(),
-		Logger: extern.NewLogger(&elastictransport.TextLogger{
-			Output:             os.Stdout,
-			EnableRequestBody:  false,
-			EnableResponseBody: true, // Make response body available in the logger so we can log response
-		}),
+		Logger:        extern.NewLogger(),
 	})
-	var elasticClient consume.ElasticDocumentClient
+	var elasticClient interface {
+		consume.ElasticDocumentClient
+		consume.IdentityUpdateClient
+	}
 	if cfg.Elastic.Stub {
-		log.Printf("[WARN] main: Using stub ES client.")
+		zap.S().Warn("Using stub ES client.")
 		elasticClient = &ElasticClientStub{}
 	} else {
-		elasticClient = extern.NewElasticClient(esClient)
+		switch cfg.Elastic.Sink {
+		case sink.Elasticsearch:
+			elasticClient = extern.NewElasticClient(esClient)
+		case sink.OpenSearch, sink.Ndjson:
+			elasticClient, err = sink.NewHttpBulkClient(context.Background(), sink.HttpBulkConfig{
+				Sink:         cfg.Elastic.Sink,
+				Addresses:    cfg.Elastic.Addresses,
+				BulkUrl:      cfg.Elastic.BulkUrl,
+				Username:     cfg.Elastic.Username,
+				Password:     cfg.Elastic.Password,
+				CACert:       cert,
+				MaxRetries:   cfg.Elastic.MaxRetries,
+				RetryBackoff: calculateBackoff(),
+			})
+			if err != nil {
+				return errors.Wrapf(err, "creating %s client", cfg.Elastic.Sink)
+			}
+		default:
+			return errors.Errorf("unknown sink [%s]", cfg.Elastic.Sink)
+		}
 	}
 	processingMetrics := metrics.NewMetrics(cfg.Broker.MetricsNamespace)
+
+	consumerCtx, consumerCtxCancel := context.WithCancel(context.Background())
+	defer consumerCtxCancel()
+
+	var router *consume.Router
+	if cfg.Sync.RoutingConfigFile != "" {
+		router, err = consume.NewFileRouter(cfg.Sync.RoutingConfigFile, processingMetrics)
+		if err != nil {
+			return errors.Wrap(err, "creating router")
+		}
+		go router.Watch(consumerCtx, cfg.Sync.RoutingReloadInterval)
+	} else {
+		routingConfig := consume.DefaultRoutingConfig(cfg.Elastic.IndexName, cfg.Elastic.EphemeralIndexName, cfg.Sync.EphemeralInputTypes,
+			cfg.Sync.EphemeralTTL)
+		router, err = consume.NewRouter(routingConfig, processingMetrics)
+		if err != nil {
+			return errors.Wrap(err, "creating router")
+		}
+	}
+
 	consumerConfig := &consume.ConsumerConfig{
-		PermanentIndexName:  cfg.Elastic.IndexName,
-		EphemeralIndexName:  cfg.Elastic.EphemeralIndexName,
-		EphemeralInputTypes: cfg.Sync.EphemeralInputTypes,
-		MaxPollRecords:      cfg.Broker.MaxPollRecords,
+		Router:                router,
+		MaxPollRecords:        cfg.Broker.MaxPollRecords,
+		TickCompletionTimeout: cfg.Sync.TickCompletionTimeout,
+		FragmentTimeout:       cfg.Sync.FragmentTimeout,
+		MaxFragmentedBytes:    cfg.Sync.FragmentBufferMB * 1024 * 1024,
+	}
+	if cfg.Sync.Identities {
+		consumerConfig.IdentityClient = elasticClient
+		consumerConfig.IdentityIndexName = cfg.Elastic.IdentityIndexName
+	}
+	if cfg.Sync.BlobStoreFolder != "" {
+		consumerConfig.BlobReader = extern.NewFileBlobReader(cfg.Sync.BlobStoreFolder)
+	}
+
+	if len(cfg.Args) > 0 {
+		replayConfig, err := replay.NewConfig(cfg.Broker.ConsumeTopic, cfg.Replay.Options, cfg.Replay.TickRangeOptions)
+		if err != nil {
+			return errors.Wrap(err, "parsing replay config")
+		}
+		return runReplayCommand(consumerCtx, cfg.Args, replayCommand{
+			config:         replayConfig,
+			brokers:        cfg.Broker.BootstrapServers,
+			targetIndex:    cfg.Replay.TargetIndex,
+			dryRun:         cfg.Replay.DryRun,
+			elasticClient:  elasticClient,
+			consumerConfig: *consumerConfig,
+			metrics:        processingMetrics,
+		})
+	}
+
+	m := kprom.NewMetrics(cfg.Broker.MetricsNamespace,
+		kprom.Registerer(prometheus.DefaultRegisterer),
+		kprom.Gatherer(prometheus.DefaultGatherer))
+	revocations := &consume.Revocations{}
+	consumerConfig.Revocations = revocations
+	kcl, err := kgo.NewClient(
+		kgo.WithHooks(m),
+		kgo.SeedBrokers(cfg.Broker.BootstrapServers...),
+		kgo.ConsumeTopics(cfg.Broker.ConsumeTopic),
+		kgo.ConsumerGroup(cfg.Broker.ConsumerGroup),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.DisableAutoCommit(),
+		kgo.OnPartitionsRevoked(revocations.Revoked),
+		kgo.OnPartitionsLost(revocations.Revoked),
+		kgo.WithLogger(logging.NewKafkaLogger()),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating kafka client")
+	}
+	defer kcl.Close()
+
+	var documentClient consume.ElasticDocumentClient = elasticClient
+	var dedupCache *dedup.Cache
+	if cfg.Sync.DedupCacheMaxEntries > 0 {
+		dedupCache = dedup.NewCache(cfg.Sync.DedupCacheMaxEntries)
+		if cfg.Sync.DedupStateFile != "" {
+			err = dedupCache.Load(cfg.Sync.DedupStateFile)
+			if err != nil {
+				zap.S().Warnw("Could not load deduplication cache.", logging.Error, err)
+			}
+		}
+		zap.S().Infow("Using deduplication cache.", "entries", dedupCache.Len(), "bytes", dedupCache.SizeBytes())
+		documentClient = dedup.NewClient(elasticClient, dedupCache, processingMetrics)
+	}
+	saveDedupCache := func() {
+		if dedupCache == nil || cfg.Sync.DedupStateFile == "" {
+			return
+		}
+		if err := dedupCache.Save(cfg.Sync.DedupStateFile); err != nil {
+			zap.S().Warnw("Could not save deduplication cache.", logging.Error, err)
+		}
+	}
+
+	consumer := consume.NewTransactionConsumer(kcl, documentClient, processingMetrics, consumerConfig)
+
+	if cfg.Expiry.Enabled {
+		adminClient, ok := elasticClient.(*extern.ElasticClient)
+		if !ok {
+			return errors.Errorf("expiry needs the [%s] sink", sink.Elasticsearch)
+		}
+		job, err := expiry.NewJob(consumerCtx, adminClient, expiry.Config{
+			Targets:          router.Indices,
+			ProtectedIndices: append([]string{cfg.Elastic.IdentityIndexName}, cfg.Expiry.ProtectedIndices...),
+			Interval:         cfg.Expiry.Interval,
+			DryRun:           cfg.Expiry.DryRun,
+		}, processingMetrics)
+		if err != nil {
+			return errors.Wrap(err, "creating expiry job")
+		}
+		go job.Run(consumerCtx)
 	}
-	consumer := consume.NewTransactionConsumer(kcl, elasticClient, processingMetrics, consumerConfig)
 
 	procError := make(chan error, 1)
-	consumerCtx, consumerCtxCancel := context.WithCancel(context.Background())
-	defer consumerCtxCancel()
 	if cfg.Sync.Enabled {
 		go func() {
 			procError <- consumer.Consume(consumerCtx)
 		}()
 	} else {
-		log.Println("[WARN] main: Message consuming disabled")
+		zap.S().Warn("Message consuming disabled.")
 	}
 
 	shutdown := make(chan os.Signal, 1)
@@ -157,27 +298,30 @@ func run() error {
 	// status and metrics endpoint
 	serverError := make(chan error, 1)
 	go func() {
-		log.Printf("main: Starting health and metrics endpoint on port [%d].", cfg.Broker.MetricsPort)
+		zap.S().Infow("Starting health, metrics and log level endpoint.", "port", cfg.Broker.MetricsPort)
 		http.HandleFunc("/health", status.Health)
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		serverError <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.Broker.MetricsPort), nil)
 	}()
 
-	log.Println("main: Service started.")
+	zap.S().Info("Service started.")
 
 	for {
 		select {
 		case <-shutdown:
-			log.Println("main: Received shutdown signal, shutting down...")
+			zap.S().Info("Received shutdown signal, shutting down.")
 			consumerCtxCancel()
 			<-procError // Wait for consumer to stop
+			saveDedupCache()
 			return nil
 		case err := <-procError:
-			return fmt.Errorf("[ERROR] processing error: %v", err)
+			saveDedupCache() // only contains indexed documents
+			return errors.Wrap(err, "processing")
 		case err := <-serverError:
 			consumerCtxCancel()
 			<-procError // Wait for consumer to stop
-			return fmt.Errorf("[ERROR] starting server: %v", err)
+			return errors.Wrap(err, "starting server")
 		}
 	}
 }
@@ -191,7 +335,7 @@ func calculateBackoff() func(i int) time.Duration {
 		} else {
 			d = time.Second*30 + randomMillis()
 		}
-		log.Printf("[WARN] elasticsearch client retry [%d] in %v.", i, d)
+		zap.S().Warnw("Retrying elasticsearch request.", "attempt", i, logging.Duration, d)
 		return d
 	}
 }
@@ -203,6 +347,10 @@ func randomMillis() time.Duration {
 type ElasticClientStub struct {
 }
 
-func (c *ElasticClientStub) BulkIndex(_ context.Context, _ []extern.EsDocument, _ string) error {
+func (c *ElasticClientStub) BulkIndex(_ context.Context, _ []sink.Document, _ string) error {
+	return nil
+}
+
+func (c *ElasticClientStub) BulkUpdate(_ context.Context, _ []sink.Document, _ string) error {
 	return nil
 }
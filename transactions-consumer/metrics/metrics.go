@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -11,6 +13,27 @@ type Metrics struct {
 	processedTickGauge    prometheus.Gauge
 	processedMessageCount prometheus.Counter
 	processedTicksCount   prometheus.Counter
+	routingRuleHits       *prometheus.CounterVec
+	routingReloads        *prometheus.CounterVec
+	incompleteTicksCount  prometheus.Counter
+	pendingTicksGauge     prometheus.Gauge
+	pendingFragmentsGauge prometheus.Gauge
+	expiredPayloadCount   prometheus.Counter
+	identityUpdateCount   prometheus.Counter
+	committedOffsetGauge  *prometheus.GaugeVec
+	highWatermarkGauge    *prometheus.GaugeVec
+	lagGauge              *prometheus.GaugeVec
+	lastRecordTimeGauge   *prometheus.GaugeVec
+	commitBlockedGauge    *prometheus.GaugeVec
+	batchSizeHistogram    prometheus.Histogram
+	bulkIndexDuration     *prometheus.HistogramVec
+	secondsBehindGauge    prometheus.Gauge
+	dedupHitCount         prometheus.Counter
+	dedupMissCount        prometheus.Counter
+	dedupEntriesGauge     prometheus.Gauge
+	expiredDocumentCount  prometheus.Counter
+	expiryCandidatesGauge prometheus.Gauge
+	expiryRunCount        *prometheus.CounterVec
 }
 
 func NewMetrics(namespace string) *Metrics {
@@ -28,6 +51,100 @@ func NewMetrics(namespace string) *Metrics {
 			Name: fmt.Sprintf("%s_processed_message_count", namespace),
 			Help: "The total number of processed message records",
 		}),
+		// metrics per partition
+		committedOffsetGauge: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_partition_committed_offset", namespace),
+			Help: "The committed offset per partition",
+		}, []string{"topic", "partition"}),
+		highWatermarkGauge: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_partition_high_watermark", namespace),
+			Help: "The high watermark per partition of the latest fetch",
+		}, []string{"topic", "partition"}),
+		lagGauge: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_partition_lag", namespace),
+			Help: "The number of records between the committed offset and the high watermark per partition",
+		}, []string{"topic", "partition"}),
+		lastRecordTimeGauge: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_partition_last_record_timestamp_seconds", namespace),
+			Help: "The timestamp of the latest consumed record per partition",
+		}, []string{"topic", "partition"}),
+		commitBlockedGauge: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_partition_commit_blocked_seconds", namespace),
+			Help: "The time since buffered records (incomplete ticks or payloads) prevent committing per partition",
+		}, []string{"topic", "partition"}),
+		// metrics for batches
+		batchSizeHistogram: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%s_batch_size", namespace),
+			Help:    "The number of records per poll",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8), // 1 to 16384
+		}),
+		bulkIndexDuration: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%s_bulk_index_duration_seconds", namespace),
+			Help:    "The duration of bulk index requests per index",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"index"}),
+		secondsBehindGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_seconds_behind", namespace),
+			Help: "The time between now and the timestamp of the latest indexed transaction",
+		}),
+		// metrics for tick completeness
+		incompleteTicksCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_incomplete_tick_count", namespace),
+			Help: "The total number of ticks that were indexed with missing transactions after the completion timeout",
+		}),
+		pendingTicksGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_pending_ticks", namespace),
+			Help: "The number of ticks waiting for further transactions",
+		}),
+		// metrics for fragmented payloads
+		pendingFragmentsGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_pending_fragments", namespace),
+			Help: "The number of buffered fragments of incomplete payloads",
+		}),
+		expiredPayloadCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_expired_payload_count", namespace),
+			Help: "The total number of fragmented payloads that were incomplete after the timeout or released because of the buffer size",
+		}),
+		// metrics for the deduplication cache
+		dedupHitCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_dedup_hit_count", namespace),
+			Help: "The total number of documents that were skipped, because they were indexed recently",
+		}),
+		dedupMissCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_dedup_miss_count", namespace),
+			Help: "The total number of documents that were not found in the deduplication cache",
+		}),
+		dedupEntriesGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_dedup_cache_entries", namespace),
+			Help: "The number of documents in the deduplication cache",
+		}),
+		// metrics for the expiry of ephemeral documents
+		expiredDocumentCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_expiry_deleted_count", namespace),
+			Help: "The total number of deleted expired ephemeral documents",
+		}),
+		expiryCandidatesGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_expiry_candidates", namespace),
+			Help: "The number of expired ephemeral documents of the latest dry run",
+		}),
+		expiryRunCount: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_expiry_run_count", namespace),
+			Help: "The total number of expiry runs by result",
+		}, []string{"result"}),
+		// metrics for the identities index
+		identityUpdateCount: promauto.NewCounter(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_identity_update_count", namespace),
+			Help: "The total number of identity updates sent to the identities index",
+		}),
+		// metrics for index routing
+		routingRuleHits: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_routing_rule_hit_count", namespace),
+			Help: "The total number of transactions routed per rule and index",
+		}, []string{"rule", "index"}),
+		routingReloads: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_routing_reload_count", namespace),
+			Help: "The total number of routing config reloads",
+		}, []string{"result"}),
 	}
 	return &m
 }
@@ -43,3 +160,87 @@ func (metrics *Metrics) IncProcessedTicks() {
 func (metrics *Metrics) IncProcessedMessages() {
 	metrics.processedMessageCount.Inc()
 }
+
+func (metrics *Metrics) IncIncompleteTicks() {
+	metrics.incompleteTicksCount.Inc()
+}
+
+func (metrics *Metrics) SetPendingTicks(count int) {
+	metrics.pendingTicksGauge.Set(float64(count))
+}
+
+func (metrics *Metrics) SetPendingFragments(count int) {
+	metrics.pendingFragmentsGauge.Set(float64(count))
+}
+
+func (metrics *Metrics) IncExpiredPayloads() {
+	metrics.expiredPayloadCount.Inc()
+}
+
+func (metrics *Metrics) AddIdentityUpdates(count int) {
+	metrics.identityUpdateCount.Add(float64(count))
+}
+
+func (metrics *Metrics) AddDedupHits(count int) {
+	metrics.dedupHitCount.Add(float64(count))
+}
+
+func (metrics *Metrics) AddDedupMisses(count int) {
+	metrics.dedupMissCount.Add(float64(count))
+}
+
+func (metrics *Metrics) SetDedupEntries(count int) {
+	metrics.dedupEntriesGauge.Set(float64(count))
+}
+
+func (metrics *Metrics) AddExpiredDocuments(count int64) {
+	metrics.expiredDocumentCount.Add(float64(count))
+}
+
+func (metrics *Metrics) SetExpiryCandidates(count int64) {
+	metrics.expiryCandidatesGauge.Set(float64(count))
+}
+
+func (metrics *Metrics) IncExpiryRuns(result string) {
+	metrics.expiryRunCount.WithLabelValues(result).Inc()
+}
+
+func (metrics *Metrics) SetCommittedOffset(topic string, partition int32, offset int64) {
+	metrics.committedOffsetGauge.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(offset))
+}
+
+func (metrics *Metrics) SetHighWatermark(topic string, partition int32, offset int64) {
+	metrics.highWatermarkGauge.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(offset))
+}
+
+func (metrics *Metrics) SetLag(topic string, partition int32, lag int64) {
+	metrics.lagGauge.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+func (metrics *Metrics) SetLastRecordTimestamp(topic string, partition int32, timestamp time.Time) {
+	metrics.lastRecordTimeGauge.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(timestamp.Unix()))
+}
+
+func (metrics *Metrics) SetCommitBlocked(topic string, partition int32, duration time.Duration) {
+	metrics.commitBlockedGauge.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(duration.Seconds())
+}
+
+func (metrics *Metrics) ObserveBatchSize(size int) {
+	metrics.batchSizeHistogram.Observe(float64(size))
+}
+
+func (metrics *Metrics) ObserveBulkIndexDuration(index string, duration time.Duration) {
+	metrics.bulkIndexDuration.WithLabelValues(index).Observe(duration.Seconds())
+}
+
+func (metrics *Metrics) SetSecondsBehind(duration time.Duration) {
+	metrics.secondsBehindGauge.Set(duration.Seconds())
+}
+
+func (metrics *Metrics) IncRoutingRuleHits(rule, index string) {
+	metrics.routingRuleHits.WithLabelValues(rule, index).Inc()
+}
+
+func (metrics *Metrics) IncRoutingReloads(result string) {
+	metrics.routingReloads.WithLabelValues(result).Inc()
+}
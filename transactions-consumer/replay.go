@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ardanlabs/conf"
+	"github.com/pkg/errors"
+	"github.com/qubic/replay"
+	"github.com/qubic/sink"
+	"github.com/qubic/transactions-consumer/consume"
+	"github.com/qubic/transactions-consumer/metrics"
+	"go.uber.org/zap"
+)
+
+type replayOptions struct {
+	replay.Options
+	replay.TickRangeOptions
+	TargetIndex string `conf:"optional"` // index or alias for all documents. Default is the routing.
+}
+
+type replayCommand struct {
+	config        replay.Config
+	brokers       []string
+	targetIndex   string
+	dryRun        bool
+	elasticClient interface {
+		consume.ElasticDocumentClient
+		consume.IdentityUpdateClient
+	}
+	consumerConfig consume.ConsumerConfig
+	metrics        *metrics.Metrics
+}
+
+// runReplayCommand re-indexes a range of the topic. The live consumer group is not used, so the service can keep
+// running during the replay.
+func runReplayCommand(ctx context.Context, args conf.Args, cmd replayCommand) error {
+	if args.Num(0) != "replay" {
+		return fmt.Errorf("unknown command [%s]\n%s", args.Num(0), replay.Usage)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := replay.NewClient(ctx, cmd.brokers, cmd.config)
+	if err != nil {
+		return errors.Wrap(err, "creating replay client")
+	}
+	defer client.Close()
+
+	consumerConfig := cmd.consumerConfig
+	if cmd.targetIndex != "" {
+		consumerConfig.Router, err = consume.NewRouter(consume.RoutingConfig{Default: consume.RoutingTarget{Index: cmd.targetIndex}}, cmd.metrics)
+		if err != nil {
+			return errors.Wrap(err, "creating replay router")
+		}
+	}
+	elasticClient := cmd.elasticClient
+	var counter *countingClient
+	if cmd.dryRun {
+		counter = newCountingClient()
+		elasticClient = counter
+	}
+	if consumerConfig.IdentityClient != nil {
+		consumerConfig.IdentityClient = elasticClient
+	}
+
+	consumer := consume.NewTransactionConsumer(client, elasticClient, cmd.metrics, &consumerConfig)
+	count, err := consumer.Replay(ctx, client.Done)
+	if err != nil {
+		return errors.Wrapf(err, "replaying after [%d] documents", count)
+	}
+	zap.S().Infow("Replay finished.", "documents", count)
+	if counter != nil {
+		counter.documents.Print(os.Stdout, "documents")
+		counter.updates.Print(os.Stdout, "updates")
+	}
+	return nil
+}
+
+// countingClient replaces the elastic client for dry runs. It only counts the documents per target index.
+type countingClient struct {
+	documents *replay.Counter
+	updates   *replay.Counter
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{documents: replay.NewCounter(), updates: replay.NewCounter()}
+}
+
+func (c *countingClient) BulkIndex(_ context.Context, data []sink.Document, indexName string) error {
+	c.documents.Add(indexName, len(data))
+	return nil
+}
+
+func (c *countingClient) BulkUpdate(_ context.Context, data []sink.Document, indexName string) error {
+	c.updates.Add(indexName, len(data))
+	return nil
+}
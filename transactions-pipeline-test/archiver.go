@@ -0,0 +1,118 @@
+package pipelinetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	archiverproto "github.com/qubic/go-archiver-v2/protobuf"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ArchiverFixture contains captured archiver responses. Ticks without entry have no transactions.
+type ArchiverFixture struct {
+	Status *archiverproto.GetStatusResponse
+	Ticks  map[uint32]*archiverproto.GetTickTransactionsResponseV2
+}
+
+type archiverFixtureFile struct {
+	Status json.RawMessage            `json:"status"`
+	Ticks  map[string]json.RawMessage `json:"ticks"`
+}
+
+// LoadArchiverFixture reads a fixture file with protojson encoded GetStatus and GetTickTransactionsV2 responses.
+func LoadArchiverFixture(path string) (*ArchiverFixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture file: %w", err)
+	}
+
+	var file archiverFixtureFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling fixture file: %w", err)
+	}
+
+	fixture := &ArchiverFixture{
+		Status: &archiverproto.GetStatusResponse{},
+		Ticks:  make(map[uint32]*archiverproto.GetTickTransactionsResponseV2, len(file.Ticks)),
+	}
+	err = protojson.Unmarshal(file.Status, fixture.Status)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling status: %w", err)
+	}
+	for key, value := range file.Ticks {
+		tick, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parsing tick number [%s]: %w", key, err)
+		}
+		response := &archiverproto.GetTickTransactionsResponseV2{}
+		err = protojson.Unmarshal(value, response)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshalling transactions of tick [%d]: %w", tick, err)
+		}
+		fixture.Ticks[uint32(tick)] = response
+	}
+	return fixture, nil
+}
+
+// Transactions returns all archived transactions.
+func (f *ArchiverFixture) Transactions() []*archiverproto.Transaction {
+	var transactions []*archiverproto.Transaction
+	for _, response := range f.Ticks {
+		for _, transactionData := range response.Transactions {
+			transactions = append(transactions, transactionData.Transaction)
+		}
+	}
+	return transactions
+}
+
+// ArchiverReplay is a local archiver that answers with the fixture responses.
+type ArchiverReplay struct {
+	archiverproto.UnimplementedArchiveServiceServer
+	fixture *ArchiverFixture
+	server  *grpc.Server
+	addr    string
+}
+
+func StartArchiverReplay(fixture *ArchiverFixture) (*ArchiverReplay, error) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, fmt.Errorf("listening: %w", err)
+	}
+
+	replay := &ArchiverReplay{
+		fixture: fixture,
+		server:  grpc.NewServer(),
+		addr:    lis.Addr().String(),
+	}
+	archiverproto.RegisterArchiveServiceServer(replay.server, replay)
+	go func() {
+		_ = replay.server.Serve(lis)
+	}()
+	return replay, nil
+}
+
+func (r *ArchiverReplay) Addr() string {
+	return r.addr
+}
+
+func (r *ArchiverReplay) Stop() {
+	r.server.Stop()
+}
+
+func (r *ArchiverReplay) GetStatus(_ context.Context, _ *emptypb.Empty) (*archiverproto.GetStatusResponse, error) {
+	return r.fixture.Status, nil
+}
+
+func (r *ArchiverReplay) GetTickTransactionsV2(_ context.Context, req *archiverproto.GetTickTransactionsRequestV2) (*archiverproto.GetTickTransactionsResponseV2, error) {
+	if response, ok := r.fixture.Ticks[req.TickNumber]; ok {
+		return response, nil
+	}
+	return &archiverproto.GetTickTransactionsResponseV2{}, nil
+}
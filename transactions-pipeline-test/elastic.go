@@ -0,0 +1,158 @@
+package pipelinetest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// ElasticStub is a fake elasticsearch bulk endpoint. It records all bulk requests and keeps the latest version of
+// every document per index.
+type ElasticStub struct {
+	server     *httptest.Server
+	lock       sync.Mutex
+	requests   int
+	operations map[string]int                        // index operations per index
+	documents  map[string]map[string]json.RawMessage // index -> document id -> document
+	failNext   int                                   // number of bulk requests to fail
+}
+
+func StartElasticStub() *ElasticStub {
+	stub := &ElasticStub{
+		operations: make(map[string]int),
+		documents:  make(map[string]map[string]json.RawMessage),
+	}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.handle))
+	return stub
+}
+
+func (s *ElasticStub) URL() string {
+	return s.server.URL
+}
+
+func (s *ElasticStub) Close() {
+	s.server.Close()
+}
+
+// Documents returns a copy of the documents of the index.
+func (s *ElasticStub) Documents(index string) map[string]json.RawMessage {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	documents := make(map[string]json.RawMessage, len(s.documents[index]))
+	for id, document := range s.documents[index] {
+		documents[id] = document
+	}
+	return documents
+}
+
+// DocumentCount returns the number of documents over all indices.
+func (s *ElasticStub) DocumentCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	count := 0
+	for _, documents := range s.documents {
+		count += len(documents)
+	}
+	return count
+}
+
+// Operations returns the number of index operations for the index, including re-indexed documents.
+func (s *ElasticStub) Operations(index string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.operations[index]
+}
+
+// FailNextRequests lets the next bulk requests fail with a server error.
+func (s *ElasticStub) FailNextRequests(count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failNext = count
+}
+
+func (s *ElasticStub) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch") // needed for the product check of the client
+	w.Header().Set("Content-Type", "application/json")
+
+	if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+		_, _ = w.Write([]byte(`{"version":{"number":"8.19.0"}}`))
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests++
+	if s.failNext > 0 {
+		s.failNext--
+		http.Error(w, `{"error":"injected failure"}`, http.StatusInternalServerError)
+		return
+	}
+
+	defaultIndex := strings.Trim(strings.TrimSuffix(r.URL.Path, "/_bulk"), "/")
+	items, err := s.applyBulk(r.Body, defaultIndex)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	response, _ := json.Marshal(map[string]any{"took": 1, "errors": false, "items": items})
+	_, _ = w.Write(response)
+}
+
+type bulkMeta struct {
+	Index string `json:"_index"`
+	Id    string `json:"_id"`
+}
+
+func (s *ElasticStub) applyBulk(body io.Reader, defaultIndex string) ([]map[string]any, error) {
+	var items []map[string]any
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var action map[string]bulkMeta
+		err := json.Unmarshal(line, &action)
+		if err != nil || len(action) != 1 {
+			return nil, fmt.Errorf("invalid action line: %s", line)
+		}
+		meta, ok := action["index"]
+		if !ok {
+			return nil, fmt.Errorf("unsupported action: %s", line)
+		}
+		if meta.Index == "" {
+			meta.Index = defaultIndex
+		}
+
+		if !scanner.Scan() {
+			return nil, fmt.Errorf("missing document for [%s]", meta.Id)
+		}
+		document := json.RawMessage(bytes.Clone(scanner.Bytes()))
+		if !json.Valid(document) {
+			return nil, fmt.Errorf("invalid document for [%s]", meta.Id)
+		}
+
+		if s.documents[meta.Index] == nil {
+			s.documents[meta.Index] = make(map[string]json.RawMessage)
+		}
+		result, status := "created", http.StatusCreated
+		if _, exists := s.documents[meta.Index][meta.Id]; exists {
+			result, status = "updated", http.StatusOK
+		}
+		s.documents[meta.Index][meta.Id] = document
+		s.operations[meta.Index]++
+		items = append(items, map[string]any{
+			"index": map[string]any{"_index": meta.Index, "_id": meta.Id, "result": result, "status": status},
+		})
+	}
+	return items, scanner.Err()
+}
@@ -0,0 +1,258 @@
+package pipelinetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/qubic/replay"
+	"github.com/qubic/sink"
+	"github.com/qubic/transactions-consumer/consume"
+	"github.com/qubic/transactions-consumer/extern"
+	consumermetrics "github.com/qubic/transactions-consumer/metrics"
+	"github.com/qubic/transactions-producer/domain"
+	"github.com/qubic/transactions-producer/entities"
+	"github.com/qubic/transactions-producer/external/archiver"
+	"github.com/qubic/transactions-producer/external/kafka"
+	"github.com/qubic/transactions-producer/infrastructure/store/pebbledb"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+const (
+	topic              = "qubic-transactions"
+	consumerGroup      = "qubic-elastic"
+	PermanentIndexName = "qubic-transactions"
+	EphemeralIndexName = "qubic-eph-transactions"
+)
+
+// ErrInjectedCrash marks errors caused by crash injection.
+var ErrInjectedCrash = errors.New("injected crash")
+
+// metrics can only be registered once per process
+var (
+	producerMetrics = sync.OnceValue(func() *domain.Metrics { return domain.NewMetrics("pipeline_producer") })
+	consumerMetrics = sync.OnceValue(func() *consumermetrics.Metrics { return consumermetrics.NewMetrics("pipeline_consumer") })
+)
+
+type Config struct {
+	Partitions          int32
+	ProducerWorkers     int
+	ConsumerPollRecords int
+	EphemeralInputTypes []uint32
+}
+
+// Pipeline wires the transactions producer and consumer with a replayed archiver, an in-memory kafka cluster and
+// an elastic stub.
+type Pipeline struct {
+	config   Config
+	Archiver *ArchiverReplay
+	Kafka    *kfake.Cluster
+	Elastic  *ElasticStub
+	storeDir string
+	Store    *pebbledb.Store
+	logger   *zap.SugaredLogger
+}
+
+func NewPipeline(fixture *ArchiverFixture, config Config) (*Pipeline, error) {
+	// stops the started components in reverse order, if a later step fails
+	var cleanup []func()
+	failed := func(err error) (*Pipeline, error) {
+		for i := len(cleanup) - 1; i >= 0; i-- {
+			cleanup[i]()
+		}
+		return nil, err
+	}
+
+	archiverReplay, err := StartArchiverReplay(fixture)
+	if err != nil {
+		return failed(fmt.Errorf("starting archiver replay: %w", err))
+	}
+	cleanup = append(cleanup, archiverReplay.Stop)
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(config.Partitions, topic))
+	if err != nil {
+		return failed(fmt.Errorf("creating kafka cluster: %w", err))
+	}
+	cleanup = append(cleanup, cluster.Close)
+
+	storeDir, err := os.MkdirTemp("", "pipeline_test")
+	if err != nil {
+		return failed(fmt.Errorf("creating store folder: %w", err))
+	}
+	cleanup = append(cleanup, func() { _ = os.RemoveAll(storeDir) })
+
+	store, err := pebbledb.NewProcessorStore(storeDir)
+	if err != nil {
+		return failed(fmt.Errorf("creating processor store: %w", err))
+	}
+	cleanup = append(cleanup, func() { _ = store.Close() })
+
+	err = store.SetLastProcessedTick(0)
+	if err != nil {
+		return failed(fmt.Errorf("initializing last processed tick: %w", err))
+	}
+
+	return &Pipeline{
+		config:   config,
+		Archiver: archiverReplay,
+		Kafka:    cluster,
+		Elastic:  StartElasticStub(),
+		storeDir: storeDir,
+		Store:    store,
+		logger:   zap.NewNop().Sugar(),
+	}, nil
+}
+
+// Close stops all components. Producers need to be stopped before.
+func (p *Pipeline) Close() {
+	_ = p.Store.Close()
+	_ = os.RemoveAll(p.storeDir)
+	p.Elastic.Close()
+	p.Kafka.Close()
+	p.Archiver.Stop()
+}
+
+// RunProducer runs a producer instance until it crashes at the crash tick or the context is canceled. A crash
+// happens after publishing the crash tick but before storing the checkpoint. Zero disables crashing.
+func (p *Pipeline) RunProducer(ctx context.Context, crashTick uint32) error {
+	kcl, err := kgo.NewClient(
+		kgo.SeedBrokers(p.Kafka.ListenAddrs()...),
+		kgo.DefaultProduceTopic(topic),
+	)
+	if err != nil {
+		return fmt.Errorf("creating kafka client: %w", err)
+	}
+	defer kcl.Close()
+
+	archiverClient, err := archiver.NewClient(p.Archiver.Addr(), 16*1024*1024)
+	if err != nil {
+		return fmt.Errorf("creating archiver client: %w", err)
+	}
+
+	runCtx, crash := context.WithCancel(ctx)
+	defer crash()
+	publisher := &crashingPublisher{
+		delegate:  kafka.NewClient(kcl, kafka.OversizeConfig{}),
+		crashTick: crashTick,
+		crash:     crash,
+	}
+	proc := domain.NewProcessor(archiverClient, time.Second, publisher, p.Store, p.config.ProducerWorkers, p.logger, producerMetrics())
+	source := archiver.NewStatusFeed(nil, archiver.NewAdaptivePoller(archiverClient, time.Second, 10*time.Millisecond, 50*time.Millisecond), time.Second)
+
+	err = proc.Start(runCtx, source)
+	if err != nil {
+		return err
+	}
+	if publisher.crashed.Load() {
+		return fmt.Errorf("%w at tick [%d]", ErrInjectedCrash, crashTick)
+	}
+	return nil
+}
+
+// RunConsumer runs a consumer instance until it crashes after the given number of bulk index requests or the
+// context is canceled. A crash happens after indexing but before committing offsets. Zero disables crashing.
+func (p *Pipeline) RunConsumer(ctx context.Context, crashAfterBulkRequests int) error {
+	kcl, err := kgo.NewClient(
+		kgo.SeedBrokers(p.Kafka.ListenAddrs()...),
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumerGroup(consumerGroup),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.DisableAutoCommit(),
+	)
+	if err != nil {
+		return fmt.Errorf("creating kafka client: %w", err)
+	}
+	defer kcl.Close()
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{p.Elastic.URL()}})
+	if err != nil {
+		return fmt.Errorf("creating elastic client: %w", err)
+	}
+
+	elasticClient := &crashingElasticClient{
+		delegate:   extern.NewElasticClient(esClient),
+		crashAfter: crashAfterBulkRequests,
+	}
+	router, err := consume.NewRouter(consume.DefaultRoutingConfig(PermanentIndexName, EphemeralIndexName, p.config.EphemeralInputTypes, 0), consumerMetrics())
+	if err != nil {
+		return fmt.Errorf("creating router: %w", err)
+	}
+	consumer := consume.NewTransactionConsumer(kcl, elasticClient, consumerMetrics(), &consume.ConsumerConfig{
+		MaxPollRecords: p.config.ConsumerPollRecords,
+		Router:         router,
+	})
+	return consumer.Consume(ctx)
+}
+
+// RunReplay replays the topic into the target index with the replay tool of the consumer. Returns the number of
+// indexed documents.
+func (p *Pipeline) RunReplay(ctx context.Context, config replay.Config, targetIndex string) (int, error) {
+	config.Topic = topic
+	client, err := replay.NewClient(ctx, p.Kafka.ListenAddrs(), config)
+	if err != nil {
+		return 0, fmt.Errorf("creating replay client: %w", err)
+	}
+	defer client.Close()
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{p.Elastic.URL()}})
+	if err != nil {
+		return 0, fmt.Errorf("creating elastic client: %w", err)
+	}
+	router, err := consume.NewRouter(consume.RoutingConfig{Default: consume.RoutingTarget{Index: targetIndex}}, consumerMetrics())
+	if err != nil {
+		return 0, fmt.Errorf("creating router: %w", err)
+	}
+	consumer := consume.NewTransactionConsumer(client, extern.NewElasticClient(esClient), consumerMetrics(), &consume.ConsumerConfig{
+		MaxPollRecords: p.config.ConsumerPollRecords,
+		Router:         router,
+	})
+	return consumer.Replay(ctx, client.Done)
+}
+
+// crashingPublisher stops the processor after publishing the crash tick. The publish call fails, so that the
+// processor does not store the checkpoint of the crash tick.
+type crashingPublisher struct {
+	delegate  domain.Publisher
+	crashTick uint32
+	crash     context.CancelFunc
+	crashed   atomic.Bool
+}
+
+func (c *crashingPublisher) PublishTickTransactions(transactions []entities.Transaction) error {
+	err := c.delegate.PublishTickTransactions(transactions)
+	if err != nil {
+		return err
+	}
+	if c.crashTick > 0 && transactions[0].TickNumber == c.crashTick {
+		c.crashed.Store(true)
+		c.crash()
+		return fmt.Errorf("%w at tick [%d]", ErrInjectedCrash, c.crashTick)
+	}
+	return nil
+}
+
+// crashingElasticClient fails after the given number of successful bulk requests.
+type crashingElasticClient struct {
+	delegate   consume.ElasticDocumentClient
+	crashAfter int
+	requests   int
+}
+
+func (c *crashingElasticClient) BulkIndex(ctx context.Context, data []sink.Document, indexName string) error {
+	err := c.delegate.BulkIndex(ctx, data, indexName)
+	if err != nil {
+		return err
+	}
+	c.requests++
+	if c.crashAfter > 0 && c.requests >= c.crashAfter {
+		return fmt.Errorf("%w after [%d] bulk requests", ErrInjectedCrash, c.requests)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package pipelinetest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	archiverproto "github.com/qubic/go-archiver-v2/protobuf"
+	"github.com/qubic/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const zeroAddress = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB"
+
+var ephemeralInputTypes = []uint32{6}
+
+type crashPoints struct {
+	producerTicks        []uint32 // crash after publishing these non-empty ticks, one per producer run
+	consumerBulkRequests []int    // crash after these numbers of bulk requests, one per consumer run
+}
+
+func TestPipeline_ReplayWithoutCrashes(t *testing.T) {
+	runPipeline(t, crashPoints{})
+}
+
+func TestPipeline_ProducerCrashesBeforeCheckpoint(t *testing.T) {
+	runPipeline(t, crashPoints{producerTicks: []uint32{20000003, 20000014, 20100003}})
+}
+
+func TestPipeline_ConsumerCrashesBeforeCommit(t *testing.T) {
+	runPipeline(t, crashPoints{consumerBulkRequests: []int{1, 3, 2}})
+}
+
+func TestPipeline_ProducerAndConsumerCrash(t *testing.T) {
+	runPipeline(t, crashPoints{
+		producerTicks:        []uint32{20000009, 20100011},
+		consumerBulkRequests: []int{2, 1},
+	})
+}
+
+func runPipeline(t *testing.T, crashes crashPoints) {
+	fixture, err := LoadArchiverFixture("testdata/archiver-fixture.json")
+	require.NoError(t, err)
+
+	pipeline, err := NewPipeline(fixture, Config{
+		Partitions:          3,
+		ProducerWorkers:     4,
+		ConsumerPollRecords: 7, // small batches, so that ticks straddle polls
+		EphemeralInputTypes: ephemeralInputTypes,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	producerDone := make(chan struct{})
+	go func() {
+		defer close(producerDone)
+		for _, tick := range crashes.producerTicks {
+			err := pipeline.RunProducer(ctx, tick)
+			assert.ErrorIs(t, err, ErrInjectedCrash)
+		}
+		assert.NoError(t, pipeline.RunProducer(ctx, 0))
+	}()
+
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		for _, requests := range crashes.consumerBulkRequests {
+			err := pipeline.RunConsumer(ctx, requests)
+			assert.ErrorIs(t, err, ErrInjectedCrash)
+		}
+		assert.NoError(t, pipeline.RunConsumer(ctx, 0))
+	}()
+
+	transactions := fixture.Transactions()
+	lastTick := fixture.Status.LastProcessedTick.TickNumber
+	assert.Eventually(t, func() bool {
+		tick, err := pipeline.Store.GetLastProcessedTick()
+		return err == nil && tick == lastTick && pipeline.Elastic.DocumentCount() >= len(transactions)
+	}, 30*time.Second, 50*time.Millisecond)
+
+	cancel()
+	<-producerDone
+	<-consumerDone
+	pipeline.Close()
+
+	assertIndexedExactlyOnce(t, pipeline.Elastic, fixture)
+	t.Logf("indexed [%d] transactions with [%d] index operations.", len(transactions),
+		pipeline.Elastic.Operations(PermanentIndexName)+pipeline.Elastic.Operations(EphemeralIndexName))
+}
+
+// assertIndexedExactlyOnce asserts that every archived transaction is indexed once with the expected content in the
+// expected index and that there are no further documents.
+func assertIndexedExactlyOnce(t *testing.T, elastic *ElasticStub, fixture *ArchiverFixture) {
+	expected := expectedDocuments(t, fixture)
+	permanent := elastic.Documents(PermanentIndexName)
+	ephemeral := elastic.Documents(EphemeralIndexName)
+	require.Equal(t, len(expected), len(permanent)+len(ephemeral), "unexpected documents")
+	require.Equal(t, len(expected), elastic.DocumentCount(), "unexpected documents in other indices")
+
+	for _, transaction := range fixture.Transactions() {
+		expectedIndex, otherIndex := permanent, ephemeral
+		if isEphemeral(transaction) {
+			expectedIndex, otherIndex = ephemeral, permanent
+		}
+
+		document, ok := expectedIndex[transaction.TxId]
+		if !assert.True(t, ok, "transaction [%s] missing in expected index", transaction.TxId) {
+			continue
+		}
+		assert.NotContains(t, otherIndex, transaction.TxId)
+		assert.JSONEq(t, expected[transaction.TxId], string(document), "unexpected content of transaction [%s]", transaction.TxId)
+	}
+}
+
+// expectedDocuments returns the expected document per transaction hash. The fixture must not contain duplicates.
+func expectedDocuments(t *testing.T, fixture *ArchiverFixture) map[string]string {
+	documents := make(map[string]string)
+	for _, response := range fixture.Ticks {
+		for _, transactionData := range response.Transactions {
+			transaction := transactionData.Transaction
+			input, err := hex.DecodeString(transaction.InputHex)
+			require.NoError(t, err)
+			signature, err := hex.DecodeString(transaction.SignatureHex)
+			require.NoError(t, err)
+
+			document, err := json.Marshal(map[string]any{
+				"hash":        transaction.TxId,
+				"source":      transaction.SourceId,
+				"destination": transaction.DestId,
+				"amount":      transaction.Amount,
+				"tickNumber":  transaction.TickNumber,
+				"inputType":   transaction.InputType,
+				"inputSize":   transaction.InputSize,
+				"inputData":   base64.StdEncoding.EncodeToString(input),
+				"signature":   base64.StdEncoding.EncodeToString(signature),
+				"timestamp":   transactionData.Timestamp,
+				"moneyFlew":   transactionData.MoneyFlew,
+			})
+			require.NoError(t, err)
+			require.NotContains(t, documents, transaction.TxId, "duplicate transaction in fixture")
+			documents[transaction.TxId] = string(document)
+		}
+	}
+	return documents
+}
+
+func isEphemeral(transaction *archiverproto.Transaction) bool {
+	return transaction.InputType == ephemeralInputTypes[0] && transaction.DestId == zeroAddress && transaction.Amount == 0
+}
+
+func TestPipeline_ReplayTickRangeIntoTargetIndex(t *testing.T) {
+	fixture, err := LoadArchiverFixture("testdata/archiver-fixture.json")
+	require.NoError(t, err)
+
+	pipeline, err := NewPipeline(fixture, Config{
+		Partitions:          3,
+		ProducerWorkers:     4,
+		ConsumerPollRecords: 7,
+		EphemeralInputTypes: ephemeralInputTypes,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	producerDone := make(chan struct{})
+	go func() {
+		defer close(producerDone)
+		assert.NoError(t, pipeline.RunProducer(ctx, 0))
+	}()
+	lastTick := fixture.Status.LastProcessedTick.TickNumber
+	require.Eventually(t, func() bool {
+		tick, err := pipeline.Store.GetLastProcessedTick()
+		return err == nil && tick == lastTick
+	}, 30*time.Second, 50*time.Millisecond)
+	cancel()
+	<-producerDone
+	defer pipeline.Close()
+
+	const fromTick, toTick, targetIndex = 20000005, 20100005, "qubic-transactions-rebuild"
+	count, err := pipeline.RunReplay(t.Context(), replay.Config{FromTick: fromTick, ToTick: toTick}, targetIndex)
+	require.NoError(t, err)
+
+	expected := make(map[string]bool)
+	for _, transaction := range fixture.Transactions() {
+		if transaction.TickNumber >= fromTick && transaction.TickNumber <= toTick {
+			expected[transaction.TxId] = true
+		}
+	}
+	require.NotEmpty(t, expected)
+	assert.Equal(t, len(expected), count)
+
+	documents := pipeline.Elastic.Documents(targetIndex)
+	assert.Len(t, documents, len(expected))
+	for hash := range documents {
+		assert.True(t, expected[hash], "unexpected transaction [%s]", hash)
+	}
+	assert.Empty(t, pipeline.Elastic.Documents(PermanentIndexName), "live indices must not be touched")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ardanlabs/conf"
+	"github.com/qubic/transactions-producer/domain"
+	"github.com/qubic/transactions-producer/entities"
+	"github.com/qubic/transactions-producer/infrastructure/store/pebbledb"
+)
+
+const checkpointsUsage = `usage:
+  checkpoints list                  print the checkpoint history
+  checkpoints export [file]         export the checkpoint history as json lines (default: stdout)
+  checkpoints rewind tick <tick>    reset the last processed tick to a previous tick
+  checkpoints rewind epoch <epoch>  reset the last processed tick to the start of a previous epoch`
+
+// runCheckpointsCommand executes the checkpoint subcommands. The service must not be running, as the store can only
+// be opened by one process.
+func runCheckpointsCommand(args conf.Args, store *pebbledb.Store) error {
+	if args.Num(0) != "checkpoints" {
+		return fmt.Errorf("unknown command [%s]\n%s", args.Num(0), checkpointsUsage)
+	}
+
+	history, err := store.GetCheckpointHistory()
+	if err != nil {
+		return fmt.Errorf("getting checkpoint history: %v", err)
+	}
+
+	switch args.Num(1) {
+	case "list":
+		return printCheckpoints(os.Stdout, history)
+	case "export":
+		if args.Num(2) == "" {
+			return exportCheckpoints(os.Stdout, history)
+		}
+		file, err := os.Create(args.Num(2))
+		if err != nil {
+			return fmt.Errorf("creating export file: %v", err)
+		}
+		defer file.Close()
+		return exportCheckpoints(file, history)
+	case "rewind":
+		value, err := strconv.ParseUint(args.Num(3), 10, 32)
+		if err != nil {
+			return fmt.Errorf("parsing rewind target [%s]: %v", args.Num(3), err)
+		}
+
+		var checkpoint entities.Checkpoint
+		switch args.Num(2) {
+		case "tick":
+			checkpoint, err = domain.RewindToTick(history, uint32(value))
+		case "epoch":
+			checkpoint, err = domain.RewindToEpochStart(history, uint32(value))
+		default:
+			return fmt.Errorf("unknown rewind target [%s]\n%s", args.Num(2), checkpointsUsage)
+		}
+		if err != nil {
+			return fmt.Errorf("calculating rewind checkpoint: %v", err)
+		}
+
+		err = store.SaveCheckpoint(checkpoint)
+		if err != nil {
+			return fmt.Errorf("saving rewind checkpoint: %v", err)
+		}
+		fmt.Printf("rewound last processed tick to [%d] (epoch [%d]).\n", checkpoint.Tick, checkpoint.Epoch)
+		return nil
+	default:
+		return fmt.Errorf("unknown command [%s]\n%s", args.Num(1), checkpointsUsage)
+	}
+}
+
+func printCheckpoints(w io.Writer, history []entities.Checkpoint) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIMESTAMP\tEPOCH\tSTART TICK\tTICK\tTRANSACTIONS\tREWIND\tREASON")
+	for _, checkpoint := range history {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%t\t%s\n", checkpoint.Timestamp.Format(time.DateTime), checkpoint.Epoch,
+			checkpoint.StartTick, checkpoint.Tick, checkpoint.TransactionCount, checkpoint.Rewind, checkpoint.Reason)
+	}
+	return tw.Flush()
+}
+
+func exportCheckpoints(w io.Writer, history []entities.Checkpoint) error {
+	encoder := json.NewEncoder(w)
+	for _, checkpoint := range history {
+		err := encoder.Encode(checkpoint)
+		if err != nil {
+			return fmt.Errorf("encoding checkpoint: %v", err)
+		}
+	}
+	return nil
+}
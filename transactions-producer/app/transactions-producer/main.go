@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -14,54 +15,64 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qubic/logging"
 	"github.com/qubic/transactions-producer/domain"
 	"github.com/qubic/transactions-producer/entities"
 	"github.com/qubic/transactions-producer/external/archiver"
 	"github.com/qubic/transactions-producer/external/kafka"
+	"github.com/qubic/transactions-producer/external/qubic"
+	"github.com/qubic/transactions-producer/infrastructure/store/filestore"
 	"github.com/qubic/transactions-producer/infrastructure/store/pebbledb"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/plugin/kprom"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 const prefix = "QUBIC_TRANSACTIONS_PUBLISHER"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatalf("main: exited with error: %s", err.Error())
-	}
-}
-
-func run() error {
-	config := zap.NewProductionConfig()
-	config.Encoding = "console"
-	// this is just for sugar, to display a readable date instead of an epoch time
-	config.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(time.DateTime)
-
-	logger, err := config.Build()
+	logger, logLevel, err := logging.New("transactions-producer", "info")
 	if err != nil {
-		return fmt.Errorf("creating logger: %v", err)
+		log.Fatalf("main: creating logger: %v", err)
 	}
-	defer logger.Sync()
-	sLogger := logger.Sugar()
+	if err := run(logger, logLevel); err != nil {
+		logger.Fatalw("Exited with error.", logging.Error, err)
+	}
+	_ = logger.Sync()
+}
 
+func run(sLogger *zap.SugaredLogger, logLevel zap.AtomicLevel) error {
 	var cfg struct {
 		InternalStoreFolder            string        `conf:"default:store"`
 		ArchiverGrpcHost               string        `conf:"default:127.0.0.1:6001"`
 		ArchiverReadTimeout            time.Duration `conf:"default:30s"`
+		ArchiverStatusStream           bool          `conf:"default:true"`
+		ArchiverStreamIdleTimeout      time.Duration `conf:"default:1m"`
+		ArchiverPollMinInterval        time.Duration `conf:"default:250ms"`
+		ArchiverPollMaxInterval        time.Duration `conf:"default:5s"`
 		NrWorkers                      int           `conf:"default:10"`
 		PublishCustomTicks             []uint32      `conf:"optional"`
 		OverrideLastProcessedTick      bool          `conf:"default:false"`
 		OverrideLastProcessedTickValue uint32        `conf:"default:0"`
 		MaxRecvSizeInMb                int           `conf:"default:20"`
+		BlobStoreFolder                string        `conf:"default:blobs"`
 		Kafka                          struct {
 			BootstrapServers []string `conf:"default:localhost:9092"`
 			TxTopic          string   `conf:"default:qubic-transactions-local"`
 			MaxMessageSizeMB int      `conf:"default:1"`
+			OversizeMode     string   `conf:"default:chunk"` // chunk or offload
+			QuarantineTopic  string   `conf:"default:qubic-transactions-quarantine-local"`
+		}
+		Verification struct {
+			Enabled      bool   `conf:"default:false"`
+			MismatchMode string `conf:"default:halt"` // halt, skip or quarantine
 		}
 		MetricsNamespace string `conf:"default:qubic_kafka"`
 		MetricsPort      int    `conf:"default:9999"`
+		Log              struct {
+			Level string `conf:"default:info"` // debug, info, warn or error. Can be changed at runtime.
+		}
+		Args conf.Args
 	}
 
 	if err := conf.Parse(os.Args[1:], prefix, &cfg); err != nil {
@@ -88,27 +99,37 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("generating config for output: %v", err)
 	}
-	log.Printf("main: Config :\n%v\n", out)
+	sLogger.Infow("Config.", "config", out)
+	err = logLevel.UnmarshalText([]byte(cfg.Log.Level))
+	if err != nil {
+		return fmt.Errorf("setting log level: %v", err)
+	}
 
 	procStore, err := pebbledb.NewProcessorStore(cfg.InternalStoreFolder)
 	if err != nil {
 		return fmt.Errorf("creating processor store: %v", err)
 	}
 
+	if len(cfg.Args) > 0 {
+		defer procStore.Close()
+		return runCheckpointsCommand(cfg.Args, procStore)
+	}
+
 	lpt, err := procStore.GetLastProcessedTick()
 	if cfg.OverrideLastProcessedTick {
-		log.Printf("main: overriding last processed tick with [%d].", cfg.OverrideLastProcessedTickValue)
-		if err := procStore.SetLastProcessedTick(cfg.OverrideLastProcessedTickValue); err != nil {
-			return fmt.Errorf("setting last processed tick: %v", err)
+		sLogger.Infow("Overriding last processed tick.", logging.Tick, cfg.OverrideLastProcessedTickValue)
+		err = procStore.SaveCheckpoint(domain.ResetToTick(cfg.OverrideLastProcessedTickValue, entities.ReasonOverride))
+		if err != nil {
+			return fmt.Errorf("saving override checkpoint: %v", err)
 		}
 	} else if err == entities.ErrStoreEntityNotFound {
-		log.Println("main: initializing last processed tick.")
-		err = procStore.SetLastProcessedTick(0)
+		sLogger.Info("Initializing last processed tick.")
+		err = procStore.SaveCheckpoint(domain.ResetToTick(0, entities.ReasonInit))
 		if err != nil {
-			return fmt.Errorf("setting last processed tick: %v", err)
+			return fmt.Errorf("saving initial checkpoint: %v", err)
 		}
 	} else {
-		log.Printf("main: resuming from  last processed tick [%d].", lpt)
+		sLogger.Infow("Resuming from last processed tick.", logging.Tick, lpt)
 	}
 
 	kafkaMetrics := kprom.NewMetrics(cfg.MetricsNamespace,
@@ -121,13 +142,53 @@ func run() error {
 		kgo.SeedBrokers(cfg.Kafka.BootstrapServers...),
 		kgo.ProducerBatchCompression(kgo.ZstdCompression()),
 		kgo.ProducerBatchMaxBytes(int32(cfg.Kafka.MaxMessageSizeMB*1024*1024)),
-		kgo.WithLogger(kgo.BasicLogger(os.Stdout, kgo.LogLevelInfo, nil)),
+		kgo.WithLogger(logging.NewKafkaLogger()),
 	)
 	if err != nil {
 		return errors.Wrap(err, "creating kafka client")
 	}
 
-	kafkaClient := kafka.NewClient(kcl)
+	metrics := domain.NewMetrics(cfg.MetricsNamespace)
+	oversizeConfig := kafka.OversizeConfig{
+		MaxMessageBytes: cfg.Kafka.MaxMessageSizeMB * 1024 * 1024,
+		Mode:            cfg.Kafka.OversizeMode,
+		Metrics:         metrics,
+	}
+	switch cfg.Kafka.OversizeMode {
+	case kafka.OversizeModeChunk:
+	case kafka.OversizeModeOffload:
+		blobStore, err := filestore.NewBlobStore(cfg.BlobStoreFolder)
+		if err != nil {
+			return fmt.Errorf("creating blob store: %v", err)
+		}
+		oversizeConfig.BlobStore = blobStore
+	default:
+		return fmt.Errorf("invalid oversize mode [%s]", cfg.Kafka.OversizeMode)
+	}
+	var publisher domain.Publisher = kafka.NewClient(kcl, oversizeConfig)
+
+	if cfg.Verification.Enabled {
+		var quarantinePublisher domain.Publisher
+		if cfg.Verification.MismatchMode == domain.MismatchModeQuarantine {
+			quarantineKcl, err := kgo.NewClient(
+				kgo.DefaultProduceTopic(cfg.Kafka.QuarantineTopic),
+				kgo.SeedBrokers(cfg.Kafka.BootstrapServers...),
+				kgo.ProducerBatchCompression(kgo.ZstdCompression()),
+				kgo.ProducerBatchMaxBytes(int32(cfg.Kafka.MaxMessageSizeMB*1024*1024)),
+				kgo.WithLogger(logging.NewKafkaLogger()),
+			)
+			if err != nil {
+				return errors.Wrap(err, "creating quarantine kafka client")
+			}
+			defer quarantineKcl.Close()
+			quarantinePublisher = kafka.NewClient(quarantineKcl, oversizeConfig)
+		}
+		publisher, err = domain.NewVerifyingPublisher(qubic.NewVerifier(), publisher, quarantinePublisher, cfg.Verification.MismatchMode, sLogger, metrics)
+		if err != nil {
+			return fmt.Errorf("creating verifying publisher: %v", err)
+		}
+		sLogger.Infow("Transaction verification enabled.", "mismatchMode", cfg.Verification.MismatchMode)
+	}
 
 	maxRecvSize := cfg.MaxRecvSizeInMb * 1024 * 1024
 	archiverClient, err := archiver.NewClient(cfg.ArchiverGrpcHost, maxRecvSize)
@@ -135,8 +196,7 @@ func run() error {
 		return fmt.Errorf("creating archiver client: %v", err)
 	}
 
-	metrics := domain.NewMetrics(cfg.MetricsNamespace)
-	proc := domain.NewProcessor(archiverClient, cfg.ArchiverReadTimeout, kafkaClient, procStore, cfg.NrWorkers, sLogger, metrics)
+	proc := domain.NewProcessor(archiverClient, cfg.ArchiverReadTimeout, publisher, procStore, cfg.NrWorkers, sLogger, metrics)
 	if err != nil {
 		return fmt.Errorf("creating processor: %v", err)
 	}
@@ -144,21 +204,31 @@ func run() error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
+	procCtx, cancelProc := context.WithCancel(context.Background())
+	defer cancelProc()
 	procErrors := make(chan error, 1)
 	if len(cfg.PublishCustomTicks) > 0 {
-		log.Printf("main: publishing custom ticks: %v", cfg.PublishCustomTicks)
+		sLogger.Infow("Publishing custom ticks.", "ticks", cfg.PublishCustomTicks)
 		go func() {
 			procErrors <- proc.PublishSingleTicks(cfg.PublishCustomTicks)
 		}()
 	} else {
+		poller := archiver.NewAdaptivePoller(archiverClient, cfg.ArchiverReadTimeout, cfg.ArchiverPollMinInterval, cfg.ArchiverPollMaxInterval)
+		var statusFeed *archiver.StatusFeed
+		if cfg.ArchiverStatusStream {
+			statusFeed = archiver.NewStatusFeed(archiverClient, poller, cfg.ArchiverStreamIdleTimeout)
+		} else {
+			statusFeed = archiver.NewStatusFeed(nil, poller, cfg.ArchiverStreamIdleTimeout)
+		}
+		defer statusFeed.Close()
 		go func() {
-			procErrors <- proc.Start()
+			procErrors <- proc.Start(procCtx, statusFeed)
 		}()
 	}
 
 	serverErr := make(chan error, 1)
 	go func() {
-		log.Printf("main: starting status and metrics endpoint on port [%d]", cfg.MetricsPort)
+		sLogger.Infow("Starting status, metrics and log level endpoint.", "port", cfg.MetricsPort)
 		http.HandleFunc("/v1/status", func(w http.ResponseWriter, r *http.Request) {
 			lastProcessedTick, err := procStore.GetLastProcessedTick()
 			if err != nil {
@@ -180,6 +250,7 @@ func run() error {
 			}
 		})
 		http.Handle("/metrics", promhttp.Handler())
+		logging.Handle(http.DefaultServeMux, logLevel)
 		serverErr <- http.ListenAndServe(fmt.Sprintf(":%d", cfg.MetricsPort), nil)
 	}()
 
@@ -191,7 +262,7 @@ func run() error {
 			if err != nil {
 				return fmt.Errorf("processing error: %v", err)
 			} else {
-				log.Print("main: processing finished without error")
+				sLogger.Info("Processing finished without error.")
 				return nil
 			}
 		case err := <-serverErr:
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qubic/transactions-producer/entities"
+)
+
+// RewindToTick creates a rewind checkpoint that resets the last processed tick to the given tick. The tick needs
+// to be covered by a previously recorded checkpoint.
+func RewindToTick(history []entities.Checkpoint, tick uint32) (entities.Checkpoint, error) {
+	current, err := currentCheckpoint(history)
+	if err != nil {
+		return entities.Checkpoint{}, err
+	}
+	if tick > current.Tick {
+		return entities.Checkpoint{}, fmt.Errorf("tick [%d] is after last processed tick [%d]", tick, current.Tick)
+	}
+
+	for _, checkpoint := range history {
+		if checkpoint.Rewind {
+			continue
+		}
+		// the tick before the start tick belongs to the previous checkpoint and is a valid rewind target, too
+		if checkpoint.StartTick <= tick+1 && tick <= checkpoint.Tick {
+			return entities.Checkpoint{
+				Epoch:     checkpoint.Epoch,
+				StartTick: tick,
+				Tick:      tick,
+				Timestamp: time.Now().UTC(),
+				Rewind:    true,
+				Reason:    entities.ReasonRewindTick,
+			}, nil
+		}
+	}
+	return entities.Checkpoint{}, fmt.Errorf("tick [%d] not found in checkpoint history", tick)
+}
+
+// RewindToEpochStart creates a rewind checkpoint that resets the last processed tick to the tick before the
+// first recorded tick of the given epoch.
+func RewindToEpochStart(history []entities.Checkpoint, epoch uint32) (entities.Checkpoint, error) {
+	var startTick uint32
+	for _, checkpoint := range history {
+		if checkpoint.Rewind || checkpoint.Epoch != epoch {
+			continue
+		}
+		if startTick == 0 || checkpoint.StartTick < startTick {
+			startTick = checkpoint.StartTick
+		}
+	}
+	if startTick == 0 {
+		return entities.Checkpoint{}, fmt.Errorf("epoch [%d] not found in checkpoint history", epoch)
+	}
+
+	return entities.Checkpoint{
+		Epoch:     epoch,
+		StartTick: startTick - 1,
+		Tick:      startTick - 1,
+		Timestamp: time.Now().UTC(),
+		Rewind:    true,
+		Reason:    entities.ReasonRewindEpoch,
+	}, nil
+}
+
+// ResetToTick creates a rewind checkpoint that sets the last processed tick to the given tick without checking the
+// history, for example to initialize the store or to apply the configured override. The epoch is unknown.
+func ResetToTick(tick uint32, reason string) entities.Checkpoint {
+	return entities.Checkpoint{
+		StartTick: tick,
+		Tick:      tick,
+		Timestamp: time.Now().UTC(),
+		Rewind:    true,
+		Reason:    reason,
+	}
+}
+
+func currentCheckpoint(history []entities.Checkpoint) (entities.Checkpoint, error) {
+	if len(history) == 0 {
+		return entities.Checkpoint{}, fmt.Errorf("empty checkpoint history")
+	}
+	return history[len(history)-1], nil
+}
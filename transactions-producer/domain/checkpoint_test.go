@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/qubic/transactions-producer/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var checkpointHistory = []entities.Checkpoint{
+	{Epoch: 100, StartTick: 1001, Tick: 1010, TransactionCount: 5},
+	{Epoch: 100, StartTick: 1011, Tick: 1020, TransactionCount: 7},
+	{Epoch: 101, StartTick: 2001, Tick: 2010, TransactionCount: 3},
+	{Epoch: 101, StartTick: 2011, Tick: 2015, TransactionCount: 1},
+}
+
+func TestRewindToTick(t *testing.T) {
+	checkpoint, err := RewindToTick(checkpointHistory, 1015)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1015), checkpoint.Tick)
+	assert.Equal(t, uint32(100), checkpoint.Epoch)
+	assert.True(t, checkpoint.Rewind)
+	assert.Equal(t, entities.ReasonRewindTick, checkpoint.Reason)
+
+	// tick before the first tick of an epoch
+	checkpoint, err = RewindToTick(checkpointHistory, 2000)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2000), checkpoint.Tick)
+	assert.Equal(t, uint32(101), checkpoint.Epoch)
+
+	_, err = RewindToTick(checkpointHistory, 2016)
+	require.ErrorContains(t, err, "after last processed tick")
+
+	_, err = RewindToTick(checkpointHistory, 1500)
+	require.ErrorContains(t, err, "not found")
+
+	_, err = RewindToTick(nil, 1000)
+	require.Error(t, err)
+}
+
+func TestRewindToEpochStart(t *testing.T) {
+	checkpoint, err := RewindToEpochStart(checkpointHistory, 101)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2000), checkpoint.Tick)
+	assert.Equal(t, uint32(101), checkpoint.Epoch)
+	assert.True(t, checkpoint.Rewind)
+	assert.Equal(t, entities.ReasonRewindEpoch, checkpoint.Reason)
+
+	// ignores rewinds and uses the lowest start tick
+	history := append(checkpointHistory,
+		entities.Checkpoint{Epoch: 100, StartTick: 1005, Tick: 1005, Rewind: true},
+		entities.Checkpoint{Epoch: 100, StartTick: 1006, Tick: 1015},
+	)
+	checkpoint, err = RewindToEpochStart(history, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1000), checkpoint.Tick)
+
+	_, err = RewindToEpochStart(checkpointHistory, 99)
+	require.ErrorContains(t, err, "not found")
+}
+
+func TestResetToTick(t *testing.T) {
+	checkpoint := ResetToTick(1500, entities.ReasonOverride)
+	assert.Equal(t, uint32(1500), checkpoint.StartTick)
+	assert.Equal(t, uint32(1500), checkpoint.Tick)
+	assert.True(t, checkpoint.Rewind)
+	assert.Equal(t, entities.ReasonOverride, checkpoint.Reason)
+	assert.False(t, checkpoint.Timestamp.IsZero())
+
+	// the reset is the current checkpoint, but not a rewind target
+	history := append(checkpointHistory, checkpoint)
+	_, err := RewindToTick(history, 1600)
+	require.ErrorContains(t, err, "after last processed tick")
+	_, err = RewindToTick(history, 1500)
+	require.ErrorContains(t, err, "not found")
+}
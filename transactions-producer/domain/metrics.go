@@ -14,6 +14,8 @@ type Metrics struct {
 	processingEpochGauge  prometheus.Gauge
 	processedMessageCount prometheus.Counter
 	processedTicksCount   prometheus.Counter
+	oversizedPayloadCount *prometheus.CounterVec
+	verificationFailures  *prometheus.CounterVec
 }
 
 func NewMetrics(namespace string) *Metrics {
@@ -35,6 +37,14 @@ func NewMetrics(namespace string) *Metrics {
 			Name: fmt.Sprintf("%s_processed_message_count", namespace),
 			Help: "The total number of processed message records",
 		}),
+		oversizedPayloadCount: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_oversized_payload_count", namespace),
+			Help: "The total number of payloads exceeding the maximum message size",
+		}, []string{"mode"}),
+		verificationFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_verification_failure_count", namespace),
+			Help: "The total number of transactions failing hash or signature verification",
+		}, []string{"reason", "mode"}),
 		// metrics for comparison to event source
 		sourceTickGauge: promauto.NewGauge(prometheus.GaugeOpts{
 			Name: fmt.Sprintf("%s_source_tick", namespace),
@@ -65,3 +75,11 @@ func (metrics *Metrics) SetSourceTick(epoch uint32, tick uint32) {
 	metrics.sourceEpochGauge.Set(float64(epoch))
 	metrics.sourceTickGauge.Set(float64(tick))
 }
+
+func (metrics *Metrics) IncOversizedPayloads(mode string) {
+	metrics.oversizedPayloadCount.WithLabelValues(mode).Inc()
+}
+
+func (metrics *Metrics) IncVerificationFailures(reason, mode string) {
+	metrics.verificationFailures.WithLabelValues(reason, mode).Inc()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/qubic/transactions-producer/entities"
@@ -23,7 +24,7 @@ type Publisher interface {
 
 type statusStore interface {
 	GetLastProcessedTick() (uint32, error)
-	SetLastProcessedTick(tick uint32) error
+	SaveCheckpoint(checkpoint entities.Checkpoint) error
 }
 
 type Processor struct {
@@ -56,10 +57,28 @@ func NewProcessor(
 	}
 }
 
-func (p *Processor) Start() error {
-	ticker := time.Tick(1 * time.Second)
-	for range ticker {
-		err := p.process()
+// StatusSource delivers the archiver status whenever new ticks might be available.
+type StatusSource interface {
+	// NextStatus blocks until the next status is available. If the caller did not catch up with the last status
+	// it should not block.
+	NextStatus(ctx context.Context, caughtUp bool) ([]entities.ProcessedTickIntervalsPerEpoch, error)
+}
+
+// Start processes new ticks until the context is canceled or a non-retriable kafka error occurs.
+func (p *Processor) Start(ctx context.Context, source StatusSource) error {
+	caughtUp := true
+	for ctx.Err() == nil {
+		intervals, err := source.NextStatus(ctx, caughtUp)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			p.logger.Errorw("error getting archiver status", "error", err)
+			caughtUp = true // wait for the next status
+			continue
+		}
+
+		caughtUp, err = p.processIntervals(intervals)
 		if err != nil {
 			if kafkaErr, ok := errors.AsType[*kerr.Error](err); ok {
 				if !kafkaErr.Retriable {
@@ -68,6 +87,7 @@ func (p *Processor) Start() error {
 			}
 			// only exit, if non-retriable kafka error
 			p.logger.Errorw("error running processing cycle", "error", err)
+			caughtUp = true // do not retry immediately
 		}
 	}
 	return nil
@@ -86,7 +106,7 @@ func (p *Processor) PublishSingleTicks(ticks []uint32) error {
 		}
 
 		p.logger.Infow("Trying to publish transactions", "tick", tick)
-		err = p.processTick(epoch, tick)
+		_, err = p.processTick(epoch, tick)
 		if err != nil {
 			return fmt.Errorf("processing tick [%d]: %w", tick, err)
 		}
@@ -102,16 +122,22 @@ func (p *Processor) process() error {
 	if err != nil {
 		return fmt.Errorf("getting tick intervals: %w", err)
 	}
+	_, err = p.processIntervals(intervals)
+	return err
+}
+
+// processIntervals processes the next tick range and returns true, if there are no more ticks to process.
+func (p *Processor) processIntervals(intervals []entities.ProcessedTickIntervalsPerEpoch) (bool, error) {
 	p.setLatestSourceTickToMetrics(intervals)
 
 	tick, err := p.statusStore.GetLastProcessedTick()
 	if err != nil {
-		return fmt.Errorf("get last processed tick: %w", err)
+		return false, fmt.Errorf("get last processed tick: %w", err)
 	}
 
 	start, end, epoch, err := calculateTickRange(tick, intervals)
 	if err != nil {
-		return fmt.Errorf("calculating tick range: %w", err)
+		return false, fmt.Errorf("calculating tick range: %w", err)
 	}
 
 	if start <= end && start > 0 && end > 0 && epoch > 0 {
@@ -119,10 +145,11 @@ func (p *Processor) process() error {
 		// if start == end, then process one tick
 		err = p.processTickRange(epoch, start, end)
 		if err != nil {
-			return fmt.Errorf("processing tick range: %w", err)
+			return false, fmt.Errorf("processing tick range: %w", err)
 		}
+		return end >= latestSourceTick(intervals), nil
 	}
-	return nil
+	return true, nil
 }
 
 func (p *Processor) processTickRange(epoch, from, to uint32) error {
@@ -132,12 +159,19 @@ func (p *Processor) processTickRange(epoch, from, to uint32) error {
 		// process several ticks in parallel
 		nextTicks = append(nextTicks, tick)
 		if len(nextTicks) == p.maxWorkers || tick == to {
-			err := p.processTickRangeParallel(epoch, nextTicks)
+			txCount, err := p.processTickRangeParallel(epoch, nextTicks)
 			if err != nil {
 				return fmt.Errorf("processing ticks [%d]: %w", nextTicks, err)
 			}
 
-			err = p.statusStore.SetLastProcessedTick(tick) // set after completing the batch
+			// set after completing the batch
+			err = p.statusStore.SaveCheckpoint(entities.Checkpoint{
+				Epoch:            epoch,
+				StartTick:        nextTicks[0],
+				Tick:             tick,
+				TransactionCount: txCount,
+				Timestamp:        time.Now().UTC(),
+			})
 			if err != nil {
 				return fmt.Errorf("storing last processed tick [%d]: %w", tick, err)
 			}
@@ -154,17 +188,21 @@ func (p *Processor) processTickRange(epoch, from, to uint32) error {
 	return nil
 }
 
-func (p *Processor) processTickRangeParallel(epoch uint32, ticks []uint32) error {
+func (p *Processor) processTickRangeParallel(epoch uint32, ticks []uint32) (int, error) {
 	var errorGroup errgroup.Group
+	var txCount atomic.Int64
 	for _, tick := range ticks {
 		errorGroup.Go(func() error {
-			return p.processTick(epoch, tick)
+			count, err := p.processTick(epoch, tick)
+			txCount.Add(int64(count))
+			return err
 		})
 	}
-	return errorGroup.Wait()
+	err := errorGroup.Wait()
+	return int(txCount.Load()), err
 }
 
-func (p *Processor) processTick(epoch, tick uint32) error {
+func (p *Processor) processTick(epoch, tick uint32) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), p.fetchTimeout)
 	defer cancel()
 
@@ -172,7 +210,7 @@ func (p *Processor) processTick(epoch, tick uint32) error {
 	transactions, err := p.fetcher.GetTickTransactions(ctx, tick)
 	fetchDuration := time.Since(fetchStart)
 	if err != nil {
-		return fmt.Errorf("fetching transactions: %w", err)
+		return 0, fmt.Errorf("fetching transactions: %w", err)
 	}
 	if len(transactions) == 0 {
 		p.logger.Infow("Skipping tick without transactions", "epoch", epoch, "tick", tick, "fetch", fetchDuration.Milliseconds())
@@ -184,11 +222,11 @@ func (p *Processor) processTick(epoch, tick uint32) error {
 		if err != nil {
 			// extra log so that we know what tick failed
 			p.logger.Errorw("Error publishing tick transactions", "epoch", epoch, "tick", tick, "error", err)
-			return fmt.Errorf("inserting batch: %w", err)
+			return 0, fmt.Errorf("inserting batch: %w", err)
 		}
 		p.syncMetrics.IncProcessedMessages(len(transactions))
 	}
-	return nil
+	return len(transactions), nil
 }
 
 func calculateTickRange(lastProcessedTick uint32, epochsIntervals []entities.ProcessedTickIntervalsPerEpoch) (uint32, uint32, uint32, error) {
@@ -220,6 +258,14 @@ func getEpochForTick(tick uint32, intervals []entities.ProcessedTickIntervalsPer
 	return 0, fmt.Errorf("found no epoch")
 }
 
+func latestSourceTick(epochs []entities.ProcessedTickIntervalsPerEpoch) uint32 {
+	if len(epochs) == 0 || len(epochs[len(epochs)-1].Intervals) == 0 {
+		return 0
+	}
+	intervals := epochs[len(epochs)-1].Intervals
+	return intervals[len(intervals)-1].LastProcessedTick
+}
+
 func (p *Processor) setLatestSourceTickToMetrics(epochs []entities.ProcessedTickIntervalsPerEpoch) {
 	// last epoch and last interval contains latest source tick
 	if len(epochs) > 0 { // check if there are epochs
@@ -64,6 +64,14 @@ func (mf *MockFetcher) GetTickTransactions(_ context.Context, tick uint32) ([]en
 	}, nil
 }
 
+type MockStatusSource struct {
+	fetcher *MockFetcher
+}
+
+func (ms *MockStatusSource) NextStatus(ctx context.Context, _ bool) ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	return ms.fetcher.GetProcessedTickIntervalsPerEpoch(ctx)
+}
+
 type MockPublisher struct {
 	publishedTickTransactions []entities.Transaction
 	error                     error
@@ -143,6 +151,57 @@ func TestTxProcessor_process(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 100+1+10, len(publisher.publishedTickTransactions))
 
+	history, err := store.GetCheckpointHistory()
+	require.NoError(t, err)
+	require.Len(t, history, 11+1+1) // one checkpoint per batch of workers
+	txCount := 0
+	for _, checkpoint := range history {
+		txCount += checkpoint.TransactionCount
+	}
+	require.Equal(t, 100+1+10, txCount)
+	require.Equal(t, uint32(50000010), history[len(history)-1].Tick)
+	require.Equal(t, uint32(103), history[len(history)-1].Epoch)
+
+}
+
+func TestTxProcessor_processIntervals_CaughtUp(t *testing.T) {
+	intervals := []entities.ProcessedTickIntervalsPerEpoch{
+		{
+			Epoch: 100,
+			Intervals: []entities.ProcessedTickInterval{
+				{InitialProcessedTick: 10000001, LastProcessedTick: 10000005},
+			},
+		},
+		{
+			Epoch: 101,
+			Intervals: []entities.ProcessedTickInterval{
+				{InitialProcessedTick: 20000001, LastProcessedTick: 20000005},
+			},
+		},
+	}
+
+	dbDir, err := os.MkdirTemp("", "pebble_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dbDir)
+	store, err := pebbledb.NewProcessorStore(dbDir)
+	require.NoError(t, err)
+	defer store.Close()
+	err = store.SetLastProcessedTick(0)
+	require.NoError(t, err)
+
+	txProcessor := NewProcessor(&MockFetcher{}, time.Second, &MockPublisher{}, store, 10, zap.NewNop().Sugar(), metrics)
+
+	caughtUp, err := txProcessor.processIntervals(intervals) // first epoch
+	require.NoError(t, err)
+	assert.False(t, caughtUp)
+
+	caughtUp, err = txProcessor.processIntervals(intervals) // second epoch
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+
+	caughtUp, err = txProcessor.processIntervals(intervals) // nothing to do
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
 }
 
 func TestTxProcessor_RunCycle(t *testing.T) {
@@ -487,7 +546,7 @@ func TestProcessor_Start_NonRetriableKafkaError(t *testing.T) {
 	// run with a timeout
 	done := make(chan error, 1)
 	go func() {
-		done <- txProcessor.Start()
+		done <- txProcessor.Start(context.Background(), &MockStatusSource{fetcher: &fetcher})
 	}()
 
 	// wait for the error or timeout
@@ -499,3 +558,30 @@ func TestProcessor_Start_NonRetriableKafkaError(t *testing.T) {
 		t.Fatal("Test timed out - Start() should have returned an error")
 	}
 }
+
+func TestProcessor_Start_ContextCanceled(t *testing.T) {
+	fetcher := MockFetcher{}
+	txProcessor := NewProcessor(&fetcher, time.Second, &MockPublisher{}, nil, 10, zap.NewNop().Sugar(), metrics)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- txProcessor.Start(ctx, &blockingStatusSource{})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test timed out - Start() should have returned after cancel")
+	}
+}
+
+// blockingStatusSource blocks until the context is canceled.
+type blockingStatusSource struct{}
+
+func (s *blockingStatusSource) NextStatus(ctx context.Context, _ bool) ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
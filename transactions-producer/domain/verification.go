@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qubic/transactions-producer/entities"
+	"go.uber.org/zap"
+)
+
+const (
+	MismatchModeHalt       = "halt"       // stop publishing. Processing is retried but will fail until resolved manually.
+	MismatchModeSkip       = "skip"       // do not publish the transaction
+	MismatchModeQuarantine = "quarantine" // publish the transaction to the quarantine topic
+)
+
+type TransactionVerifier interface {
+	Verify(tx entities.Transaction) error
+}
+
+// VerifyingPublisher verifies transaction hashes and signatures before publishing. Transactions that fail
+// verification are handled according to the mismatch mode.
+type VerifyingPublisher struct {
+	verifier   TransactionVerifier
+	publisher  Publisher
+	quarantine Publisher
+	mode       string
+	logger     *zap.SugaredLogger
+	metrics    *Metrics
+}
+
+// NewVerifyingPublisher creates a verifying publisher. The quarantine publisher is only needed for the quarantine
+// mismatch mode.
+func NewVerifyingPublisher(verifier TransactionVerifier, publisher, quarantine Publisher, mode string, logger *zap.SugaredLogger, metrics *Metrics) (*VerifyingPublisher, error) {
+	switch mode {
+	case MismatchModeHalt, MismatchModeSkip:
+	case MismatchModeQuarantine:
+		if quarantine == nil {
+			return nil, errors.New("quarantine mode needs quarantine publisher")
+		}
+	default:
+		return nil, fmt.Errorf("invalid mismatch mode [%s]", mode)
+	}
+	return &VerifyingPublisher{
+		verifier:   verifier,
+		publisher:  publisher,
+		quarantine: quarantine,
+		mode:       mode,
+		logger:     logger,
+		metrics:    metrics,
+	}, nil
+}
+
+func (vp *VerifyingPublisher) PublishTickTransactions(transactions []entities.Transaction) error {
+	verified := make([]entities.Transaction, 0, len(transactions))
+	var quarantined []entities.Transaction
+	for _, tx := range transactions {
+		err := vp.verifier.Verify(tx)
+		if err == nil {
+			verified = append(verified, tx)
+			continue
+		}
+
+		vp.metrics.IncVerificationFailures(verificationFailureReason(err), vp.mode)
+		vp.logger.Errorw("Transaction verification failed", "tick", tx.TickNumber, "hash", tx.Hash, "mode", vp.mode, "error", err)
+		switch vp.mode {
+		case MismatchModeSkip:
+		case MismatchModeQuarantine:
+			quarantined = append(quarantined, tx)
+		default:
+			return fmt.Errorf("verifying transaction [%s] in tick [%d]: %w", tx.Hash, tx.TickNumber, err)
+		}
+	}
+
+	if len(quarantined) > 0 {
+		err := vp.quarantine.PublishTickTransactions(quarantined)
+		if err != nil {
+			return fmt.Errorf("publishing quarantined transactions: %w", err)
+		}
+	}
+	if len(verified) > 0 {
+		return vp.publisher.PublishTickTransactions(verified)
+	}
+	return nil
+}
+
+func verificationFailureReason(err error) string {
+	switch {
+	case errors.Is(err, entities.ErrHashMismatch):
+		return "hash"
+	case errors.Is(err, entities.ErrInvalidSignature):
+		return "signature"
+	default:
+		return "invalid"
+	}
+}
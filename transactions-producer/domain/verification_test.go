@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/qubic/transactions-producer/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockVerifier struct {
+	failures map[string]error
+}
+
+func (mv *MockVerifier) Verify(tx entities.Transaction) error {
+	return mv.failures[tx.Hash]
+}
+
+func verificationTestTransactions() []entities.Transaction {
+	return []entities.Transaction{
+		{Hash: "valid-1", TickNumber: 42},
+		{Hash: "wrong-hash", TickNumber: 42},
+		{Hash: "valid-2", TickNumber: 42},
+		{Hash: "wrong-signature", TickNumber: 42},
+	}
+}
+
+func verificationTestVerifier() *MockVerifier {
+	return &MockVerifier{failures: map[string]error{
+		"wrong-hash":      entities.ErrHashMismatch,
+		"wrong-signature": entities.ErrInvalidSignature,
+	}}
+}
+
+func TestVerifyingPublisher_Halt(t *testing.T) {
+	publisher := &MockPublisher{}
+	vp, err := NewVerifyingPublisher(verificationTestVerifier(), publisher, nil, MismatchModeHalt, zap.NewNop().Sugar(), metrics)
+	require.NoError(t, err)
+
+	err = vp.PublishTickTransactions(verificationTestTransactions())
+	require.ErrorIs(t, err, entities.ErrHashMismatch)
+	assert.Empty(t, publisher.publishedTickTransactions)
+}
+
+func TestVerifyingPublisher_Skip(t *testing.T) {
+	publisher := &MockPublisher{}
+	vp, err := NewVerifyingPublisher(verificationTestVerifier(), publisher, nil, MismatchModeSkip, zap.NewNop().Sugar(), metrics)
+	require.NoError(t, err)
+
+	err = vp.PublishTickTransactions(verificationTestTransactions())
+	require.NoError(t, err)
+	assert.Equal(t, []entities.Transaction{{Hash: "valid-1", TickNumber: 42}, {Hash: "valid-2", TickNumber: 42}}, publisher.publishedTickTransactions)
+
+	// nothing to publish
+	publisher.publishedTickTransactions = nil
+	err = vp.PublishTickTransactions([]entities.Transaction{{Hash: "wrong-hash", TickNumber: 43}})
+	require.NoError(t, err)
+	assert.Empty(t, publisher.publishedTickTransactions)
+}
+
+func TestVerifyingPublisher_Quarantine(t *testing.T) {
+	publisher := &MockPublisher{}
+	quarantine := &MockPublisher{}
+	vp, err := NewVerifyingPublisher(verificationTestVerifier(), publisher, quarantine, MismatchModeQuarantine, zap.NewNop().Sugar(), metrics)
+	require.NoError(t, err)
+
+	err = vp.PublishTickTransactions(verificationTestTransactions())
+	require.NoError(t, err)
+	assert.Equal(t, []entities.Transaction{{Hash: "valid-1", TickNumber: 42}, {Hash: "valid-2", TickNumber: 42}}, publisher.publishedTickTransactions)
+	assert.Equal(t, []entities.Transaction{{Hash: "wrong-hash", TickNumber: 42}, {Hash: "wrong-signature", TickNumber: 42}}, quarantine.publishedTickTransactions)
+
+	// do not publish, if quarantine fails
+	publisher.publishedTickTransactions = nil
+	quarantine.error = ErrMock
+	err = vp.PublishTickTransactions(verificationTestTransactions())
+	require.ErrorIs(t, err, ErrMock)
+	assert.Empty(t, publisher.publishedTickTransactions)
+}
+
+func TestNewVerifyingPublisher_InvalidConfig(t *testing.T) {
+	_, err := NewVerifyingPublisher(verificationTestVerifier(), &MockPublisher{}, nil, MismatchModeQuarantine, zap.NewNop().Sugar(), metrics)
+	require.Error(t, err)
+
+	_, err = NewVerifyingPublisher(verificationTestVerifier(), &MockPublisher{}, nil, "unknown", zap.NewNop().Sugar(), metrics)
+	require.Error(t, err)
+}
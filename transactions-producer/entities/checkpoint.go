@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// Checkpoint records one advance (or rewind) of the last processed tick.
+type Checkpoint struct {
+	Epoch            uint32    `json:"epoch"`
+	StartTick        uint32    `json:"startTick"`
+	Tick             uint32    `json:"tick"`
+	TransactionCount int       `json:"transactionCount"`
+	Timestamp        time.Time `json:"timestamp"`
+	Rewind           bool      `json:"rewind,omitempty"`
+	Reason           string    `json:"reason,omitempty"` // why the last processed tick was reset. Only for rewinds.
+}
+
+// Reasons of rewind checkpoints.
+const (
+	ReasonRewindTick  = "rewind-tick"  // checkpoints rewind tick command
+	ReasonRewindEpoch = "rewind-epoch" // checkpoints rewind epoch command
+	ReasonOverride    = "override"     // override last processed tick option
+	ReasonInit        = "init"         // empty store
+)
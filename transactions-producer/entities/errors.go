@@ -4,3 +4,5 @@ import "errors"
 
 var ErrStoreEntityNotFound = errors.New("store resource not found")
 var ErrEmptyTick = errors.New("empty tick")
+var ErrHashMismatch = errors.New("transaction hash mismatch")
+var ErrInvalidSignature = errors.New("invalid transaction signature")
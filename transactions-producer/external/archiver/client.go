@@ -10,9 +10,15 @@ import (
 	"github.com/qubic/transactions-producer/entities"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// streamStatusMethod is the server streaming method that pushes the archiver status whenever new ticks got
+// processed. Archivers that do not support it answer with codes.Unimplemented.
+const streamStatusMethod = "/qubic.archiver.v2.pb.ArchiveService/StreamStatus"
+
 type Client struct {
+	conn           grpc.ClientConnInterface
 	archiverClient archiverproto.ArchiveServiceClient
 }
 
@@ -27,7 +33,7 @@ func NewClient(host string, maxCallRecvMsgSize int) (*Client, error) {
 		return nil, fmt.Errorf("creating grpc connection: %v", err)
 	}
 
-	return &Client{archiverClient: archiverproto.NewArchiveServiceClient(archiverConn)}, nil
+	return &Client{conn: archiverConn, archiverClient: archiverproto.NewArchiveServiceClient(archiverConn)}, nil
 }
 
 func (c *Client) GetTickTransactions(ctx context.Context, tick uint32) ([]entities.Transaction, error) {
@@ -53,6 +59,36 @@ func (c *Client) GetProcessedTickIntervalsPerEpoch(ctx context.Context) ([]entit
 	return archiveStatusToEntitiesProcessedTickIntervals(resp.ProcessedTickIntervalsPerEpoch), nil
 }
 
+// StreamStatus opens the archiver status stream. Errors, like a missing implementation on the archiver side, are
+// only reported when receiving from the stream. Archivers without the stream answer with codes.Unimplemented and the
+// status feed polls instead.
+func (c *Client) StreamStatus(ctx context.Context) (StatusStream, error) {
+	stream, err := c.conn.NewStream(ctx, &grpc.StreamDesc{StreamName: "StreamStatus", ServerStreams: true}, streamStatusMethod)
+	if err != nil {
+		return nil, fmt.Errorf("opening grpc stream: %v", err)
+	}
+	if err = stream.SendMsg(&emptypb.Empty{}); err != nil {
+		return nil, fmt.Errorf("sending stream request: %v", err)
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, fmt.Errorf("closing stream send direction: %v", err)
+	}
+	return &statusStream{stream: stream}, nil
+}
+
+type statusStream struct {
+	stream grpc.ClientStream
+}
+
+func (s *statusStream) Recv() ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	var resp archiverproto.GetStatusResponse
+	err := s.stream.RecvMsg(&resp)
+	if err != nil {
+		return nil, err // keep grpc status for the caller
+	}
+	return archiveStatusToEntitiesProcessedTickIntervals(resp.ProcessedTickIntervalsPerEpoch), nil
+}
+
 func archiveTxsToEntitiesTx(archiveTxs []*archiverproto.TransactionData) ([]entities.Transaction, error) {
 	entitiesTx := make([]entities.Transaction, 0, len(archiveTxs))
 
@@ -11,11 +11,20 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type testArchiveServer struct {
 	archiverproto.UnimplementedArchiveServiceServer
 	getTickTransactionsV2Func func(ctx context.Context, in *archiverproto.GetTickTransactionsRequestV2) (*archiverproto.GetTickTransactionsResponseV2, error)
+	getStatusFunc             func(ctx context.Context, in *emptypb.Empty) (*archiverproto.GetStatusResponse, error)
+}
+
+func (s *testArchiveServer) GetStatus(ctx context.Context, in *emptypb.Empty) (*archiverproto.GetStatusResponse, error) {
+	if s.getStatusFunc != nil {
+		return s.getStatusFunc(ctx, in)
+	}
+	return s.UnimplementedArchiveServiceServer.GetStatus(ctx, in)
 }
 
 func (s *testArchiveServer) GetTickTransactionsV2(ctx context.Context, in *archiverproto.GetTickTransactionsRequestV2) (*archiverproto.GetTickTransactionsResponseV2, error) {
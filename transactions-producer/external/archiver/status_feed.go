@@ -0,0 +1,196 @@
+package archiver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qubic/logging"
+	"github.com/qubic/transactions-producer/entities"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type statusFetcher interface {
+	GetProcessedTickIntervalsPerEpoch(ctx context.Context) ([]entities.ProcessedTickIntervalsPerEpoch, error)
+}
+
+// statusStreamer is the source of status streams. It is optional, the status feed falls back to the adaptive poller,
+// if opening or receiving fails.
+type statusStreamer interface {
+	StreamStatus(ctx context.Context) (StatusStream, error)
+}
+
+// StatusStream delivers the archiver status whenever new ticks got processed. Recv blocks until the next status
+// arrives or the context of the stream is canceled.
+type StatusStream interface {
+	Recv() ([]entities.ProcessedTickIntervalsPerEpoch, error)
+}
+
+// AdaptivePoller polls the archiver status. It polls with the minimum interval as long as new ticks arrive and
+// doubles the interval up to the maximum interval while the archiver is idle or unavailable.
+type AdaptivePoller struct {
+	fetcher     statusFetcher
+	timeout     time.Duration
+	minInterval time.Duration
+	maxInterval time.Duration
+	interval    time.Duration
+	lastPoll    time.Time
+	latestTick  uint32
+}
+
+func NewAdaptivePoller(fetcher statusFetcher, timeout, minInterval, maxInterval time.Duration) *AdaptivePoller {
+	return &AdaptivePoller{
+		fetcher:     fetcher,
+		timeout:     timeout,
+		minInterval: minInterval,
+		maxInterval: max(minInterval, maxInterval),
+		interval:    minInterval,
+	}
+}
+
+// NextStatus waits for the current poll interval and fetches the status.
+func (p *AdaptivePoller) NextStatus(ctx context.Context) ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	if wait := time.Until(p.lastPoll.Add(p.interval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	p.lastPoll = time.Now()
+	fetchCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	intervals, err := p.fetcher.GetProcessedTickIntervalsPerEpoch(fetchCtx)
+	if err != nil {
+		p.backOff()
+		return nil, fmt.Errorf("polling status: %w", err)
+	}
+
+	if tick := latestTick(intervals); tick > p.latestTick {
+		p.latestTick = tick
+		p.interval = p.minInterval
+	} else {
+		p.backOff()
+	}
+	return intervals, nil
+}
+
+// Interval returns the current poll interval.
+func (p *AdaptivePoller) Interval() time.Duration {
+	return p.interval
+}
+
+func (p *AdaptivePoller) backOff() {
+	p.interval = min(p.interval*2, p.maxInterval)
+}
+
+// StatusFeed delivers the archiver status. It consumes the archiver status stream, if available, and falls back to
+// adaptive polling otherwise. Not safe for concurrent use.
+type StatusFeed struct {
+	streamer          statusStreamer
+	poller            *AdaptivePoller
+	idleTimeout       time.Duration
+	retryDelay        time.Duration
+	stream            StatusStream
+	cancelStream      context.CancelFunc
+	streamUnavailable bool
+	retryStreamAt     time.Time
+	last              []entities.ProcessedTickIntervalsPerEpoch
+}
+
+// NewStatusFeed creates a status feed. If the streamer is nil, the feed only polls. The idle timeout limits how long
+// to wait for a stream message before reconnecting.
+func NewStatusFeed(streamer statusStreamer, poller *AdaptivePoller, idleTimeout time.Duration) *StatusFeed {
+	return &StatusFeed{
+		streamer:          streamer,
+		poller:            poller,
+		idleTimeout:       idleTimeout,
+		retryDelay:        time.Minute,
+		streamUnavailable: streamer == nil,
+	}
+}
+
+// NextStatus returns the next archiver status. If the caller did not catch up with the last status, it is returned
+// again without waiting.
+func (f *StatusFeed) NextStatus(ctx context.Context, caughtUp bool) ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	if !caughtUp && f.last != nil {
+		return f.last, nil
+	}
+
+	var intervals []entities.ProcessedTickIntervalsPerEpoch
+	var err error
+	if f.streamUnavailable || time.Now().Before(f.retryStreamAt) {
+		intervals, err = f.poller.NextStatus(ctx)
+	} else {
+		intervals, err = f.receive(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.last = intervals
+	return intervals, nil
+}
+
+func (f *StatusFeed) receive(ctx context.Context) ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	if f.stream == nil {
+		streamCtx, cancel := context.WithCancel(ctx)
+		stream, err := f.streamer.StreamStatus(streamCtx)
+		if err != nil {
+			cancel()
+			zap.S().Warnw("Opening status stream failed. Polling instead.", logging.Error, err)
+			f.retryStreamAt = time.Now().Add(f.retryDelay)
+			return f.poller.NextStatus(ctx)
+		}
+		f.stream = stream
+		f.cancelStream = cancel
+	}
+
+	// cancel the stream, if the archiver does not send anything for too long
+	watchdog := time.AfterFunc(f.idleTimeout, f.cancelStream)
+	intervals, err := f.stream.Recv()
+	idle := !watchdog.Stop()
+	if err == nil {
+		return intervals, nil
+	}
+
+	f.closeStream()
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		zap.S().Info("Archiver does not support status streaming. Falling back to polling.")
+		f.streamUnavailable = true
+	case idle:
+		// reconnect with the next call. Poll once in the meantime.
+	default:
+		zap.S().Warnw("Receiving from status stream failed. Polling instead.", logging.Error, err)
+		f.retryStreamAt = time.Now().Add(f.retryDelay)
+	}
+	return f.poller.NextStatus(ctx)
+}
+
+func (f *StatusFeed) closeStream() {
+	if f.cancelStream != nil {
+		f.cancelStream()
+	}
+	f.stream = nil
+	f.cancelStream = nil
+}
+
+// Close releases the status stream, if any.
+func (f *StatusFeed) Close() {
+	f.closeStream()
+}
+
+func latestTick(intervals []entities.ProcessedTickIntervalsPerEpoch) uint32 {
+	// last epoch and last interval contains latest tick
+	if len(intervals) == 0 || len(intervals[len(intervals)-1].Intervals) == 0 {
+		return 0
+	}
+	epochIntervals := intervals[len(intervals)-1].Intervals
+	return epochIntervals[len(epochIntervals)-1].LastProcessedTick
+}
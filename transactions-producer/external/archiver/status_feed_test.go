@@ -0,0 +1,336 @@
+package archiver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	archiverproto "github.com/qubic/go-archiver-v2/protobuf"
+	"github.com/qubic/transactions-producer/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func statusResponse(epoch, lastTick uint32) *archiverproto.GetStatusResponse {
+	return &archiverproto.GetStatusResponse{
+		LastProcessedTick: &archiverproto.ProcessedTick{TickNumber: lastTick, Epoch: epoch},
+		ProcessedTickIntervalsPerEpoch: []*archiverproto.ProcessedTickIntervalsPerEpoch{
+			{
+				Epoch:     epoch,
+				Intervals: []*archiverproto.ProcessedTickInterval{{InitialProcessedTick: 1000, LastProcessedTick: lastTick}},
+			},
+		},
+	}
+}
+
+// startTestArchiver starts an in-process archiver. If the stream handler is set, the archiver supports the status
+// stream.
+func startTestArchiver(t *testing.T, server *testArchiveServer, streamHandler grpc.StreamHandler) *Client {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	serviceDesc := archiverproto.ArchiveService_ServiceDesc
+	if streamHandler != nil {
+		serviceDesc.Streams = append(serviceDesc.Streams, grpc.StreamDesc{
+			StreamName:    "StreamStatus",
+			Handler:       streamHandler,
+			ServerStreams: true,
+		})
+	}
+	s.RegisterService(&serviceDesc, server)
+
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	client, err := NewClient(lis.Addr().String(), 1024*1024)
+	require.NoError(t, err)
+	return client
+}
+
+func TestStatusFeed_Streaming(t *testing.T) {
+	var polls atomic.Int32
+	server := &testArchiveServer{
+		getStatusFunc: func(_ context.Context, _ *emptypb.Empty) (*archiverproto.GetStatusResponse, error) {
+			polls.Add(1)
+			return statusResponse(100, 1000), nil
+		},
+	}
+	client := startTestArchiver(t, server, func(_ any, stream grpc.ServerStream) error {
+		var request emptypb.Empty
+		if err := stream.RecvMsg(&request); err != nil {
+			return err
+		}
+		for tick := uint32(1001); tick <= 1003; tick++ {
+			if err := stream.SendMsg(statusResponse(100, tick)); err != nil {
+				return err
+			}
+		}
+		<-stream.Context().Done()
+		return nil
+	})
+
+	feed := NewStatusFeed(client, NewAdaptivePoller(client, time.Second, time.Millisecond, time.Millisecond), time.Second)
+	defer feed.Close()
+
+	for tick := uint32(1001); tick <= 1003; tick++ {
+		intervals, err := feed.NextStatus(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, tick, latestTick(intervals))
+	}
+
+	// not caught up returns the last status again
+	intervals, err := feed.NextStatus(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1003), latestTick(intervals))
+	assert.Equal(t, int32(0), polls.Load())
+}
+
+func TestStatusFeed_FallbackToPolling(t *testing.T) {
+	var polls atomic.Int32
+	server := &testArchiveServer{
+		getStatusFunc: func(_ context.Context, _ *emptypb.Empty) (*archiverproto.GetStatusResponse, error) {
+			return statusResponse(100, 1000+uint32(polls.Add(1))), nil
+		},
+	}
+	client := startTestArchiver(t, server, nil) // no streaming support
+
+	feed := NewStatusFeed(client, NewAdaptivePoller(client, time.Second, time.Millisecond, time.Millisecond), time.Second)
+	defer feed.Close()
+
+	intervals, err := feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1001), latestTick(intervals))
+	assert.True(t, feed.streamUnavailable)
+
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1002), latestTick(intervals))
+	assert.Equal(t, int32(2), polls.Load())
+}
+
+type fakeStatusFetcher struct {
+	ticks []uint32
+	calls int
+	err   error
+}
+
+func (f *fakeStatusFetcher) GetProcessedTickIntervalsPerEpoch(_ context.Context) ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	tick := f.ticks[min(f.calls, len(f.ticks)-1)]
+	f.calls++
+	return statusIntervals(tick), nil
+}
+
+func statusIntervals(tick uint32) []entities.ProcessedTickIntervalsPerEpoch {
+	return []entities.ProcessedTickIntervalsPerEpoch{
+		{Epoch: 100, Intervals: []entities.ProcessedTickInterval{{InitialProcessedTick: 1, LastProcessedTick: tick}}},
+	}
+}
+
+type fakeStatusStreamer struct {
+	ticks   []uint32 // sent by every opened stream
+	err     error    // returned after the ticks. If nil, the stream blocks until it gets canceled.
+	openErr error
+	opens   int
+}
+
+func (f *fakeStatusStreamer) StreamStatus(ctx context.Context) (StatusStream, error) {
+	f.opens++
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	return &fakeStatusStream{ctx: ctx, ticks: f.ticks, err: f.err}, nil
+}
+
+type fakeStatusStream struct {
+	ctx   context.Context
+	ticks []uint32
+	err   error
+}
+
+func (s *fakeStatusStream) Recv() ([]entities.ProcessedTickIntervalsPerEpoch, error) {
+	if len(s.ticks) > 0 {
+		tick := s.ticks[0]
+		s.ticks = s.ticks[1:]
+		return statusIntervals(tick), nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func newTestStatusFeed(streamer statusStreamer, fetcher statusFetcher, idleTimeout time.Duration) *StatusFeed {
+	return NewStatusFeed(streamer, NewAdaptivePoller(fetcher, time.Second, time.Millisecond, time.Millisecond), idleTimeout)
+}
+
+func TestStatusFeed_FakeStream(t *testing.T) {
+	streamer := &fakeStatusStreamer{ticks: []uint32{11, 12, 13}}
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10}}
+	feed := newTestStatusFeed(streamer, fetcher, time.Second)
+	defer feed.Close()
+
+	for tick := uint32(11); tick <= 13; tick++ {
+		intervals, err := feed.NextStatus(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, tick, latestTick(intervals))
+	}
+	assert.Equal(t, 1, streamer.opens)
+	assert.Equal(t, 0, fetcher.calls)
+}
+
+func TestStatusFeed_WithoutStreamer(t *testing.T) {
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10, 11}}
+	feed := newTestStatusFeed(nil, fetcher, time.Second)
+
+	intervals, err := feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), latestTick(intervals))
+
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), latestTick(intervals))
+	assert.Equal(t, 2, fetcher.calls)
+}
+
+func TestStatusFeed_StreamOpenFailed(t *testing.T) {
+	streamer := &fakeStatusStreamer{ticks: []uint32{20}, openErr: errors.New("connection refused")}
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10, 11}}
+	feed := newTestStatusFeed(streamer, fetcher, time.Second)
+	defer feed.Close()
+
+	intervals, err := feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), latestTick(intervals))
+
+	// polls until the retry delay passed
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), latestTick(intervals))
+	assert.Equal(t, 1, streamer.opens)
+
+	streamer.openErr = nil
+	feed.retryStreamAt = time.Now()
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(20), latestTick(intervals))
+	assert.Equal(t, 2, streamer.opens)
+	assert.Equal(t, 2, fetcher.calls)
+}
+
+func TestStatusFeed_StreamFailed(t *testing.T) {
+	streamer := &fakeStatusStreamer{ticks: []uint32{20}, err: errors.New("connection reset")}
+	fetcher := &fakeStatusFetcher{ticks: []uint32{21, 22}}
+	feed := newTestStatusFeed(streamer, fetcher, time.Second)
+	defer feed.Close()
+
+	intervals, err := feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(20), latestTick(intervals))
+
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(21), latestTick(intervals))
+	assert.False(t, feed.streamUnavailable)
+
+	// polls until the retry delay passed
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(22), latestTick(intervals))
+	assert.Equal(t, 1, streamer.opens)
+}
+
+func TestStatusFeed_StreamUnimplemented(t *testing.T) {
+	streamer := &fakeStatusStreamer{err: status.Error(codes.Unimplemented, "unknown method StreamStatus")}
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10, 11}}
+	feed := newTestStatusFeed(streamer, fetcher, time.Second)
+	defer feed.Close()
+
+	intervals, err := feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), latestTick(intervals))
+	assert.True(t, feed.streamUnavailable)
+
+	// never retries the stream
+	feed.retryStreamAt = time.Time{}
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), latestTick(intervals))
+	assert.Equal(t, 1, streamer.opens)
+}
+
+func TestStatusFeed_StreamIdleTimeout(t *testing.T) {
+	streamer := &fakeStatusStreamer{} // never sends anything
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10, 11}}
+	feed := newTestStatusFeed(streamer, fetcher, 10*time.Millisecond)
+	defer feed.Close()
+
+	intervals, err := feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), latestTick(intervals))
+	assert.False(t, feed.streamUnavailable)
+
+	// reconnects immediately
+	intervals, err = feed.NextStatus(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), latestTick(intervals))
+	assert.Equal(t, 2, streamer.opens)
+}
+
+func TestAdaptivePoller_NextStatus(t *testing.T) {
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10, 10, 10, 10, 11}}
+	poller := NewAdaptivePoller(fetcher, time.Second, time.Millisecond, 4*time.Millisecond)
+
+	_, err := poller.NextStatus(context.Background()) // new tick
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond, poller.Interval())
+
+	_, err = poller.NextStatus(context.Background()) // idle
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Millisecond, poller.Interval())
+
+	_, err = poller.NextStatus(context.Background()) // idle
+	require.NoError(t, err)
+	assert.Equal(t, 4*time.Millisecond, poller.Interval())
+
+	_, err = poller.NextStatus(context.Background()) // idle, max reached
+	require.NoError(t, err)
+	assert.Equal(t, 4*time.Millisecond, poller.Interval())
+
+	intervals, err := poller.NextStatus(context.Background()) // new tick
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), latestTick(intervals))
+	assert.Equal(t, time.Millisecond, poller.Interval())
+
+	fetcher.err = errors.New("unavailable")
+	_, err = poller.NextStatus(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 2*time.Millisecond, poller.Interval())
+}
+
+func TestAdaptivePoller_NextStatus_ContextCanceled(t *testing.T) {
+	fetcher := &fakeStatusFetcher{ticks: []uint32{10}}
+	poller := NewAdaptivePoller(fetcher, time.Second, time.Hour, time.Hour)
+
+	_, err := poller.NextStatus(context.Background()) // first poll does not wait
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = poller.NextStatus(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, fetcher.calls)
+}
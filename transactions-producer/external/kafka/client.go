@@ -5,45 +5,101 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"log"
+	"strconv"
 	"sync"
 
+	"github.com/qubic/logging"
 	"github.com/qubic/transactions-producer/entities"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
 )
 
+// Record headers for oversized payloads. IMPORTANT: they need to match the consumer code.
+const (
+	FragmentIdHeader    = "qubic-fragment-id"
+	FragmentIndexHeader = "qubic-fragment-index"
+	FragmentCountHeader = "qubic-fragment-count"
+	BlobRefHeader       = "qubic-blob-ref"
+)
+
+// TickTransactionCountHeader contains the number of transactions published for the tick of the record. Consumers
+// use it to detect incomplete ticks. IMPORTANT: it needs to match the consumer code.
+const TickTransactionCountHeader = "qubic-tick-transaction-count"
+
+const (
+	OversizeModeChunk   = "chunk"
+	OversizeModeOffload = "offload"
+)
+
+// recordOverhead is reserved for key, headers and batch framing when comparing payloads to the maximum message size.
+const recordOverhead = 1024
+
 type KafkaClient interface {
 	Produce(ctx context.Context, r *kgo.Record, promise func(*kgo.Record, error))
 }
+
+// BlobStore stores payloads that are too large for the message broker.
+type BlobStore interface {
+	Put(key string, data []byte) (string, error)
+}
+
+type oversizeMetrics interface {
+	IncOversizedPayloads(mode string)
+}
+
+type OversizeConfig struct {
+	MaxMessageBytes int    // maximum message size of the broker. Zero disables oversize handling.
+	Mode            string // chunk or offload
+	BlobStore       BlobStore
+	Metrics         oversizeMetrics
+}
+
 type Client struct {
-	kcl KafkaClient
+	kcl      KafkaClient
+	oversize OversizeConfig
 }
 
-func NewClient(kafkaClient KafkaClient) *Client {
+func NewClient(kafkaClient KafkaClient, oversize OversizeConfig) *Client {
 	return &Client{
-		kcl: kafkaClient,
+		kcl:      kafkaClient,
+		oversize: oversize,
 	}
 }
 
 func (kc *Client) PublishTickTransactions(transactions []entities.Transaction) error {
 
-	wg := sync.WaitGroup{}
-	errorChannel := make(chan error, len(transactions))
-
+	tickTransactionCounts := make(map[uint32]int)
 	for _, transaction := range transactions {
+		tickTransactionCounts[transaction.TickNumber]++
+	}
 
-		record, err := createTransactionRecord(transaction)
+	var records []*kgo.Record
+	var recordTransactions []entities.Transaction
+	for _, transaction := range transactions {
+		txRecords, err := kc.createTransactionRecords(transaction)
 		if err != nil {
-			log.Printf("Error while creating record: %v", err)
-			errorChannel <- err
-			break
+			zap.S().Errorw("Creating record failed.", logging.Error, err)
+			return fmt.Errorf("producing record: %w", err)
 		}
+		count := strconv.Itoa(tickTransactionCounts[transaction.TickNumber])
+		for _, record := range txRecords {
+			record.Headers = append(record.Headers, kgo.RecordHeader{Key: TickTransactionCountHeader, Value: []byte(count)})
+			records = append(records, record)
+			recordTransactions = append(recordTransactions, transaction)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	errorChannel := make(chan error, len(records))
+
+	for i, record := range records {
+		transaction := recordTransactions[i]
 
 		wg.Add(1)
 		kc.kcl.Produce(nil, record, func(_ *kgo.Record, err error) {
 			defer wg.Done()
 			if err != nil {
-				log.Printf("Error while producing record: %v", err)
+				zap.S().Errorw("Producing record failed.", logging.Tick, transaction.TickNumber, "hash", transaction.Hash, logging.Error, err)
 				errorChannel <- fmt.Errorf("publishing tick [%d] and transaction [%s]: %w", transaction.TickNumber, transaction.Hash, err)
 				return
 			}
@@ -64,6 +120,64 @@ func (kc *Client) PublishTickTransactions(transactions []entities.Transaction) e
 	return nil
 }
 
+// createTransactionRecords creates one record per transaction. Payloads exceeding the maximum message size are either
+// split into ordered fragments or offloaded to the blob store.
+func (kc *Client) createTransactionRecords(tx entities.Transaction) ([]*kgo.Record, error) {
+	record, err := createTransactionRecord(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPayloadSize := kc.oversize.MaxMessageBytes - recordOverhead
+	if kc.oversize.MaxMessageBytes <= 0 || len(record.Value) <= maxPayloadSize {
+		return []*kgo.Record{record}, nil
+	}
+
+	zap.S().Warnw("Transaction exceeds maximum message size.", logging.Tick, tx.TickNumber, "hash", tx.Hash, "bytes", len(record.Value))
+	if kc.oversize.Metrics != nil {
+		kc.oversize.Metrics.IncOversizedPayloads(kc.oversize.Mode)
+	}
+
+	switch kc.oversize.Mode {
+	case OversizeModeChunk:
+		return chunkRecord(record, tx.Hash, maxPayloadSize), nil
+	case OversizeModeOffload:
+		if kc.oversize.BlobStore == nil {
+			return nil, fmt.Errorf("offloading transaction [%s]: no blob store configured", tx.Hash)
+		}
+		ref, err := kc.oversize.BlobStore.Put(tx.Hash, record.Value)
+		if err != nil {
+			return nil, fmt.Errorf("offloading transaction [%s]: %w", tx.Hash, err)
+		}
+		return []*kgo.Record{{
+			Key:     record.Key,
+			Headers: []kgo.RecordHeader{{Key: BlobRefHeader, Value: []byte(ref)}},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("transaction [%s] too large and unknown oversize mode [%s]", tx.Hash, kc.oversize.Mode)
+	}
+}
+
+// chunkRecord splits the record value into fragments. All fragments have the same key and end up in the same
+// partition in order.
+func chunkRecord(record *kgo.Record, id string, fragmentSize int) []*kgo.Record {
+	count := (len(record.Value) + fragmentSize - 1) / fragmentSize
+	fragments := make([]*kgo.Record, 0, count)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*fragmentSize, len(record.Value))
+		fragments = append(fragments, &kgo.Record{
+			Key:   record.Key,
+			Value: record.Value[i*fragmentSize : end],
+			Headers: []kgo.RecordHeader{
+				{Key: FragmentIdHeader, Value: []byte(id)},
+				{Key: FragmentIndexHeader, Value: []byte(strconv.Itoa(i))},
+				{Key: FragmentCountHeader, Value: []byte(strconv.Itoa(count))},
+			},
+		})
+	}
+	return fragments
+}
+
 func createTransactionRecord(tx entities.Transaction) (*kgo.Record, error) {
 
 	payload, err := json.Marshal(tx)
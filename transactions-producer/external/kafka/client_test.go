@@ -3,11 +3,15 @@ package kafka
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/qubic/transactions-producer/entities"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/twmb/franz-go/pkg/kgo"
 )
 
@@ -93,7 +97,7 @@ func TestClient_PublishTransactions(t *testing.T) {
 			mockClient := &MockKafkaClient{
 				shouldError: testRun.shouldError,
 			}
-			kc := NewClient(mockClient)
+			kc := NewClient(mockClient, OversizeConfig{})
 
 			err := kc.PublishTickTransactions(testRun.tickTransactions)
 
@@ -104,6 +108,9 @@ func TestClient_PublishTransactions(t *testing.T) {
 			}
 			assert.NoError(t, err)
 			assert.Equal(t, testRun.expectedCount, mockClient.MessageCount)
+			for _, record := range mockClient.ProducedRecords {
+				assert.Equal(t, "3", headerValue(record, TickTransactionCountHeader))
+			}
 
 		})
 	}
@@ -135,7 +142,7 @@ func TestClient_PublishTransactions_JsonPayload(t *testing.T) {
 	}
 
 	mockClient := &MockKafkaClient{}
-	kc := NewClient(mockClient)
+	kc := NewClient(mockClient, OversizeConfig{})
 
 	err := kc.PublishTickTransactions([]entities.Transaction{tx1, tx2})
 	assert.NoError(t, err)
@@ -157,3 +164,120 @@ func TestClient_PublishTransactions_JsonPayload(t *testing.T) {
 	assert.Equal(t, 50000017, int(binary.LittleEndian.Uint32(mockClient.ProducedRecords[1].Key)))
 
 }
+
+type fakeBlobStore struct {
+	blobs map[string][]byte
+}
+
+func (s *fakeBlobStore) Put(key string, data []byte) (string, error) {
+	if s.blobs == nil {
+		s.blobs = make(map[string][]byte)
+	}
+	s.blobs[key] = data
+	return "ref-" + key, nil
+}
+
+type fakeOversizeMetrics struct {
+	modes []string
+}
+
+func (m *fakeOversizeMetrics) IncOversizedPayloads(mode string) {
+	m.modes = append(m.modes, mode)
+}
+
+func oversizedTransaction(inputSize int) entities.Transaction {
+	return entities.Transaction{
+		Hash:        "nagnkafzthqkxvbdewcrypgvkwdzkbbyupekzxpyrtdvvmqgugxbdhvmhvef",
+		Source:      "BTDXTBFYNBMVCGYBRRTNBZAFUBZNTWSRNSLGMTKGTBNJZTPXJLFHNSLVVQGY",
+		Destination: "RLRNPMAFKPPLUZQXJLTTNFSCJMQEHWMWDVJHMAMZGAMEQWSDUFRJKHLCLDTD",
+		TickNumber:  50000017,
+		InputSize:   uint32(inputSize),
+		InputData:   strings.Repeat("a", inputSize),
+	}
+}
+
+func headerValue(record *kgo.Record, key string) string {
+	for _, header := range record.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func TestClient_PublishTransactions_ChunksOversizedPayload(t *testing.T) {
+	mockClient := &MockKafkaClient{}
+	oversizeMetrics := &fakeOversizeMetrics{}
+	kc := NewClient(mockClient, OversizeConfig{MaxMessageBytes: 2048, Mode: OversizeModeChunk, Metrics: oversizeMetrics})
+
+	small := oversizedTransaction(10)
+	small.Hash = "small"
+	large := oversizedTransaction(2500)
+	err := kc.PublishTickTransactions([]entities.Transaction{small, large})
+	require.NoError(t, err)
+
+	expected, err := json.Marshal(large)
+	require.NoError(t, err)
+
+	records := mockClient.ProducedRecords
+	require.Len(t, records, 1+3) // ~2800 bytes in fragments of 1024 bytes
+	assert.Equal(t, []kgo.RecordHeader{{Key: TickTransactionCountHeader, Value: []byte("2")}}, records[0].Headers)
+
+	var reassembled []byte
+	for i, record := range records[1:] {
+		assert.Equal(t, large.Hash, headerValue(record, FragmentIdHeader))
+		assert.Equal(t, strconv.Itoa(i), headerValue(record, FragmentIndexHeader))
+		assert.Equal(t, "3", headerValue(record, FragmentCountHeader))
+		assert.Equal(t, "2", headerValue(record, TickTransactionCountHeader))
+		assert.Equal(t, 50000017, int(binary.LittleEndian.Uint32(record.Key)))
+		assert.LessOrEqual(t, len(record.Value), 2048-recordOverhead)
+		reassembled = append(reassembled, record.Value...)
+	}
+	assert.Equal(t, expected, reassembled)
+	assert.Equal(t, []string{OversizeModeChunk}, oversizeMetrics.modes)
+}
+
+func TestClient_PublishTransactions_OffloadsOversizedPayload(t *testing.T) {
+	mockClient := &MockKafkaClient{}
+	blobStore := &fakeBlobStore{}
+	kc := NewClient(mockClient, OversizeConfig{MaxMessageBytes: 2048, Mode: OversizeModeOffload, BlobStore: blobStore})
+
+	large := oversizedTransaction(3000)
+	err := kc.PublishTickTransactions([]entities.Transaction{large})
+	require.NoError(t, err)
+
+	expected, err := json.Marshal(large)
+	require.NoError(t, err)
+
+	require.Len(t, mockClient.ProducedRecords, 1)
+	record := mockClient.ProducedRecords[0]
+	assert.Empty(t, record.Value)
+	assert.Equal(t, "ref-"+large.Hash, headerValue(record, BlobRefHeader))
+	assert.Equal(t, expected, blobStore.blobs[large.Hash])
+}
+
+func TestClient_PublishTransactions_OversizedPayloadWithoutBlobStore_ThenError(t *testing.T) {
+	mockClient := &MockKafkaClient{}
+	kc := NewClient(mockClient, OversizeConfig{MaxMessageBytes: 2048, Mode: OversizeModeOffload})
+
+	err := kc.PublishTickTransactions([]entities.Transaction{oversizedTransaction(3000)})
+	require.ErrorContains(t, err, "no blob store")
+	assert.Zero(t, mockClient.MessageCount)
+}
+
+func TestClient_PublishTransactions_TickTransactionCountPerTick(t *testing.T) {
+	mockClient := &MockKafkaClient{}
+	kc := NewClient(mockClient, OversizeConfig{})
+
+	err := kc.PublishTickTransactions([]entities.Transaction{
+		{Hash: "tx-1", TickNumber: 1},
+		{Hash: "tx-2", TickNumber: 2},
+		{Hash: "tx-3", TickNumber: 2},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mockClient.ProducedRecords, 3)
+	assert.Equal(t, "1", headerValue(mockClient.ProducedRecords[0], TickTransactionCountHeader))
+	assert.Equal(t, "2", headerValue(mockClient.ProducedRecords[1], TickTransactionCountHeader))
+	assert.Equal(t, "2", headerValue(mockClient.ProducedRecords[2], TickTransactionCountHeader))
+}
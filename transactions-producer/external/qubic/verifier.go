@@ -0,0 +1,94 @@
+package qubic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/qubic/go-qubic/common"
+	"github.com/qubic/schnorrq"
+	"github.com/qubic/transactions-producer/entities"
+)
+
+// Verifier recomputes the transaction digest from the transaction fields and verifies hash and signature.
+type Verifier struct{}
+
+func NewVerifier() *Verifier {
+	return &Verifier{}
+}
+
+// Verify returns entities.ErrHashMismatch, if the hash does not match the transaction data, and
+// entities.ErrInvalidSignature, if the signature cannot be verified with the source identity.
+func (v *Verifier) Verify(tx entities.Transaction) error {
+	sourcePublicKey, err := toPublicKey(tx.Source)
+	if err != nil {
+		return fmt.Errorf("decoding source identity [%s]: %w", tx.Source, err)
+	}
+	unsignedData, signature, err := marshalBinary(tx, sourcePublicKey)
+	if err != nil {
+		return fmt.Errorf("serializing transaction: %w", err)
+	}
+
+	// the transaction hash is the digest of the signed transaction
+	digest, err := common.K12Hash(append(unsignedData, signature[:]...))
+	if err != nil {
+		return fmt.Errorf("hashing transaction: %w", err)
+	}
+	hash, err := common.DigestToTxID(digest)
+	if err != nil {
+		return fmt.Errorf("converting digest to transaction id: %w", err)
+	}
+	if string(hash) != tx.Hash {
+		return fmt.Errorf("%w: calculated [%s]", entities.ErrHashMismatch, hash)
+	}
+
+	// the signature signs the digest of the transaction without signature
+	unsignedDigest, err := common.K12Hash(unsignedData)
+	if err != nil {
+		return fmt.Errorf("hashing unsigned transaction: %w", err)
+	}
+	err = schnorrq.Verify(sourcePublicKey, unsignedDigest, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", entities.ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// marshalBinary serializes the transaction like the qubic network does and returns the data without signature and
+// the signature.
+func marshalBinary(tx entities.Transaction, sourcePublicKey [32]byte) ([]byte, [64]byte, error) {
+	destinationPublicKey, err := toPublicKey(tx.Destination)
+	if err != nil {
+		return nil, [64]byte{}, fmt.Errorf("decoding destination identity [%s]: %w", tx.Destination, err)
+	}
+	input, err := base64.StdEncoding.DecodeString(tx.InputData)
+	if err != nil {
+		return nil, [64]byte{}, fmt.Errorf("decoding input data: %w", err)
+	}
+	if len(input) != int(tx.InputSize) {
+		return nil, [64]byte{}, fmt.Errorf("input size [%d] does not match input data length [%d]", tx.InputSize, len(input))
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(tx.Signature)
+	if err != nil {
+		return nil, [64]byte{}, fmt.Errorf("decoding signature: %w", err)
+	}
+	if len(signatureBytes) != 64 {
+		return nil, [64]byte{}, fmt.Errorf("invalid signature length [%d]", len(signatureBytes))
+	}
+
+	var buff bytes.Buffer
+	buff.Write(sourcePublicKey[:])
+	buff.Write(destinationPublicKey[:])
+	_ = binary.Write(&buff, binary.LittleEndian, tx.Amount)
+	_ = binary.Write(&buff, binary.LittleEndian, tx.TickNumber)
+	_ = binary.Write(&buff, binary.LittleEndian, uint16(tx.InputType))
+	_ = binary.Write(&buff, binary.LittleEndian, uint16(tx.InputSize))
+	buff.Write(input)
+	return buff.Bytes(), [64]byte(signatureBytes), nil
+}
+
+func toPublicKey(identity string) ([32]byte, error) {
+	id := common.Identity(identity)
+	return id.ToPubKey(false)
+}
@@ -0,0 +1,97 @@
+package qubic
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/qubic/go-qubic/common"
+
+	"github.com/qubic/transactions-producer/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transaction from the qubic network (tick 23582758)
+func validTransaction() entities.Transaction {
+	return entities.Transaction{
+		Hash:        "czxyxioyrhtkbbinsnhoieectcugxmbscizlynmaieilqhmnwojaekdczaki",
+		Source:      "FZTXBUWQTOWAHBODSZKVMUQRRPDDASKDOQLSDGLIUCVWDSYWIBAKAXRBKEJJ",
+		Destination: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAFXIB",
+		Amount:      0,
+		TickNumber:  23582758,
+		InputType:   1,
+		InputSize:   848,
+		InputData:   "dCAo5j2A40g1p4wfyLYxauNnEgaM4Af+KGJZ9n4LjR/YIa1d2dayBYGiaOYxiWCGffyLYzfuMGFZlp3+fdnolYGLWcaqLYogh9GcZJm2saeMozjGJ2Iit+n3fp9Ipep7osfSMIlfV9Wcad1WpfWAGGZuKWiaOKotit+IpYNlH4aJoWJZOMIpfGJoji+AHxiN84iidxolaZ02vhxfYrZZoYjh5+Yjh5n1/ZVo4oaRjmhaZnotiGHogfV7Yghx8X1iJhWUfGHmsa9gmWiRl2hiFg3shx94daaJGuhp63EiiIntiaH3wfGImpX5m2rfFyn8Zh71+ZKGnpiSHGqiGJmae1gYhhxkYeh58XAaJm2KfWGmWiFsGmYp5odcxlX8hxp2fhhhWXZeJ4Xh+G3xet74jfN6n6fZ64je98ohil5ofZxhGhe6IGgiF8HIZeHoZiCHodhZ6mFhR84jfmGXNfGIImhx7ojexnIOhtpHsZ+IoaZJlodiN6ocapp17Zd73Dhdz4hf1qIXZ6HGHZhomiX5l4cfSIoXaaInledqF7Zl5WdapgYkZFmWoXuHYVe1nYVgeH2+Z+HXuZdgmQalpYcc584XbRemaahwXge58mdYZ1GTZNo2AZR6nvaRqF/Yxp2gXpjWjhlm14ZRyHTf1tIacZqGmc99V/hqHHEZpqHUhhnV7aCEYff1omfhhgYdYl7HNaFx2aXuGXoeZkmiZliIcY2D2oiFmnihBeYahVjXzaVqIfeuHF/fCGYZhiHIVe2FIUguEnghWFXsiOF3memGmVZ1oIMhCFIVhiD4Vd9v4TepfINeyHmJh2CGhdBnGyACFoDZBnGbXhmm6Z2E3Fd1n3shF/3nhViHHZB4oPc5oWbYJlWbZhfmLZpnWGYx534d9omXX9iXFZ9wXJadh2Gd5oWpexeW8dSFX2e1xWRZh0GehViGgZt5YdZRf3ahKFGXaN+3jaBw3IW9mH8aFvWXYNlIYaCD3bZFy4GfBe2OgqDoTg+EIRhRpGaXqDIMgyE3Ddl1H3caDWUgllYAg+DYReGCoAdiGGheyB3hhOEYIgp44DXiGoCed43gb9yIKfl0oWcKFXNhSC2Id93XleCF4VhmG4XeiDYIh2FHlgYAAAA=",
+		Signature:   "I0pFA7cbPoEJLJuswrwkNt5JCX7pT5R/b/RsWD/WLK2NnACGziKd1DAbK5xaHtlRHapvPK47I3XL6YrvrJIDAA==",
+		Timestamp:   1744649165000,
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	err := NewVerifier().Verify(validTransaction())
+	require.NoError(t, err)
+}
+
+func TestVerifier_Verify_HashMismatch(t *testing.T) {
+	tx := validTransaction()
+	tx.Hash = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	err := NewVerifier().Verify(tx)
+	require.ErrorIs(t, err, entities.ErrHashMismatch)
+
+	tx = validTransaction()
+	tx.Amount = 1 // changed content
+	err = NewVerifier().Verify(tx)
+	require.ErrorIs(t, err, entities.ErrHashMismatch)
+}
+
+func TestVerifier_Verify_InvalidSignature(t *testing.T) {
+	tx := validTransaction()
+	signature, err := base64.StdEncoding.DecodeString(tx.Signature)
+	require.NoError(t, err)
+	signature[40] ^= 0x01
+	tx.Signature = base64.StdEncoding.EncodeToString(signature)
+	tx.Hash = calculateHash(t, tx) // valid hash for the manipulated signature
+
+	err = NewVerifier().Verify(tx)
+	require.ErrorIs(t, err, entities.ErrInvalidSignature)
+
+	tx = validTransaction()
+	tx.Source = "BZBQFLLBNCXEMGLOBHUVFTLUPLVCPQUASSILFABOFFBCADQSSUPNWLZBQEXK" // other identity
+	tx.Hash = calculateHash(t, tx)
+	err = NewVerifier().Verify(tx)
+	require.ErrorIs(t, err, entities.ErrInvalidSignature)
+}
+
+func TestVerifier_Verify_InvalidData(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(tx *entities.Transaction)
+	}{
+		{name: "invalid source", modify: func(tx *entities.Transaction) { tx.Source = "invalid" }},
+		{name: "invalid destination", modify: func(tx *entities.Transaction) { tx.Destination = "invalid" }},
+		{name: "invalid input size", modify: func(tx *entities.Transaction) { tx.InputSize = 10 }},
+		{name: "invalid signature length", modify: func(tx *entities.Transaction) { tx.Signature = "AAAA" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := validTransaction()
+			tt.modify(&tx)
+			err := NewVerifier().Verify(tx)
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, entities.ErrHashMismatch)
+			assert.NotErrorIs(t, err, entities.ErrInvalidSignature)
+		})
+	}
+}
+
+func calculateHash(t *testing.T, tx entities.Transaction) string {
+	sourcePublicKey, err := toPublicKey(tx.Source)
+	require.NoError(t, err)
+	unsignedData, signature, err := marshalBinary(tx, sourcePublicKey)
+	require.NoError(t, err)
+	digest, err := common.K12Hash(append(unsignedData, signature[:]...))
+	require.NoError(t, err)
+	hash, err := common.DigestToTxID(digest)
+	require.NoError(t, err)
+	return string(hash)
+}
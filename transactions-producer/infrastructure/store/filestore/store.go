@@ -0,0 +1,56 @@
+package filestore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store is a blob store that writes every blob into a file of a (shared) folder. The returned reference is the file
+// name relative to the folder.
+type Store struct {
+	dir string
+}
+
+func NewBlobStore(dir string) (*Store, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("creating blob folder: %v", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) Put(key string, data []byte) (string, error) {
+	name := filepath.Base(key) + ".json"
+
+	// write to temporary file first, so that readers never see partial blobs
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary blob file: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("writing blob [%s]: %v", name, err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return "", fmt.Errorf("closing blob [%s]: %v", name, err)
+	}
+
+	err = os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+	if err != nil {
+		return "", fmt.Errorf("renaming blob [%s]: %v", name, err)
+	}
+	return name, nil
+}
+
+func (s *Store) Get(ref string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.Base(ref)))
+	if err != nil {
+		return nil, fmt.Errorf("reading blob [%s]: %v", ref, err)
+	}
+	return data, nil
+}
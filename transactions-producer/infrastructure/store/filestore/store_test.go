@@ -0,0 +1,35 @@
+package filestore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_PutAndGet(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBlobStore(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+
+	ref, err := store.Put("transaction-hash", []byte(`{"hash":"transaction-hash"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "transaction-hash.json", ref)
+
+	data, err := store.Get(ref)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hash":"transaction-hash"}`, string(data))
+
+	// no temporary files left behind
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// references cannot escape the folder
+	_, err = store.Put("../escape", []byte("data"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "escape.json"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -2,18 +2,22 @@ package pebbledb
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/cockroachdb/pebble/v2"
 	"github.com/qubic/transactions-producer/entities"
 )
 
 const lastProcessedTickPerEpochKey = 0x00
+const checkpointHistoryKey = 0x01
 
 type Store struct {
-	db *pebble.DB
+	db             *pebble.DB
+	checkpointLock sync.Mutex // serializes history appends
 }
 
 func NewProcessorStore(storeDir string) (*Store, error) {
@@ -26,13 +30,7 @@ func NewProcessorStore(storeDir string) (*Store, error) {
 }
 
 func (ps *Store) SetLastProcessedTick(tick uint32) error {
-	key := []byte{lastProcessedTickPerEpochKey}
-	key = binary.BigEndian.AppendUint32(key, 0)
-
-	var value []byte
-	value = binary.BigEndian.AppendUint32(value, tick)
-
-	err := ps.db.Set(key, value, pebble.Sync)
+	err := ps.db.Set(lastProcessedTickKey(), lastProcessedTickValue(tick), pebble.Sync)
 	if err != nil {
 		return fmt.Errorf("setting last processed tick: %v", err)
 	}
@@ -41,10 +39,7 @@ func (ps *Store) SetLastProcessedTick(tick uint32) error {
 }
 
 func (ps *Store) GetLastProcessedTick() (tick uint32, err error) {
-	key := []byte{lastProcessedTickPerEpochKey}
-	key = binary.BigEndian.AppendUint32(key, 0)
-
-	value, closer, err := ps.db.Get(key)
+	value, closer, err := ps.db.Get(lastProcessedTickKey())
 	if errors.Is(err, pebble.ErrNotFound) {
 		return 0, entities.ErrStoreEntityNotFound
 	}
@@ -59,6 +54,98 @@ func (ps *Store) GetLastProcessedTick() (tick uint32, err error) {
 	return tick, nil
 }
 
+// SaveCheckpoint sets the last processed tick and appends the checkpoint to the history log in one atomic write.
+func (ps *Store) SaveCheckpoint(checkpoint entities.Checkpoint) error {
+	ps.checkpointLock.Lock()
+	defer ps.checkpointLock.Unlock()
+
+	seq, err := ps.nextCheckpointSequence()
+	if err != nil {
+		return fmt.Errorf("getting next checkpoint sequence: %v", err)
+	}
+
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("marshalling checkpoint: %v", err)
+	}
+
+	batch := ps.db.NewBatch()
+	defer batch.Close()
+
+	err = batch.Set(lastProcessedTickKey(), lastProcessedTickValue(checkpoint.Tick), nil)
+	if err != nil {
+		return fmt.Errorf("setting last processed tick: %v", err)
+	}
+	err = batch.Set(checkpointHistoryEntryKey(seq), value, nil)
+	if err != nil {
+		return fmt.Errorf("appending checkpoint: %v", err)
+	}
+
+	err = batch.Commit(pebble.Sync)
+	if err != nil {
+		return fmt.Errorf("committing checkpoint for tick [%d]: %v", checkpoint.Tick, err)
+	}
+
+	return nil
+}
+
+// GetCheckpointHistory returns all recorded checkpoints, oldest first.
+func (ps *Store) GetCheckpointHistory() ([]entities.Checkpoint, error) {
+	iter, err := ps.db.NewIter(checkpointHistoryBounds())
+	if err != nil {
+		return nil, fmt.Errorf("creating iterator: %v", err)
+	}
+	defer iter.Close()
+
+	var history []entities.Checkpoint
+	for iter.First(); iter.Valid(); iter.Next() {
+		var checkpoint entities.Checkpoint
+		err = json.Unmarshal(iter.Value(), &checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshalling checkpoint: %v", err)
+		}
+		history = append(history, checkpoint)
+	}
+
+	return history, iter.Error()
+}
+
+func (ps *Store) nextCheckpointSequence() (uint64, error) {
+	iter, err := ps.db.NewIter(checkpointHistoryBounds())
+	if err != nil {
+		return 0, fmt.Errorf("creating iterator: %v", err)
+	}
+	defer iter.Close()
+
+	if !iter.Last() {
+		return 0, iter.Error()
+	}
+
+	return binary.BigEndian.Uint64(iter.Key()[1:]) + 1, nil
+}
+
+func lastProcessedTickKey() []byte {
+	key := []byte{lastProcessedTickPerEpochKey}
+	return binary.BigEndian.AppendUint32(key, 0)
+}
+
+func lastProcessedTickValue(tick uint32) []byte {
+	var value []byte
+	return binary.BigEndian.AppendUint32(value, tick)
+}
+
+func checkpointHistoryEntryKey(seq uint64) []byte {
+	key := []byte{checkpointHistoryKey}
+	return binary.BigEndian.AppendUint64(key, seq)
+}
+
+func checkpointHistoryBounds() *pebble.IterOptions {
+	return &pebble.IterOptions{
+		LowerBound: []byte{checkpointHistoryKey},
+		UpperBound: []byte{checkpointHistoryKey + 1},
+	}
+}
+
 func (ps *Store) Close() error {
 	return ps.db.Close()
 }
@@ -3,7 +3,9 @@ package pebbledb
 import (
 	"os"
 	"testing"
+	"time"
 
+	"github.com/qubic/transactions-producer/entities"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,3 +58,45 @@ func TestPebbleStore_LastProcessedTick(t *testing.T) {
 	}
 
 }
+
+func TestPebbleStore_CheckpointHistory(t *testing.T) {
+	dbDir, err := os.MkdirTemp("", "pebble_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dbDir)
+
+	store, err := NewProcessorStore(dbDir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	history, err := store.GetCheckpointHistory()
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	timestamp := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	checkpoints := []entities.Checkpoint{
+		{Epoch: 100, StartTick: 1000, Tick: 1009, TransactionCount: 42, Timestamp: timestamp},
+		{Epoch: 100, StartTick: 1010, Tick: 1019, TransactionCount: 0, Timestamp: timestamp.Add(time.Second)},
+		{Epoch: 100, StartTick: 1005, Tick: 1005, Timestamp: timestamp.Add(2 * time.Second), Rewind: true,
+			Reason: entities.ReasonRewindTick},
+	}
+
+	for _, checkpoint := range checkpoints {
+		err = store.SaveCheckpoint(checkpoint)
+		require.NoError(t, err)
+
+		lastProcessedTick, err := store.GetLastProcessedTick()
+		require.NoError(t, err)
+		require.Equal(t, checkpoint.Tick, lastProcessedTick)
+	}
+
+	history, err = store.GetCheckpointHistory()
+	require.NoError(t, err)
+	require.Equal(t, checkpoints, history)
+
+	// setting the tick directly does not touch the history
+	err = store.SetLastProcessedTick(2000)
+	require.NoError(t, err)
+	history, err = store.GetCheckpointHistory()
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+}